@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/gengapic"
+)
+
+// breaking compares the manifests of the public API of two generations,
+// written with the api-surface option, and fails if the later one has
+// breaking changes, after writing them to w.
+//
+// Usage:
+//
+//	protoc-gen-go_gapic breaking --previous=old/api_surface.json --next=new/api_surface.json
+func breaking(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("breaking", flag.ContinueOnError)
+	previous := fs.String("previous", "", "path to the api_surface.json of the previous generation")
+	next := fs.String("next", "", "path to the api_surface.json of the new generation")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if *previous == "" {
+		return errors.New("missing --previous")
+	}
+	if *next == "" {
+		return errors.New("missing --next")
+	}
+
+	prev, err := os.ReadFile(*previous)
+	if err != nil {
+		return fmt.Errorf("error reading previous API surface: %v", err)
+	}
+	nxt, err := os.ReadFile(*next)
+	if err != nil {
+		return fmt.Errorf("error reading next API surface: %v", err)
+	}
+	changes, err := gengapic.BreakingChanges(prev, nxt)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		fmt.Fprintln(w, c)
+	}
+	if len(changes) > 0 {
+		return fmt.Errorf("found %d breaking change(s)", len(changes))
+	}
+	return nil
+}
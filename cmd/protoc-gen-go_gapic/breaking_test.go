@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBreaking(t *testing.T) {
+	dir := t.TempDir()
+	writeSurface := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	prev := writeSurface("prev.json", `{"functions": ["func DefaultAuthScopes() []string", "func NewThing() *Thing"]}`)
+	next := writeSurface("next.json", `{"functions": ["func DefaultAuthScopes() []string"]}`)
+
+	var out bytes.Buffer
+	if err := breaking([]string{"--previous=" + prev, "--next=" + prev}, &out); err != nil {
+		t.Errorf("breaking() on the same surface = %v, want nil", err)
+	}
+	if err := breaking([]string{"--previous=" + next, "--next=" + prev}, &out); err != nil {
+		t.Errorf("breaking() on an extended surface = %v, want nil", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("breaking() wrote %q, want nothing", out.String())
+	}
+
+	err := breaking([]string{"--previous=" + prev, "--next=" + next}, &out)
+	if err == nil || !strings.Contains(err.Error(), "found 1 breaking change(s)") {
+		t.Errorf("breaking() = %v, want error for 1 breaking change", err)
+	}
+	if got, want := out.String(), "function NewThing removed\n"; got != want {
+		t.Errorf("breaking() wrote %q, want %q", got, want)
+	}
+
+	for _, tst := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "missing previous",
+			args: []string{"--next=" + next},
+			want: "missing --previous",
+		},
+		{
+			name: "missing next",
+			args: []string{"--previous=" + prev},
+			want: "missing --next",
+		},
+		{
+			name: "unreadable surface",
+			args: []string{"--previous=" + prev, "--next=" + filepath.Join(dir, "missing.json")},
+			want: "error reading next API surface",
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			err := breaking(tst.args, &out)
+			if err == nil || !strings.Contains(err.Error(), tst.want) {
+				t.Errorf("breaking(%q) = %v, want error containing %q", tst.args, err, tst.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/generator"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// generate runs the generator without protoc, on the files of a
+// FileDescriptorSet, and writes the generated files to a directory.
+//
+// Usage:
+//
+//	protoc-gen-go_gapic generate --descriptor_set=api.pb --files=google/foo/v1/foo.proto --param='go-gapic-package=cloud.google.com/go/foo/apiv1;foo' [--out=dir]
+func generate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	descriptorSet := fs.String("descriptor_set", "", "path to a FileDescriptorSet of the files to generate and all of their imports, e.g. from protoc --include_imports --descriptor_set_out")
+	files := fs.String("files", "", "comma-separated names of the files to generate, as found in the descriptor set, e.g. google/foo/v1/foo.proto")
+	param := fs.String("param", "", "comma-separated plugin options, as given to protoc with --go_gapic_opt")
+	out := fs.String("out", ".", "directory to write the generated files to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if *descriptorSet == "" {
+		return errors.New("missing --descriptor_set")
+	}
+	if *files == "" {
+		return errors.New("missing --files")
+	}
+
+	b, err := os.ReadFile(*descriptorSet)
+	if err != nil {
+		return fmt.Errorf("error reading descriptor set: %v", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(b, &set); err != nil {
+		return fmt.Errorf("error unmarshaling descriptor set %q: %v", *descriptorSet, err)
+	}
+	genReq, err := newRequest(&set, strings.Split(*files, ","), *param)
+	if err != nil {
+		return err
+	}
+
+	genResp := generator.Plugin(genReq)
+	if genResp.Error != nil {
+		return errors.New(genResp.GetError())
+	}
+	return writeFiles(*out, genResp.GetFile())
+}
+
+// newRequest builds the CodeGeneratorRequest protoc would send for
+// generating the given files of set with the given plugin parameter.
+func newRequest(set *descriptorpb.FileDescriptorSet, files []string, param string) (*pluginpb.CodeGeneratorRequest, error) {
+	known := map[string]bool{}
+	for _, f := range set.GetFile() {
+		known[f.GetName()] = true
+	}
+	var missing []string
+	for _, f := range files {
+		if !known[f] {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("files not found in descriptor set: %s", strings.Join(missing, ", "))
+	}
+
+	// Like protoc, the files of a descriptor set built with --include_imports
+	// are listed after their imports.
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: files,
+		Parameter:      proto.String(param),
+		ProtoFile:      set.GetFile(),
+	}, nil
+}
+
+// writeFiles writes the files of a CodeGeneratorResponse to dir. As with
+// protoc, a file without a name continues the previous file.
+func writeFiles(dir string, files []*pluginpb.CodeGeneratorResponse_File) error {
+	var names []string
+	contents := map[string]*strings.Builder{}
+	for _, f := range files {
+		if f.GetInsertionPoint() != "" {
+			return fmt.Errorf("insertion points are not supported: %s", f.GetName())
+		}
+		name := f.GetName()
+		if name == "" {
+			if len(names) == 0 {
+				return errors.New("the first generated file has no name")
+			}
+			name = names[len(names)-1]
+		} else if _, ok := contents[name]; ok {
+			return fmt.Errorf("file generated more than once: %s", name)
+		} else {
+			names = append(names, name)
+			contents[name] = &strings.Builder{}
+		}
+		contents[name].WriteString(f.GetContent())
+	}
+
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(contents[name].String()), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func fooDescriptorSet() *descriptorpb.FileDescriptorSet {
+	return &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("my/pkg/foo.proto"),
+				Package: proto.String("my.pkg"),
+				Options: &descriptorpb.FileOptions{
+					GoPackage: proto.String("github.com/googleapis/mypkg/pb;pkgpb"),
+				},
+				MessageType: []*descriptorpb.DescriptorProto{
+					{Name: proto.String("GetThingRequest")},
+					{Name: proto.String("Thing")},
+				},
+				Service: []*descriptorpb.ServiceDescriptorProto{
+					{
+						Name: proto.String("Foo"),
+						Method: []*descriptorpb.MethodDescriptorProto{
+							{
+								Name:       proto.String("GetThing"),
+								InputType:  proto.String(".my.pkg.GetThingRequest"),
+								OutputType: proto.String(".my.pkg.Thing"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	b, err := proto.Marshal(fooDescriptorSet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := filepath.Join(dir, "api.pb")
+	if err := os.WriteFile(set, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out")
+
+	err = generate([]string{
+		"--descriptor_set=" + set,
+		"--files=my/pkg/foo.proto",
+		"--param=go-gapic-package=github.com/googleapis/mypkg;mypkg",
+		"--out=" + out,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"doc.go", "foo_client.go", "helpers.go"} {
+		b, err := os.ReadFile(filepath.Join(out, "github.com/googleapis/mypkg", f))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.Contains(string(b), "package mypkg") {
+			t.Errorf("%s: missing package clause", f)
+		}
+	}
+
+	for _, tst := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "missing descriptor set",
+			args: []string{"--files=my/pkg/foo.proto"},
+			want: "missing --descriptor_set",
+		},
+		{
+			name: "missing files",
+			args: []string{"--descriptor_set=" + set},
+			want: "missing --files",
+		},
+		{
+			name: "unknown file",
+			args: []string{"--descriptor_set=" + set, "--files=my/pkg/foo.proto,my/pkg/bar.proto"},
+			want: "files not found in descriptor set: my/pkg/bar.proto",
+		},
+		{
+			name: "generator error",
+			args: []string{"--descriptor_set=" + set, "--files=my/pkg/foo.proto"},
+			want: "need parameter in format",
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			err := generate(tst.args)
+			if err == nil || !strings.Contains(err.Error(), tst.want) {
+				t.Errorf("generate(%q) = %v, want error containing %q", tst.args, err, tst.want)
+			}
+		})
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	err := writeFiles(dir, []*pluginpb.CodeGeneratorResponse_File{
+		{Name: proto.String("a/b.go"), Content: proto.String("package b\n")},
+		{Content: proto.String("\nvar x int\n")},
+		{Name: proto.String("c.json"), Content: proto.String("{}")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"a/b.go": "package b\n\nvar x int\n",
+		"c.json": "{}",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, files := range [][]*pluginpb.CodeGeneratorResponse_File{
+		{{Content: proto.String("package b\n")}},
+		{{Name: proto.String("b.go"), InsertionPoint: proto.String("imports")}},
+		{{Name: proto.String("b.go")}, {Name: proto.String("b.go")}},
+	} {
+		if err := writeFiles(dir, files); err == nil {
+			t.Errorf("writeFiles(%v) = nil, want error", files)
+		}
+	}
+}
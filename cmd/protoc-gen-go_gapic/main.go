@@ -19,12 +19,25 @@ import (
 	"log"
 	"os"
 
-	"github.com/googleapis/gapic-generator-go/internal/gengapic"
+	"github.com/googleapis/gapic-generator-go/generator"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := generate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "breaking" {
+		if err := breaking(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	reqBytes, err := io.ReadAll(os.Stdin)
 	if err != nil {
 		log.Fatal(err)
@@ -34,7 +47,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	genResp := gengapic.Gen(&genReq)
+	genResp := generator.Plugin(&genReq)
 	outBytes, err := proto.Marshal(genResp)
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,169 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package generator generates GAPIC client libraries in Go from protos, as the
+// protoc-gen-go_gapic plugin does, for programs embedding the generation.
+package generator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/googleapis/gapic-generator-go/internal/gengapic"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// Transport is a transport of the generated clients.
+type Transport = gengapic.Transport
+
+// The transports of the generated clients.
+const (
+	GRPC    = gengapic.GRPC
+	REST    = gengapic.REST
+	Connect = gengapic.Connect
+	// InProcess clients call a server implementation directly. It must be
+	// combined with another transport.
+	InProcess = gengapic.InProcess
+)
+
+// Options configures the generation, as the plugin options of
+// protoc-gen-go_gapic named by the plugin tags of its fields do. The imports
+// missing from Imports are looked up in the files linked into the program,
+// such as google/api/annotations.proto.
+type Options = gengapic.Options
+
+// OptionError is the error of an invalid option, or of options in conflict.
+type OptionError = gengapic.OptionError
+
+// File is a generated file.
+type File struct {
+	// Name is the path of the file, relative to the output directory, e.g.
+	// cloud.google.com/go/foo/apiv1/foo_client.go.
+	Name    string
+	Content string
+}
+
+// Generate generates the GAPIC client library of the given files, which must
+// have the same Go package, or stops early with the error of ctx once it is
+// done. The errors about invalid options are *OptionError.
+func Generate(ctx context.Context, files []*descriptorpb.FileDescriptorProto, opts Options) ([]File, error) {
+	cfg, err := gengapic.NewConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newRequest(files, opts.Imports)
+	if err != nil {
+		return nil, err
+	}
+
+	gen, err := gengapic.Generate(ctx, req, cfg)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]File, 0, len(gen))
+	for _, f := range gen {
+		out = append(out, File{Name: f.GetName(), Content: f.GetContent()})
+	}
+	return out, nil
+}
+
+// Plugin runs the generator as a protoc plugin, generating the files of the
+// given request as Generate does, with the options parsed from its parameter.
+func Plugin(req *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	files, err := plugin(req)
+	return gengapic.NewResponse(files, err)
+}
+
+func plugin(req *pluginpb.CodeGeneratorRequest) ([]*pluginpb.CodeGeneratorResponse_File, error) {
+	opts, err := gengapic.ParseOptions(req.GetParameter())
+	if err != nil {
+		return nil, err
+	}
+	protos := map[string]*descriptorpb.FileDescriptorProto{}
+	for _, f := range req.GetProtoFile() {
+		protos[f.GetName()] = f
+	}
+	var files []*descriptorpb.FileDescriptorProto
+	for _, name := range req.GetFileToGenerate() {
+		f, ok := protos[name]
+		if !ok {
+			return nil, fmt.Errorf("file to generate %q is missing from the request", name)
+		}
+		files = append(files, f)
+		delete(protos, name)
+	}
+	for _, f := range req.GetProtoFile() {
+		if _, ok := protos[f.GetName()]; ok {
+			opts.Imports = append(opts.Imports, f)
+		}
+	}
+
+	gen, err := Generate(context.Background(), files, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*pluginpb.CodeGeneratorResponse_File, 0, len(gen))
+	for _, f := range gen {
+		out = append(out, &pluginpb.CodeGeneratorResponse_File{Name: proto.String(f.Name), Content: proto.String(f.Content)})
+	}
+	return out, nil
+}
+
+// newRequest builds the CodeGeneratorRequest protoc would send for generating
+// the given files, with their imports, in the order of their dependencies.
+func newRequest(files, imports []*descriptorpb.FileDescriptorProto) (*pluginpb.CodeGeneratorRequest, error) {
+	known := map[string]*descriptorpb.FileDescriptorProto{}
+	for _, f := range imports {
+		known[f.GetName()] = f
+	}
+	for _, f := range files {
+		known[f.GetName()] = f
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{}
+	added := map[string]bool{}
+	var add func(name string) error
+	add = func(name string) error {
+		if added[name] {
+			return nil
+		}
+		added[name] = true
+		f, ok := known[name]
+		if !ok {
+			fd, err := protoregistry.GlobalFiles.FindFileByPath(name)
+			if err != nil {
+				return fmt.Errorf("import %q is neither in Imports nor linked into the program", name)
+			}
+			f = protodesc.ToFileDescriptorProto(fd)
+		}
+		for _, dep := range f.GetDependency() {
+			if err := add(dep); err != nil {
+				return err
+			}
+		}
+		req.ProtoFile = append(req.ProtoFile, f)
+		return nil
+	}
+	for _, f := range files {
+		req.FileToGenerate = append(req.FileToGenerate, f.GetName())
+		if err := add(f.GetName()); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
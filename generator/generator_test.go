@@ -0,0 +1,204 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func fooFiles() []*descriptorpb.FileDescriptorProto {
+	get := &descriptorpb.MethodOptions{}
+	proto.SetExtension(get, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/things"},
+	})
+	return []*descriptorpb.FileDescriptorProto{
+		{
+			Name:       proto.String("my/pkg/foo.proto"),
+			Package:    proto.String("my.pkg"),
+			Dependency: []string{"my/pkg/thing.proto", "google/api/annotations.proto"},
+			Options: &descriptorpb.FileOptions{
+				GoPackage: proto.String("github.com/googleapis/mypkg/pb;pkgpb"),
+			},
+			MessageType: []*descriptorpb.DescriptorProto{
+				{Name: proto.String("GetThingRequest")},
+			},
+			Service: []*descriptorpb.ServiceDescriptorProto{
+				{
+					Name: proto.String("Foo"),
+					Method: []*descriptorpb.MethodDescriptorProto{
+						{
+							Name:       proto.String("GetThing"),
+							InputType:  proto.String(".my.pkg.GetThingRequest"),
+							OutputType: proto.String(".my.pkg.Thing"),
+							Options:    get,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func thingFile() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("my/pkg/thing.proto"),
+		Package: proto.String("my.pkg"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("github.com/googleapis/mypkg/pb;pkgpb"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("Thing")},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	files, err := Generate(context.Background(), fooFiles(), Options{
+		PackagePath: "github.com/googleapis/mypkg",
+		PackageName: "mypkg",
+		Transports:  []Transport{GRPC, REST},
+		Metadata:    true,
+		Imports:     []*descriptorpb.FileDescriptorProto{thingFile()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, f := range files {
+		got[f.Name] = f.Content
+	}
+	for _, name := range []string{"doc.go", "foo_client.go", "helpers.go", "gapic_metadata.json"} {
+		content, ok := got["github.com/googleapis/mypkg/"+name]
+		if !ok {
+			t.Errorf("%s was not generated", name)
+		}
+		if strings.HasSuffix(name, ".go") && !strings.Contains(content, "package mypkg") {
+			t.Errorf("%s: missing package clause", name)
+		}
+	}
+	if !strings.Contains(got["github.com/googleapis/mypkg/foo_client.go"], "func NewFooRESTClient(") {
+		t.Error("foo_client.go: missing REST client constructor")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	valid := Options{
+		PackagePath: "github.com/googleapis/mypkg",
+		PackageName: "mypkg",
+		Imports:     []*descriptorpb.FileDescriptorProto{thingFile()},
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tst := range []struct {
+		name   string
+		ctx    context.Context
+		opts   func(o *Options)
+		option string
+		want   string
+	}{
+		{
+			name:   "missing package",
+			opts:   func(o *Options) { o.PackageName = "" },
+			option: "PackagePath",
+			want:   "need parameter in format: go-gapic-package=client/import/path;packageName",
+		},
+		{
+			name:   "invalid transport",
+			opts:   func(o *Options) { o.Transports = []Transport{"tcp"} },
+			option: "Transports",
+			want:   `invalid transport option: "tcp"`,
+		},
+		{
+			name:   "invalid license",
+			opts:   func(o *Options) { o.License = "WTFPL" },
+			option: "License",
+			want:   `unsupported license "WTFPL"`,
+		},
+		{
+			name:   "unknown feature",
+			opts:   func(o *Options) { o.Features = []string{"a,b"} },
+			option: "Features",
+			want:   `no such feature is registered in the generator: "a,b"`,
+		},
+		{
+			name:   "mismatched module",
+			opts:   func(o *Options) { o.Module = "github.com/other" },
+			option: "Module",
+			want:   `does not match prefix "github.com/other"`,
+		},
+		{
+			name:   "conflicting copyright holder",
+			opts:   func(o *Options) { o.License, o.CopyrightHolder = "none", "Acme" },
+			option: "License",
+			want:   "incompatible options: license=none and copyright-holder",
+		},
+		{
+			name: "missing import",
+			opts: func(o *Options) { o.Imports = nil },
+			want: `import "my/pkg/thing.proto" is neither in Imports nor linked into the program`,
+		},
+		{
+			name: "canceled",
+			ctx:  canceled,
+			opts: func(o *Options) {},
+			want: context.Canceled.Error(),
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			ctx := tst.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			opts := valid
+			tst.opts(&opts)
+			_, err := Generate(ctx, fooFiles(), opts)
+			if err == nil || !strings.Contains(err.Error(), tst.want) {
+				t.Fatalf("Generate() = %v, want error containing %q", err, tst.want)
+			}
+			var optErr *OptionError
+			if got := errors.As(err, &optErr); got != (tst.option != "") {
+				t.Fatalf("Generate() = %v, is *OptionError: %t, want %t", err, got, tst.option != "")
+			}
+			if optErr != nil && optErr.Option != tst.option {
+				t.Errorf("Generate() = %v, for option %s, want %s", err, optErr.Option, tst.option)
+			}
+		})
+	}
+}
+
+func TestGenerateCommas(t *testing.T) {
+	files, err := Generate(context.Background(), fooFiles(), Options{
+		PackagePath:     "github.com/googleapis/mypkg",
+		PackageName:     "mypkg",
+		CopyrightHolder: "Acme, Inc.",
+		Imports:         []*descriptorpb.FileDescriptorProto{thingFile()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f.Name, ".go") && !strings.Contains(f.Content, "Acme, Inc.") {
+			t.Errorf("%s: missing copyright holder %q", f.Name, "Acme, Inc.")
+		}
+	}
+}
@@ -11,6 +11,7 @@ require (
 	google.golang.org/genproto v0.0.0-20260715203245-bcc9394bd25e
 	google.golang.org/genproto/googleapis/api v0.0.0-20260715203245-bcc9394bd25e
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20260715203245-bcc9394bd25e
+	google.golang.org/grpc v1.82.0
 	google.golang.org/protobuf v1.36.11
 )
 
@@ -24,7 +25,6 @@ require (
 	golang.org/x/net v0.56.0 // indirect
 	golang.org/x/sys v0.47.0 // indirect
 	golang.org/x/text v0.40.0 // indirect
-	google.golang.org/grpc v1.82.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
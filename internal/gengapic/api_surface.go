@@ -0,0 +1,349 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// apiSurfaceFile is the name of the manifest of the public API of the
+// generated package, in the output directory.
+const apiSurfaceFile = "api_surface.json"
+
+// apiSurface is the manifest of the public API of the generated package. It
+// lists the clients of the services of the GapicMetadata, with the exported
+// declarations of the generated files, rendered as Go source.
+type apiSurface struct {
+	LibraryPackage string           `json:"libraryPackage"`
+	PackageName    string           `json:"packageName"`
+	Services       []surfaceService `json:"services"`
+	// Types are the exported types which are not clients or call options,
+	// such as iterators and operation wrappers.
+	Types []surfaceType `json:"types"`
+	// Functions are the exported functions which are not client constructors.
+	Functions []string `json:"functions"`
+	// RenamedServices maps the services renamed by the renamed_services of the
+	// Go settings of the API to their names in the client names.
+	RenamedServices map[string]string `json:"renamedServices,omitempty"`
+}
+
+// surfaceService is the client of a service.
+type surfaceService struct {
+	Name         string       `json:"name"`
+	Client       string       `json:"client"`
+	Constructors []string     `json:"constructors"`
+	CallOptions  *surfaceType `json:"callOptions,omitempty"`
+	RPCs         []surfaceRPC `json:"rpcs"`
+	// Methods are the methods of the client which do not call an RPC.
+	Methods []string `json:"methods"`
+}
+
+// surfaceRPC is the client methods calling an RPC, with the transports they
+// are available on.
+type surfaceRPC struct {
+	Name       string   `json:"name"`
+	Transports []string `json:"transports"`
+	Methods    []string `json:"methods"`
+}
+
+// surfaceType is an exported type. Kind is struct, interface, or the Go
+// source of the type definition otherwise.
+type surfaceType struct {
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
+	Fields  []string `json:"fields,omitempty"`
+	Methods []string `json:"methods,omitempty"`
+}
+
+// surfaceDecls are the exported declarations of the generated files.
+type surfaceDecls struct {
+	types []*surfaceType
+	funcs []surfaceFunc
+}
+
+// surfaceFunc is an exported function or method, with its signature.
+type surfaceFunc struct {
+	recv, name, sig string
+	// results are the Go source of the result types.
+	results []string
+}
+
+// recordSurface records the exported declarations of the given generated
+// file, if it is a source file of the generated package.
+func (g *generator) recordSurface(fileName string, f *ast.File) {
+	if f.Name.Name != g.cfg.pkgName || filepath.Dir(fileName) != g.cfg.outDir || strings.HasSuffix(fileName, "_test.go") {
+		return
+	}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			fn := surfaceFunc{name: d.Name.Name}
+			if d.Recv != nil {
+				fn.recv = recvTypeName(d.Recv.List[0].Type)
+				if !ast.IsExported(fn.recv) {
+					continue
+				}
+			}
+			sig := *d
+			sig.Doc, sig.Body = nil, nil
+			fn.sig = nodeString(&sig)
+			if d.Type.Results != nil {
+				for _, r := range d.Type.Results.List {
+					fn.results = append(fn.results, nodeString(r.Type))
+				}
+			}
+			g.surface.funcs = append(g.surface.funcs, fn)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				if ts := spec.(*ast.TypeSpec); ts.Name.IsExported() {
+					g.surface.types = append(g.surface.types, surfaceTypeOf(ts))
+				}
+			}
+		}
+	}
+}
+
+func recvTypeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexExpr:
+		return recvTypeName(e.X)
+	case *ast.IndexListExpr:
+		return recvTypeName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
+
+func surfaceTypeOf(ts *ast.TypeSpec) *surfaceType {
+	st := &surfaceType{Name: ts.Name.Name}
+	switch t := ts.Type.(type) {
+	case *ast.StructType:
+		st.Kind = "struct"
+		for _, f := range t.Fields.List {
+			typ := nodeString(f.Type)
+			if len(f.Names) == 0 {
+				if ast.IsExported(recvTypeName(f.Type)) {
+					st.Fields = append(st.Fields, typ)
+				}
+				continue
+			}
+			for _, n := range f.Names {
+				if n.IsExported() {
+					st.Fields = append(st.Fields, n.Name+" "+typ)
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		st.Kind = "interface"
+		for _, m := range t.Methods.List {
+			if len(m.Names) == 0 {
+				st.Methods = append(st.Methods, nodeString(m.Type))
+				continue
+			}
+			st.Methods = append(st.Methods, m.Names[0].Name+strings.TrimPrefix(nodeString(m.Type), "func"))
+		}
+	default:
+		st.Kind = nodeString(ts.Type)
+		if ts.Assign.IsValid() {
+			st.Kind = "= " + st.Kind
+		}
+	}
+	return st
+}
+
+// nodeString renders the given node as Go source on a single line.
+func nodeString(n ast.Node) string {
+	var b strings.Builder
+	printer.Fprint(&b, token.NewFileSet(), n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// mergeSurface adds the declarations recorded by the generator context of a
+// service to g.
+func (g *generator) mergeSurface(sd surfaceDecls) {
+	g.surface.types = append(g.surface.types, sd.types...)
+	g.surface.funcs = append(g.surface.funcs, sd.funcs...)
+}
+
+// apiSurface builds the manifest of the public API of the generated package
+// from the services of the GapicMetadata and the recorded declarations.
+func (g *generator) apiSurface() *apiSurface {
+	types := map[string]*surfaceType{}
+	for _, t := range g.surface.types {
+		types[t.Name] = t
+	}
+	methods := map[string]map[string]string{}
+	for _, fn := range g.surface.funcs {
+		if fn.recv == "" {
+			continue
+		}
+		if methods[fn.recv] == nil {
+			methods[fn.recv] = map[string]string{}
+		}
+		methods[fn.recv][fn.name] = fn.sig
+	}
+
+	as := &apiSurface{
+		LibraryPackage: g.cfg.pkgPath,
+		PackageName:    g.cfg.pkgName,
+		Services:       []surfaceService{},
+		Types:          []surfaceType{},
+		Functions:      []string{},
+	}
+	var renamedServices map[string]string
+	if ls := g.cfg.APIServiceConfig.GetPublishing().GetLibrarySettings(); len(ls) > 0 {
+		renamedServices = ls[0].GetGoSettings().GetRenamedServices()
+	}
+	// The types and functions already listed with the services.
+	listed := map[string]bool{}
+	constructors := map[string]bool{}
+
+	for _, name := range sortedKeys(g.metadata.GetServices()) {
+		s := g.metadata.GetServices()[name]
+		ss := surfaceService{Name: name, Constructors: []string{}, RPCs: []surfaceRPC{}, Methods: []string{}}
+		rpcs := map[string]*surfaceRPC{}
+		for _, trans := range sortedKeys(s.GetClients()) {
+			c := s.GetClients()[trans]
+			ss.Client = c.GetLibraryClient()
+			for _, rpc := range sortedKeys(c.GetRpcs()) {
+				r, ok := rpcs[rpc]
+				if !ok {
+					r = &surfaceRPC{Name: rpc, Methods: []string{}}
+					for _, m := range c.GetRpcs()[rpc].GetMethods() {
+						if sig, ok := methods[ss.Client][m]; ok {
+							r.Methods = append(r.Methods, sig)
+						}
+					}
+					rpcs[rpc] = r
+				}
+				r.Transports = append(r.Transports, trans)
+			}
+		}
+		if ss.Client == "" {
+			continue
+		}
+		listed[ss.Client] = true
+
+		rpcMethods := map[string]bool{}
+		for _, rpc := range sortedKeys(rpcs) {
+			ss.RPCs = append(ss.RPCs, *rpcs[rpc])
+			for _, m := range s.GetClients() {
+				for _, cm := range m.GetRpcs()[rpc].GetMethods() {
+					rpcMethods[cm] = true
+				}
+			}
+		}
+		for _, m := range sortedKeys(methods[ss.Client]) {
+			if !rpcMethods[m] {
+				ss.Methods = append(ss.Methods, methods[ss.Client][m])
+			}
+		}
+
+		callOpts := strings.TrimSuffix(ss.Client, "Client") + "CallOptions"
+		if t, ok := types[callOpts]; ok {
+			ss.CallOptions = t
+			listed[callOpts] = true
+		}
+
+		for _, fn := range g.surface.funcs {
+			if fn.recv != "" {
+				continue
+			}
+			for _, r := range fn.results {
+				if r == "*"+ss.Client {
+					ss.Constructors = append(ss.Constructors, fn.sig)
+					constructors[fn.name] = true
+					break
+				}
+			}
+		}
+		sort.Strings(ss.Constructors)
+		as.Services = append(as.Services, ss)
+
+		if renamed, ok := renamedServices[name]; ok {
+			if as.RenamedServices == nil {
+				as.RenamedServices = map[string]string{}
+			}
+			as.RenamedServices[name] = renamed
+		}
+	}
+
+	for _, name := range sortedKeys(types) {
+		if listed[name] {
+			continue
+		}
+		t := *types[name]
+		for _, m := range sortedKeys(methods[name]) {
+			t.Methods = append(t.Methods, methods[name][m])
+		}
+		as.Types = append(as.Types, t)
+	}
+	var funcs []string
+	for _, fn := range g.surface.funcs {
+		if fn.recv == "" && !constructors[fn.name] {
+			funcs = append(funcs, fn.name)
+		}
+	}
+	sort.Strings(funcs)
+	for _, name := range funcs {
+		for _, fn := range g.surface.funcs {
+			if fn.recv == "" && fn.name == name {
+				as.Functions = append(as.Functions, fn.sig)
+				break
+			}
+		}
+	}
+	return as
+}
+
+// genAPISurfaceFile adds the manifest of the public API to the response.
+func (g *generator) genAPISurfaceFile() error {
+	data, err := json.MarshalIndent(g.apiSurface(), "", "  ")
+	if err != nil {
+		return err
+	}
+	g.resp.File = append(g.resp.File, &pluginpb.CodeGeneratorResponse_File{
+		Name:    proto.String(filepath.Join(g.cfg.outDir, apiSurfaceFile)),
+		Content: proto.String(string(data) + "\n"),
+	})
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
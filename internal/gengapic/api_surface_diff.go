@@ -0,0 +1,294 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// BreakingChanges compares two manifests of the public API written by the
+// api-surface option, and describes the changes from prev to next which break
+// users of prev: removed clients, clients renamed by the renamed_services of
+// the Go settings of the API or otherwise, removed or renamed methods, types,
+// fields and functions, changed signatures, such as a method newly returning an
+// operation or an iterator, and methods made internal by selective GAPIC
+// generation.
+func BreakingChanges(prev, next []byte) ([]string, error) {
+	var p, n apiSurface
+	if err := json.Unmarshal(prev, &p); err != nil {
+		return nil, fmt.Errorf("error parsing previous API surface: %v", err)
+	}
+	if err := json.Unmarshal(next, &n); err != nil {
+		return nil, fmt.Errorf("error parsing next API surface: %v", err)
+	}
+
+	var d surfaceDiff
+	if p.LibraryPackage != n.LibraryPackage {
+		d.add("package %s moved to %s", p.LibraryPackage, n.LibraryPackage)
+	}
+	if p.PackageName != n.PackageName {
+		d.add("package %s renamed to %s", p.PackageName, n.PackageName)
+	}
+
+	services := map[string]surfaceService{}
+	for _, s := range n.Services {
+		services[s.Name] = s
+	}
+	for _, ps := range p.Services {
+		ns, ok := services[ps.Name]
+		if !ok {
+			d.add("client %s removed: service %s is no longer generated", ps.Client, ps.Name)
+			continue
+		}
+		d.rename = func(s string) string { return s }
+		if ps.Client != ns.Client {
+			d.add("client %s renamed to %s%s", ps.Client, ns.Client, renameCause(ps.Name, p.RenamedServices, n.RenamedServices))
+			d.rename = clientRenamer(ps.Client, ns.Client)
+		}
+		d.diffService(ps, ns)
+	}
+	d.rename = func(s string) string { return s }
+
+	types := map[string]surfaceType{}
+	for _, t := range n.Types {
+		types[t.Name] = t
+	}
+	for _, pt := range p.Types {
+		nt, ok := types[pt.Name]
+		if !ok {
+			d.add("type %s removed", pt.Name)
+			continue
+		}
+		d.diffType(pt, nt)
+	}
+	d.diffFuncs("function", p.Functions, n.Functions)
+	return d.changes, nil
+}
+
+// surfaceDiff accumulates the breaking changes between two API surfaces.
+type surfaceDiff struct {
+	changes []string
+	// rename maps the names of the client being compared, and of its
+	// constructors and call options, in the previous surface to the next.
+	rename func(string) string
+}
+
+func (d *surfaceDiff) add(format string, args ...interface{}) {
+	d.changes = append(d.changes, fmt.Sprintf(format, args...))
+}
+
+func (d *surfaceDiff) diffService(ps, ns surfaceService) {
+	d.diffFuncs("constructor", ps.Constructors, ns.Constructors)
+	if ps.CallOptions != nil {
+		if ns.CallOptions == nil {
+			d.add("type %s removed", d.rename(ps.CallOptions.Name))
+		} else {
+			d.diffType(*ps.CallOptions, *ns.CallOptions)
+		}
+	}
+
+	rpcs := map[string]surfaceRPC{}
+	for _, r := range ns.RPCs {
+		rpcs[r.Name] = r
+	}
+	for _, pr := range ps.RPCs {
+		nr, ok := rpcs[pr.Name]
+		if !ok {
+			for _, m := range pr.Methods {
+				d.add("method %s removed: RPC %s.%s is no longer generated", d.rename(funcName(m)), ps.Name, pr.Name)
+			}
+			continue
+		}
+		if len(nr.Methods) == 0 {
+			for _, m := range pr.Methods {
+				d.add("method %s made internal: RPC %s.%s is omitted by selective GAPIC generation", d.rename(funcName(m)), ps.Name, pr.Name)
+			}
+			continue
+		}
+		for _, t := range pr.Transports {
+			if !slices.Contains(nr.Transports, t) {
+				d.add("RPC %s.%s is no longer available on transport %s", ps.Name, pr.Name, t)
+			}
+		}
+		d.diffFuncs("method", pr.Methods, nr.Methods)
+	}
+	d.diffFuncs("method", ps.Methods, ns.Methods)
+}
+
+func (d *surfaceDiff) diffType(pt, nt surfaceType) {
+	name := d.rename(pt.Name)
+	if pt.Kind != nt.Kind {
+		d.add("type %s changed from %s to %s", name, d.rename(pt.Kind), nt.Kind)
+		return
+	}
+
+	fields := map[string]string{}
+	for _, f := range nt.Fields {
+		fields[fieldName(f)] = f
+	}
+	for _, pf := range pt.Fields {
+		nf, ok := fields[fieldName(pf)]
+		switch {
+		case !ok:
+			d.add("field %s.%s removed", name, fieldName(pf))
+		case d.rename(pf) != nf:
+			d.add("field %s.%s changed from %q to %q", name, fieldName(pf), d.rename(pf), nf)
+		}
+	}
+
+	if pt.Kind != "interface" {
+		d.diffFuncs("method", pt.Methods, nt.Methods)
+		return
+	}
+	// The methods of an interface are listed without receiver, and adding one
+	// breaks its implementations.
+	qualify := func(methods []string) []string {
+		var q []string
+		for _, m := range methods {
+			q = append(q, "func ("+name+") "+m)
+		}
+		return q
+	}
+	d.diffFuncs("method", qualify(pt.Methods), qualify(nt.Methods))
+	methods := map[string]bool{}
+	for _, m := range pt.Methods {
+		methods[funcName(m)] = true
+	}
+	for _, m := range nt.Methods {
+		if !methods[funcName(m)] {
+			d.add("method %s.%s added to interface %s", name, funcName(m), name)
+		}
+	}
+}
+
+// diffFuncs reports the functions or methods of prev which are removed from
+// next or whose signature changed.
+func (d *surfaceDiff) diffFuncs(what string, prev, next []string) {
+	sigs := map[string]string{}
+	for _, s := range next {
+		sigs[funcName(s)] = s
+	}
+	for _, ps := range prev {
+		ps = d.rename(ps)
+		name := funcName(ps)
+		ns, ok := sigs[name]
+		switch {
+		case !ok:
+			d.add("%s %s removed", what, name)
+		case ps == ns:
+		case funcResults(ps) != funcResults(ns):
+			d.add("%s %s now returns %s instead of %s", what, name, funcResults(ns), funcResults(ps))
+		default:
+			d.add("%s %s changed from %q to %q", what, name, ps, ns)
+		}
+	}
+}
+
+// renameCause returns the explanation of the renaming of the client of the
+// given service by the renamed_services of the Go settings of the API, from
+// prev to next, or "" if the service is renamed alike in both.
+func renameCause(service string, prev, next map[string]string) string {
+	prevName, prevOK := prev[service]
+	nextName, nextOK := next[service]
+	switch {
+	case prevName == nextName:
+		return ""
+	case !prevOK:
+		return fmt.Sprintf(": service %s is renamed to %s", service, nextName)
+	case !nextOK:
+		return fmt.Sprintf(": service %s is no longer renamed", service)
+	}
+	return fmt.Sprintf(": service %s is renamed to %s instead of %s", service, nextName, prevName)
+}
+
+// clientRenamer returns a function renaming the given client, and its
+// constructors and related types, e.g. NewFooRESTClient and FooCallOptions
+// for FooClient.
+func clientRenamer(prev, next string) func(string) string {
+	prevBase, nextBase := strings.TrimSuffix(prev, "Client"), strings.TrimSuffix(next, "Client")
+	re := regexp.MustCompile(`\b(New)?` + regexp.QuoteMeta(prevBase) + `(\w*(?:Client|CallOptions|ClientInterface))\b`)
+	return func(s string) string {
+		return re.ReplaceAllString(s, "${1}"+nextBase+"${2}")
+	}
+}
+
+// funcName returns the qualified name of a function or method of the API
+// surface, e.g. FooClient.GetThing for
+// "func (c *FooClient) GetThing(ctx context.Context) error", or the name of
+// an interface method, e.g. Close for "Close() error".
+func funcName(sig string) string {
+	s, isFunc := strings.CutPrefix(sig, "func ")
+	var recv string
+	if isFunc && strings.HasPrefix(s, "(") {
+		end := strings.IndexByte(s, ')')
+		fields := strings.Fields(s[1:end])
+		recv = strings.TrimPrefix(fields[len(fields)-1], "*")
+		if i := strings.IndexByte(recv, '['); i >= 0 {
+			recv = recv[:i]
+		}
+		recv += "."
+		s = strings.TrimSpace(s[end+1:])
+	}
+	if i := strings.IndexAny(s, "(["); i >= 0 {
+		s = s[:i]
+	}
+	return recv + s
+}
+
+// funcResults returns the Go source of the results of the given function or
+// method, e.g. "(*FooIterator, error)".
+func funcResults(sig string) string {
+	fd := parseFuncDecl(sig)
+	if fd == nil || fd.Type.Results == nil {
+		return "nothing"
+	}
+	var results []string
+	for _, f := range fd.Type.Results.List {
+		typ := nodeString(f.Type)
+		for i := 1; i < len(f.Names); i++ {
+			results = append(results, typ)
+		}
+		results = append(results, typ)
+	}
+	if len(results) == 1 {
+		return results[0]
+	}
+	return "(" + strings.Join(results, ", ") + ")"
+}
+
+func parseFuncDecl(sig string) *ast.FuncDecl {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+sig, 0)
+	if err != nil || len(f.Decls) != 1 {
+		return nil
+	}
+	fd, _ := f.Decls[0].(*ast.FuncDecl)
+	return fd
+}
+
+// fieldName returns the name of a struct field of the API surface, e.g. Name
+// for "Name string", or the type of an embedded field.
+func fieldName(f string) string {
+	if i := strings.IndexByte(f, ' '); i >= 0 {
+		return f[:i]
+	}
+	return f
+}
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	prev, err := os.ReadFile(filepath.Join("testdata", "api_surface.want"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The services of api_surface.want, in order.
+	const bar, baz, foo, qux = 0, 1, 2, 3
+
+	for _, tst := range []struct {
+		name   string
+		change func(as *apiSurface)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			change: func(as *apiSurface) {},
+		},
+		{
+			name: "additions",
+			change: func(as *apiSurface) {
+				s := &as.Services[foo]
+				s.CallOptions.Fields = append(s.CallOptions.Fields, "GetThing []gax.CallOption")
+				s.RPCs = append(s.RPCs, surfaceRPC{
+					Name:       "GetThing",
+					Transports: []string{"grpc"},
+					Methods:    []string{"func (c *FooClient) GetThing(ctx context.Context, req *pkgpb.GetThingRequest, opts ...gax.CallOption) (*pkgpb.Thing, error)"},
+				})
+				as.Functions = append(as.Functions, "func NewThing() *Thing")
+			},
+		},
+		{
+			name: "newly LRO",
+			change: func(as *apiSurface) {
+				as.Services[foo].RPCs[0].Methods[0] = "func (c *FooClient) ListThings(ctx context.Context, req *pkgpb.ListThingsRequest, opts ...gax.CallOption) (*ListThingsOperation, error)"
+			},
+			want: []string{"method FooClient.ListThings now returns (*ListThingsOperation, error) instead of *ThingIterator"},
+		},
+		{
+			name: "changed parameters",
+			change: func(as *apiSurface) {
+				as.Services[foo].RPCs[0].Methods[0] = "func (c *FooClient) ListThings(ctx context.Context, parent string, opts ...gax.CallOption) *ThingIterator"
+			},
+			want: []string{`method FooClient.ListThings changed from "func (c *FooClient) ListThings(ctx context.Context, req *pkgpb.ListThingsRequest, opts ...gax.CallOption) *ThingIterator" to "func (c *FooClient) ListThings(ctx context.Context, parent string, opts ...gax.CallOption) *ThingIterator"`},
+		},
+		{
+			name: "renamed service",
+			change: func(as *apiSurface) {
+				b, _ := json.Marshal(as.Services[bar])
+				json.Unmarshal([]byte(strings.ReplaceAll(string(b), "Bar", "Shelf")), &as.Services[bar])
+				as.Services[bar].Name = "Bar"
+			},
+			want: []string{"client BarClient renamed to ShelfClient"},
+		},
+		{
+			name: "renamed by renamed_services",
+			change: func(as *apiSurface) {
+				b, _ := json.Marshal(as.Services[bar])
+				json.Unmarshal([]byte(strings.ReplaceAll(string(b), "Bar", "Shelf")), &as.Services[bar])
+				as.Services[bar].Name = "Bar"
+				as.RenamedServices = map[string]string{"Bar": "Shelf"}
+			},
+			want: []string{"client BarClient renamed to ShelfClient: service Bar is renamed to Shelf"},
+		},
+		{
+			name: "renamed service and removed method",
+			change: func(as *apiSurface) {
+				as.Services[bar].Client = "ShelfClient"
+				as.Services[bar].Methods = []string{"func (c *ShelfClient) Close() error"}
+				as.Services[bar].RPCs = nil
+			},
+			want: []string{
+				"client BarClient renamed to ShelfClient",
+				"constructor NewShelfClient removed",
+				"constructor NewShelfRESTClient removed",
+				"method ShelfClient.ListThings removed: RPC Bar.ListThings is no longer generated",
+				"method ShelfClient.Connection removed",
+			},
+		},
+		{
+			name: "made internal",
+			change: func(as *apiSurface) {
+				as.Services[baz].RPCs[0].Methods = []string{}
+			},
+			want: []string{"method BazClient.ListThings made internal: RPC Baz.ListThings is omitted by selective GAPIC generation"},
+		},
+		{
+			name: "removed",
+			change: func(as *apiSurface) {
+				as.Services = as.Services[:qux]
+				as.Services[foo].RPCs[0].Transports = []string{"grpc"}
+				as.Services[foo].Constructors = as.Services[foo].Constructors[:1]
+				as.Services[foo].CallOptions.Fields = nil
+				as.Types[0].Fields = as.Types[0].Fields[1:]
+				as.Types[0].Methods = as.Types[0].Methods[:2]
+				as.Functions = nil
+			},
+			want: []string{
+				"constructor NewFooRESTClient removed",
+				"field FooCallOptions.ListThings removed",
+				"RPC Foo.ListThings is no longer available on transport rest",
+				"client QuxClient removed: service Qux is no longer generated",
+				"field ThingIterator.Response removed",
+				"method ThingIterator.PageInfo removed",
+				"function DefaultAuthScopes removed",
+			},
+		},
+		{
+			name: "changed types",
+			change: func(as *apiSurface) {
+				as.Types[0].Fields[0] = "Response any"
+				as.Types = append(as.Types, surfaceType{Name: "Doer", Kind: "interface", Methods: []string{"Do() error", "Undo() error"}})
+			},
+			want: []string{`field ThingIterator.Response changed from "Response interface{}" to "Response any"`},
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			var as apiSurface
+			if err := json.Unmarshal(prev, &as); err != nil {
+				t.Fatal(err)
+			}
+			tst.change(&as)
+			next, err := json.Marshal(as)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := BreakingChanges(prev, next)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tst.want); diff != "" {
+				t.Errorf("got(-), want(+):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRenameCause(t *testing.T) {
+	for _, tst := range []struct {
+		prev, next map[string]string
+		want       string
+	}{
+		{},
+		{prev: map[string]string{"Bar": "Shelf"}, next: map[string]string{"Bar": "Shelf"}},
+		{next: map[string]string{"Bar": "Shelf"}, want: ": service Bar is renamed to Shelf"},
+		{prev: map[string]string{"Bar": "Shelf"}, want: ": service Bar is no longer renamed"},
+		{prev: map[string]string{"Bar": "Shelf"}, next: map[string]string{"Bar": "Book"}, want: ": service Bar is renamed to Book instead of Shelf"},
+		{prev: map[string]string{"Foo": "Shelf"}, next: map[string]string{"Foo": "Book"}},
+	} {
+		if got := renameCause("Bar", tst.prev, tst.next); got != tst.want {
+			t.Errorf("renameCause(Bar, %v, %v) = %q, want %q", tst.prev, tst.next, got, tst.want)
+		}
+	}
+}
+
+func TestBreakingChangesInterface(t *testing.T) {
+	prev := []byte(`{"types": [{"name": "Doer", "kind": "interface", "methods": ["Do() error", "Close() error"]}]}`)
+	next := []byte(`{"types": [{"name": "Doer", "kind": "interface", "methods": ["Do(ctx context.Context) error", "Undo() error"]}]}`)
+	got, err := BreakingChanges(prev, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`method Doer.Do changed from "func (Doer) Do() error" to "func (Doer) Do(ctx context.Context) error"`,
+		"method Doer.Close removed",
+		"method Doer.Undo added to interface Doer",
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("got(-), want(+):\n%s", diff)
+	}
+
+	if _, err := BreakingChanges([]byte("{"), next); err == nil {
+		t.Error("BreakingChanges() with an invalid manifest = nil, want error")
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+)
+
+func TestAPISurface(t *testing.T) {
+	resp, err := gen(thingsRequest("go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,api-surface"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest string
+	for _, f := range resp.GetFile() {
+		if f.GetName() == filepath.Join("github.com/googleapis/mypkg", apiSurfaceFile) {
+			manifest = f.GetContent()
+		}
+	}
+	if manifest == "" {
+		t.Fatalf("%s was not generated", apiSurfaceFile)
+	}
+	txtdiff.Diff(t, manifest, filepath.Join("testdata", "api_surface.want"))
+}
+
+func TestAPISurfaceRenamedServices(t *testing.T) {
+	serviceYAML := filepath.Join(t.TempDir(), "my_v1.yaml")
+	err := os.WriteFile(serviceYAML, []byte(`type: google.api.Service
+config_version: 3
+name: my.googleapis.com
+publishing:
+  library_settings:
+  - go_settings:
+      renamed_services:
+        Bar: Shelf
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := gen(thingsRequest("go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,api-surface,api-service-config=" + serviceYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var as apiSurface
+	for _, f := range resp.GetFile() {
+		if f.GetName() == filepath.Join("github.com/googleapis/mypkg", apiSurfaceFile) {
+			if err := json.Unmarshal([]byte(f.GetContent()), &as); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if diff := cmp.Diff(as.RenamedServices, map[string]string{"Bar": "Shelf"}); diff != "" {
+		t.Errorf("RenamedServices got(-),want(+):\n%s", diff)
+	}
+	for _, s := range as.Services {
+		if s.Name == "Bar" && s.Client != "ShelfClient" {
+			t.Errorf("client of Bar = %s, want ShelfClient", s.Client)
+		}
+	}
+}
+
+func TestRecordSurface(t *testing.T) {
+	const src = `package mypkg
+
+type Thing struct {
+	Name string
+	A, b int
+	*Embedded
+	unexported
+}
+
+type Doer interface {
+	Do(ctx context.Context, opts ...Option) error
+	fmt.Stringer
+}
+
+type Option func(*Thing)
+
+type Alias = Thing
+
+type internal struct{}
+
+func (t *Thing) Get(
+	ctx context.Context,
+) (string, error) {
+	return "", nil
+}
+
+func (t *Thing) Reset() {}
+
+func (t *Thing) get() {}
+
+func (internal) Exported() {}
+
+func NewThing() *Thing { return nil }
+`
+	g := &generator{cfg: &generatorConfig{pkgName: "mypkg", outDir: "out"}}
+	for _, name := range []string{"out/thing.go", "out/thing_test.go", "out/other/thing.go"} {
+		f, err := parser.ParseFile(token.NewFileSet(), name, src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.recordSurface(name, f)
+	}
+
+	want := surfaceDecls{
+		types: []*surfaceType{
+			{Name: "Thing", Kind: "struct", Fields: []string{"Name string", "A int", "*Embedded"}},
+			{Name: "Doer", Kind: "interface", Methods: []string{"Do(ctx context.Context, opts ...Option) error", "fmt.Stringer"}},
+			{Name: "Option", Kind: "func(*Thing)"},
+			{Name: "Alias", Kind: "= Thing"},
+		},
+		funcs: []surfaceFunc{
+			{recv: "Thing", name: "Get", sig: "func (t *Thing) Get(ctx context.Context) (string, error)", results: []string{"string", "error"}},
+			{recv: "Thing", name: "Reset", sig: "func (t *Thing) Reset()"},
+			{name: "NewThing", sig: "func NewThing() *Thing", results: []string{"*Thing"}},
+		},
+	}
+	if diff := cmp.Diff(g.surface, want, cmp.AllowUnexported(surfaceDecls{}, surfaceFunc{})); diff != "" {
+		t.Errorf("got(-), want(+):\n%s", diff)
+	}
+}
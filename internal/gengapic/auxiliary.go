@@ -16,8 +16,12 @@ package gengapic
 
 import (
 	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	longrunning "cloud.google.com/go/longrunning/autogen/longrunningpb"
@@ -45,6 +49,46 @@ type auxTypes struct {
 	// design pattern. This is only seen in the earliest of Cloud APIs i.e. GCE,
 	// and some non-Cloud services.
 	customOp *customOp
+
+	// Upload helper types by name e.g. InsertObjectUpload. These are returned
+	// by media upload RPCs.
+	uploads map[string]*uploadType
+
+	// httpBodyReader is set when a download variant of an HttpBody RPC was
+	// generated, which requires the HttpBodyReader type.
+	httpBodyReader bool
+
+	// restStreams is set when a client-streaming or bidi-streaming RPC was
+	// generated for the REST transport, which requires the stream transports.
+	restStreams bool
+
+	// connectConn is set when a Connect client was generated, which requires
+	// the connection pool speaking the Connect protocol and gRPC-Web.
+	connectConn bool
+
+	// inProcessConn is set when an in-process client was generated, which
+	// requires the connection pool dispatching to server implementations.
+	inProcessConn bool
+
+	// maxAttempts is set when a retry policy limiting the number of attempts
+	// of calls was generated, which requires a retryer enforcing the limit.
+	maxAttempts bool
+
+	// waitForReady is set when a REST call waiting for the service to be
+	// ready was generated, which requires a retryer retrying connections.
+	waitForReady bool
+
+	// hedging is set when hedged requests were generated, which requires the
+	// helpers sending them.
+	hedging bool
+
+	// requiredFields is set when a validation of the required fields of a
+	// request was generated, which requires the helper reporting them.
+	requiredFields bool
+
+	// updateMask is set when the computation of the update_mask of a request
+	// was generated, which requires the helper computing it.
+	updateMask bool
 }
 
 // operationWrapper is a simple data type representing an RPC-specific
@@ -76,11 +120,40 @@ func (g *generator) genAuxFile() error {
 		return err
 	}
 
-	g.commit(filepath.Join(g.cfg.outDir, "auxiliary.go"), g.cfg.pkgName)
+	if err := g.genMediaUploads(); err != nil {
+		return err
+	}
+
+	if err := g.genHTTPBodyReader(); err != nil {
+		return err
+	}
+
+	if err := g.genRESTStreams(); err != nil {
+		return err
+	}
+
+	if err := g.genConnectConn(); err != nil {
+		return err
+	}
+
+	if err := g.genInProcessConn(); err != nil {
+		return err
+	}
+
+	g.genRetryHelpers()
+	g.genHedgingHelpers()
+	g.genRequiredFieldsHelper()
+	g.genUpdateMaskHelper()
+
+	if _, err := g.commit(filepath.Join(g.cfg.outDir, "auxiliary.go"), g.cfg.pkgName); err != nil {
+		return err
+	}
 	g.reset()
 
 	g.genIteratorsGo123()
-	g.commitWithBuildTag(filepath.Join(g.cfg.outDir, "auxiliary_go123.go"), g.cfg.pkgName, "go1.23")
+	if _, err := g.commitWithBuildTag(filepath.Join(g.cfg.outDir, "auxiliary_go123.go"), g.cfg.pkgName, "go1.23"); err != nil {
+		return err
+	}
 	g.reset()
 
 	return nil
@@ -394,3 +467,32 @@ func (g *generator) genOperationWrapperType(ow operationWrapper) error {
 func lroTypeName(m *descriptorpb.MethodDescriptorProto) string {
 	return m.GetName() + "Operation"
 }
+
+// genEmbeddedSource emits the declarations of src, the source of a Go file
+// embedded in the generator, that follow its import block. The imports of src
+// are added to those of the generated file.
+func (g *generator) genEmbeddedSource(src string) error {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ImportsOnly)
+	if err != nil {
+		return err
+	}
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			return err
+		}
+		spec := pbinfo.ImportSpec{Path: path}
+		if imp.Name != nil {
+			spec.Name = imp.Name.Name
+		}
+		g.imports[spec] = true
+	}
+
+	// ImportsOnly stops parsing after the last import declaration.
+	impl := src
+	if n := len(f.Decls); n > 0 {
+		impl = impl[f.Decls[n-1].End()-f.FileStart:]
+	}
+	_, err = io.WriteString(g.pt.Writer(), strings.TrimLeft(impl, "\n"))
+	return err
+}
@@ -63,9 +63,14 @@ func (g *generator) clientOptions(serv *descriptorpb.ServiceDescriptorProto, cli
 			g.grpcCallOptions(serv, optsName)
 		case rest:
 			g.restCallOptions(serv, optsName)
+		case connect:
+			g.connectClientOptions(serv, clientName)
+			g.connectCallOptions(serv, optsName)
+		case inprocess:
+			g.inProcessCallOptions(serv, optsName)
 		default:
-			return fmt.Errorf("unexpected transport variant (supported variants are %q, %q): %d",
-				v, grpc, rest)
+			return fmt.Errorf("unexpected transport variant (supported variants are %q, %q, %q, %q): %d",
+				v, grpc, rest, connect, inprocess)
 		}
 	}
 
@@ -82,6 +87,59 @@ func (g *generator) internalClientIntfInit(serv *descriptorpb.ServiceDescriptorP
 	p("Connection() *grpc.ClientConn")
 	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc"}] = true
 
+	if err := g.clientIntfMethods(serv, false); err != nil {
+		return err
+	}
+	p("}")
+	p("")
+
+	return nil
+}
+
+// clientInterfaceInit generates the exported interface of the client, which
+// covers all of its public methods and lets users substitute test doubles for
+// the client.
+func (g *generator) clientInterfaceInit(serv *descriptorpb.ServiceDescriptorProto, servName string) error {
+	p := g.printf
+
+	p("// %sClientInterface is an interface that defines the methods available from %s.", servName, g.apiName)
+	p("// It is implemented by %sClient, and may be used to substitute a test double", servName)
+	p("// for it.")
+	p("type %sClientInterface interface {", servName)
+	p("Close() error")
+	if g.featureEnabled(ExportSetGoogleClientInfoFeature) {
+		p("SetGoogleClientInfo(...string)")
+	}
+	p("")
+	p("// Deprecated: Connections are now pooled so this method does not always")
+	p("// return the same resource.")
+	p("Connection() *grpc.ClientConn")
+	p("")
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc"}] = true
+
+	if err := g.clientIntfMethods(serv, true); err != nil {
+		return err
+	}
+	fl := g.flattenings(serv)
+	for _, m := range g.getMethods(serv) {
+		if err := g.flattenedIntfMethods(m, serv, fl[m]); err != nil {
+			return err
+		}
+	}
+	p("}")
+	p("")
+	p("var _ %[1]sClientInterface = (*%[1]sClient)(nil)", servName)
+	p("")
+
+	return nil
+}
+
+// clientIntfMethods generates the methods of serv in the body of a client
+// interface. Methods made internal by selective generation are skipped if
+// public is set.
+func (g *generator) clientIntfMethods(serv *descriptorpb.ServiceDescriptorProto, public bool) error {
+	p := g.printf
+
 	// The mixin methods are for manipulating LROs, IAM, and Location.
 	methods := g.getMethods(serv)
 
@@ -90,6 +148,9 @@ func (g *generator) internalClientIntfInit(serv *descriptorpb.ServiceDescriptorP
 		g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/option"}] = true
 	}
 	for _, m := range methods {
+		if public && g.isMethodInternal(m) {
+			continue
+		}
 
 		inType := g.descInfo.Type[m.GetInputType()]
 		inSpec, err := g.descInfo.ImportSpec(inType)
@@ -97,6 +158,14 @@ func (g *generator) internalClientIntfInit(serv *descriptorpb.ServiceDescriptorP
 			return err
 		}
 		g.imports[inSpec] = true
+		if g.isMediaUpload(m) {
+			p("%s(context.Context, *%s.%s, ...gax.CallOption) *%s",
+				g.methodName(m),
+				inSpec.Name,
+				inType.GetName(),
+				uploadTypeName(m))
+			continue
+		}
 		if m.GetOutputType() == emptyType {
 			p("%s(context.Context, *%s.%s, ...gax.CallOption) error",
 				g.methodName(m),
@@ -149,10 +218,12 @@ func (g *generator) internalClientIntfInit(serv *descriptorpb.ServiceDescriptorP
 
 			p("%s(context.Context, *%s.%s, ...gax.CallOption) (%s, error)",
 				g.methodName(m), inSpec.Name, inType.GetName(), retTyp)
+			if g.hasHTTPBodyReader(serv, m) {
+				p("%s(context.Context, *%s.%s, int64, int64, ...gax.CallOption) (*HttpBodyReader, error)",
+					g.httpBodyReaderName(m), inSpec.Name, inType.GetName())
+			}
 		}
 	}
-	p("}")
-	p("")
 
 	return nil
 }
@@ -196,7 +267,7 @@ func (g *generator) serviceDoc(serv *descriptorpb.ServiceDescriptorProto, includ
 	}
 }
 
-func (g *generator) clientInit(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, hasRPCForLRO bool) {
+func (g *generator) clientInit(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, hasRPCForLRO bool) error {
 	p := g.printf
 
 	// client struct
@@ -261,9 +332,21 @@ func (g *generator) clientInit(serv *descriptorpb.ServiceDescriptorProto, client
 	p("}")
 	p("")
 	methods := g.getMethods(serv)
+	fl := g.flattenings(serv)
 	for _, m := range methods {
-		g.genClientWrapperMethod(m, serv, clientName)
+		if err := g.genClientWrapperMethod(m, serv, clientName); err != nil {
+			return err
+		}
+		if err := g.flattenedWrapperMethods(m, serv, clientName, fl[m]); err != nil {
+			return err
+		}
+		if g.hasHTTPBodyReader(serv, m) {
+			if err := g.httpBodyReaderWrapperMethod(m, clientName); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
 }
 
 func (g *generator) genClientWrapperMethod(m *descriptorpb.MethodDescriptorProto, serv *descriptorpb.ServiceDescriptorProto, servName string) error {
@@ -286,10 +369,28 @@ func (g *generator) genClientWrapperMethod(m *descriptorpb.MethodDescriptorProto
 	// Generate method documentation just before any method is generated.
 	g.methodDoc(m, serv)
 
+	if g.isMediaUpload(m) {
+		reqTyp := fmt.Sprintf("%s.%s", inSpec.Name, inType.GetName())
+		uploadType := uploadTypeName(m)
+		p("func (c *%s) %s(ctx context.Context, req *%s, opts ...gax.CallOption) *%s {",
+			clientTypeName, methodName, reqTyp, uploadType)
+		p("    return c.internalClient.%s(ctx, req, opts...)", methodName)
+		p("}")
+		p("")
+
+		g.addSnippetsMetadataParams(m, snippetServiceName, reqTyp)
+		g.addSnippetsMetadataResult(m, snippetServiceName, uploadType)
+		return nil
+	}
+
+	checks := g.requiredChecks(m)
+
 	if m.GetOutputType() == emptyType {
 		reqTyp := fmt.Sprintf("%s.%s", inSpec.Name, inType.GetName())
 		p("func (c *%s) %s(ctx context.Context, req *%s, opts ...gax.CallOption) error {",
 			clientTypeName, methodName, reqTyp)
+		g.setUpdateMask(m, reqTyp)
+		g.validateRequiredFields(checks, "return err")
 		p("    return c.internalClient.%s(ctx, req, opts...)", methodName)
 		p("}")
 		p("")
@@ -303,6 +404,8 @@ func (g *generator) genClientWrapperMethod(m *descriptorpb.MethodDescriptorProto
 		lroType := lroTypeName(m)
 		p("func (c *%s) %s(ctx context.Context, req *%s, opts ...gax.CallOption) (*%s, error) {",
 			clientTypeName, methodName, reqTyp, lroType)
+		g.setUpdateMask(m, reqTyp)
+		g.validateRequiredFields(checks, "return nil, err")
 		p("    return c.internalClient.%s(ctx, req, opts...)", methodName)
 		p("}")
 		p("")
@@ -332,6 +435,14 @@ func (g *generator) genClientWrapperMethod(m *descriptorpb.MethodDescriptorProto
 		}
 		p("func (c *%s) %s(ctx context.Context, req *%s, opts ...gax.CallOption) *%s {",
 			clientTypeName, methodName, reqTyp, iter.iterTypeName)
+		if len(checks) > 0 {
+			// The error is returned by the first call to Next of the iterator.
+			g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/iterator"}] = true
+			g.validateRequiredFields(checks,
+				fmt.Sprintf("it := &%s{}", iter.iterTypeName),
+				`it.pageInfo, it.nextFunc = iterator.NewPageInfo(func(int, string) (string, error) { return "", err }, it.bufLen, it.takeBuf)`,
+				"return it")
+		}
 		p("    return c.internalClient.%s(ctx, req, opts...)", methodName)
 		p("}")
 		p("")
@@ -368,6 +479,8 @@ func (g *generator) genClientWrapperMethod(m *descriptorpb.MethodDescriptorProto
 		retTyp := fmt.Sprintf("%s.%s_%sClient", servSpec.Name, serv.GetName(), m.GetName())
 		p("func (c *%s) %s(ctx context.Context, req *%s, opts ...gax.CallOption) (%s, error) {",
 			clientTypeName, methodName, reqTyp, retTyp)
+		g.setUpdateMask(m, reqTyp)
+		g.validateRequiredFields(checks, "return nil, err")
 		p("    return c.internalClient.%s(ctx, req, opts...)", methodName)
 		p("}")
 		p("")
@@ -384,6 +497,8 @@ func (g *generator) genClientWrapperMethod(m *descriptorpb.MethodDescriptorProto
 
 		p("func (c *%s) %s(ctx context.Context, req *%s, opts ...gax.CallOption) (%s, error) {",
 			clientTypeName, methodName, reqTyp, retTyp)
+		g.setUpdateMask(m, reqTyp)
+		g.validateRequiredFields(checks, "return nil, err")
 		p("    return c.internalClient.%s(ctx, req, opts...)", methodName)
 		p("}")
 		p("")
@@ -415,7 +530,14 @@ func (g *generator) makeClients(serv *descriptorpb.ServiceDescriptorProto, clien
 	if err != nil {
 		return err
 	}
-	g.clientInit(serv, clientName, optsName, hasLRO)
+	if err := g.clientInit(serv, clientName, optsName, hasLRO); err != nil {
+		return err
+	}
+	if g.cfg.clientInterface {
+		if err := g.clientInterfaceInit(serv, clientName); err != nil {
+			return err
+		}
+	}
 
 	for _, v := range g.cfg.transports {
 		switch v {
@@ -423,9 +545,13 @@ func (g *generator) makeClients(serv *descriptorpb.ServiceDescriptorProto, clien
 			g.grpcClientInit(serv, clientName, optsName, imp, hasLRO)
 		case rest:
 			g.restClientInit(serv, clientName, optsName, hasLRO)
+		case connect:
+			g.connectClientInit(serv, clientName, optsName, imp, hasLRO)
+		case inprocess:
+			g.inProcessClientInit(serv, clientName, optsName, imp, hasLRO)
 		default:
-			return fmt.Errorf("unexpected transport variant (supported variants are %q, %q): %d",
-				v, grpc, rest)
+			return fmt.Errorf("unexpected transport variant (supported variants are %q, %q, %q, %q): %d",
+				v, grpc, rest, connect, inprocess)
 		}
 	}
 
@@ -67,6 +67,7 @@ func TestClientOpt(t *testing.T) {
 				MaxResponseMessageBytes: &wrappers.UInt32Value{Value: 123456},
 				RetryOrHedgingPolicy: &conf.MethodConfig_RetryPolicy_{
 					RetryPolicy: &conf.MethodConfig_RetryPolicy{
+						MaxAttempts:       5,
 						InitialBackoff:    &duration.Duration{Nanos: 100000000},
 						MaxBackoff:        &duration.Duration{Seconds: 60},
 						BackoffMultiplier: 1.3,
@@ -115,8 +116,26 @@ func TestClientOpt(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// The same config, waiting for the service to be ready and throttling
+	// retries.
+	throttledPB := proto.Clone(cpb).(*conf.ServiceConfig)
+	throttledPB.MethodConfig[1].WaitForReady = &wrappers.BoolValue{Value: true}
+	throttledPB.RetryThrottling = &conf.ServiceConfig_RetryThrottlingPolicy{
+		MaxTokens:  10,
+		TokenRatio: 0.1,
+	}
+	data, err = protojson.Marshal(throttledPB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	throttledConf, err := conf.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	g := generator{
 		imports: map[pbinfo.ImportSpec]bool{},
+		aux:     &auxTypes{},
 		mixins: mixins{
 			"google.longrunning.Operations":   operationsMethods(),
 			"google.cloud.location.Locations": locationMethods(),
@@ -197,6 +216,7 @@ func TestClientOpt(t *testing.T) {
 		tstName, servName string
 		serv              *descriptorpb.ServiceDescriptorProto
 		hasOverride       bool
+		grpcConf          *conf.Config
 		imports           map[pbinfo.ImportSpec]bool
 	}{
 		{
@@ -252,10 +272,28 @@ func TestClientOpt(t *testing.T) {
 				{Name: "gax", Path: "github.com/googleapis/gax-go/v2"}: true,
 			},
 		},
+		{
+			tstName:  "throttled_opt",
+			servName: "Foo",
+			serv:     serv,
+			grpcConf: &throttledConf,
+			imports: map[pbinfo.ImportSpec]bool{
+				{Path: "google.golang.org/api/option"}:                 true,
+				{Path: "google.golang.org/api/option/internaloption"}:  true,
+				{Path: "google.golang.org/grpc/codes"}:                 true,
+				{Path: "math"}:                                         true,
+				{Path: "time"}:                                         true,
+				{Name: "gax", Path: "github.com/googleapis/gax-go/v2"}: true,
+			},
+		},
 	} {
 		t.Run(tst.tstName, func(t *testing.T) {
 			g.reset()
 			g.hasIAMPolicyOverrides = tst.hasOverride
+			g.cfg.gRPCServiceConfig = grpcConf
+			if tst.grpcConf != nil {
+				g.cfg.gRPCServiceConfig = *tst.grpcConf
+			}
 			if err := g.clientOptions(tst.serv, tst.servName, tst.servName); err != nil {
 				t.Fatal(err)
 			}
@@ -675,6 +713,49 @@ func TestClientInit(t *testing.T) {
 			},
 			wantNumSnps: 6,
 		},
+		{
+			tstName: "connect_client_init",
+			mixins: mixins{
+				"google.longrunning.Operations": operationsMethods(),
+			},
+			servName:  "Foo",
+			serv:      servLRO,
+			parameter: proto.String("go-gapic-package=path;mypackage,transport=connect"),
+			imports: map[pbinfo.ImportSpec]bool{
+				{Name: "gtransport", Path: "google.golang.org/api/transport/grpc"}:                     true,
+				{Name: "httptransport", Path: "google.golang.org/api/transport/http"}:                  true,
+				{Name: "longrunningpb", Path: "cloud.google.com/go/longrunning/autogen/longrunningpb"}: true,
+				{Name: "lroauto", Path: "cloud.google.com/go/longrunning/autogen"}:                     true,
+				{Name: "mypackagepb", Path: "github.com/googleapis/mypackage"}:                         true,
+				{Path: "context"}:                                     true,
+				{Path: "google.golang.org/api/option"}:                true,
+				{Path: "google.golang.org/api/option/internaloption"}: true,
+				{Path: "google.golang.org/grpc"}:                      true,
+				{Path: "log/slog"}:                                    true,
+			},
+			wantNumSnps: 6,
+		},
+		{
+			tstName: "inprocess_client_init",
+			mixins: mixins{
+				"google.longrunning.Operations": operationsMethods(),
+			},
+			servName:  "Foo",
+			serv:      servLRO,
+			parameter: proto.String("go-gapic-package=path;mypackage,transport=grpc+inprocess"),
+			imports: map[pbinfo.ImportSpec]bool{
+				{Name: "gtransport", Path: "google.golang.org/api/transport/grpc"}:                     true,
+				{Name: "longrunningpb", Path: "cloud.google.com/go/longrunning/autogen/longrunningpb"}: true,
+				{Name: "lroauto", Path: "cloud.google.com/go/longrunning/autogen"}:                     true,
+				{Name: "mypackagepb", Path: "github.com/googleapis/mypackage"}:                         true,
+				{Path: "context"}:                                     true,
+				{Path: "google.golang.org/api/option"}:                true,
+				{Path: "google.golang.org/api/option/internaloption"}: true,
+				{Path: "google.golang.org/grpc"}:                      true,
+				{Path: "log/slog"}:                                    true,
+			},
+			wantNumSnps: 6,
+		},
 		{
 			tstName:   "deprecated_client_init",
 			servName:  "",
@@ -768,6 +849,38 @@ func TestClientInit(t *testing.T) {
 				},
 			},
 		},
+		{
+			tstName:   "client_interface_client_init",
+			servName:  "BaseFoo",
+			optsName:  "Foo",
+			serv:      servSelective,
+			parameter: proto.String("go-gapic-package=path;mypackage,client-interface,F_selective_gapic_generation"),
+			imports: map[pbinfo.ImportSpec]bool{
+				{Path: "context"}:                                                  true,
+				{Path: "google.golang.org/api/option"}:                             true,
+				{Path: "google.golang.org/grpc"}:                                   true,
+				{Name: "gtransport", Path: "google.golang.org/api/transport/grpc"}: true,
+				{Name: "mypackagepb", Path: "github.com/googleapis/mypackage"}:     true,
+				{Path: "log/slog"}:                                                 true,
+			},
+			wantNumSnps: 0,
+			features:    []featureID{SelectiveGapicGenerationFeature},
+			publishing: &annotations.Publishing{
+				LibrarySettings: []*annotations.ClientLibrarySettings{
+					{
+						Version: "mypackage",
+						GoSettings: &annotations.GoSettings{
+							Common: &annotations.CommonLanguageSettings{
+								SelectiveGapicGeneration: &annotations.SelectiveGapicGeneration{
+									GenerateOmittedAsInternal: true,
+									Methods:                   []string{"mypackage.Foo.Zip"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			tstName:   "selective_gapic_client_init_omitted",
 			servName:  "Foo",
@@ -883,13 +996,13 @@ func TestClientInit(t *testing.T) {
 					if g.isMethodInternal(m) {
 						continue
 					}
-					sm.AddMethod(tst.serv.GetName(), g.methodName(m), "mypackage", tst.serv.GetName(), 50)
+					sm.AddMethod(tst.serv.GetName(), g.methodName(m), "mypackage", tst.serv.GetName(), 17, 50)
 				}
 				for _, m := range g.getMixinMethods() {
 					if g.isMethodInternal(m) {
 						continue
 					}
-					sm.AddMethod(tst.serv.GetName(), g.methodName(m), "mypackage", tst.serv.GetName(), 50)
+					sm.AddMethod(tst.serv.GetName(), g.methodName(m), "mypackage", tst.serv.GetName(), 17, 50)
 				}
 			}
 			g.snippetMetadata = sm
@@ -897,7 +1010,9 @@ func TestClientInit(t *testing.T) {
 			if optsName == "" {
 				optsName = tst.servName
 			}
-			g.makeClients(tst.serv, tst.servName, optsName)
+			if err := g.makeClients(tst.serv, tst.servName, optsName); err != nil {
+				t.Fatal(err)
+			}
 
 			if md, ok := g.metadata.GetServices()[tst.serv.GetName()]; !ok {
 				t.Errorf("ClientInit(%s) gapic metadata, expected %s to be present but found %+v", tst.tstName, tst.serv.GetName(), g.metadata.GetServices())
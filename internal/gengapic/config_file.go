@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// configFileArg is the value argument naming a YAML or JSON file of plugin
+// arguments, e.g. config-file=path/to/gapic.yaml.
+const configFileArg = "config-file"
+
+// withConfigFile returns the plugin arguments of the comma-separated
+// parameter, after those loaded from the config file it names, if any.
+// Arguments of the file given again in the parameter are dropped, so that
+// the parameter takes precedence over the file.
+//
+// The config file is a YAML (or JSON) object keyed by plugin argument:
+//
+//	go-gapic-package: cloud.google.com/go/foo/apiv1;foo
+//	transport: grpc+rest
+//	metadata: true
+//	copyright-year: 2024
+//	M:
+//	  google/foo/v1/foo.proto: cloud.google.com/go/foo/apiv1/foopb;foopb
+//	F_:
+//	  - ordered_routing_headers
+//
+// The boolean arguments take a boolean, the value arguments a string or a
+// number, and the prefix arguments either a list of the strings following
+// the prefix, or a map of the keys following the prefix to their values.
+func withConfigFile(parameter string) ([]string, error) {
+	var path string
+	var args []string
+	for _, s := range strings.Split(parameter, ",") {
+		arg := strings.TrimSpace(s)
+		if v, ok := strings.CutPrefix(arg, configFileArg+"="); ok {
+			if path != "" {
+				return nil, fmt.Errorf("plugin arg %q given more than once", configFileArg)
+			}
+			if v == "" {
+				return nil, errors.New("provided config file path was empty")
+			}
+			path = v
+			continue
+		}
+		args = append(args, arg)
+	}
+	if path == "" {
+		return args, nil
+	}
+
+	fileArgs, err := argsFromConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	inline := map[string]bool{}
+	for _, arg := range args {
+		inline[argKey(arg)] = true
+	}
+	var merged []string
+	for _, arg := range fileArgs {
+		if !inline[argKey(arg)] {
+			merged = append(merged, arg)
+		}
+	}
+	return append(merged, args...), nil
+}
+
+// argKey returns the part of a plugin argument identifying the setting it
+// changes, e.g. "transport" for "transport=grpc".
+func argKey(arg string) string {
+	if e := strings.IndexByte(arg, '='); e >= 0 {
+		return arg[:e]
+	}
+	return arg
+}
+
+// argsFromConfigFile loads the plugin arguments of the given config file, in
+// the order of their keys. All of the invalid entries of the file are
+// reported together.
+func argsFromConfigFile(path string) ([]string, error) {
+	y, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file (%q): %v", path, err)
+	}
+	j, err := yaml.YAMLToJSON(y)
+	if err != nil {
+		return nil, fmt.Errorf("error converting config file %q from YAML to JSON: %v", path, err)
+	}
+	var entries map[string]any
+	dec := json.NewDecoder(bytes.NewReader(j))
+	dec.UseNumber()
+	if err := dec.Decode(&entries); err != nil {
+		return nil, fmt.Errorf("config file %q is not a map of plugin args: %v", path, err)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var args []string
+	var errs []error
+	for _, k := range keys {
+		a, err := configFileEntry(k, entries[k])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		args = append(args, a...)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config file %q:\n%w", path, errors.Join(errs...))
+	}
+	return args, nil
+}
+
+// configFileEntry returns the plugin arguments of the given entry of a config
+// file, validating its key and the type of its value.
+func configFileEntry(key string, val any) ([]string, error) {
+	if err, ok := DeprecatedArgs[key]; ok {
+		return nil, fmt.Errorf("key %q: %w", key, err)
+	}
+	if key == configFileArg {
+		return nil, fmt.Errorf("key %q: config files cannot be nested", key)
+	}
+	a, ok := pluginArgs[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", key)
+	}
+	if a.boolean() {
+		b, ok := val.(bool)
+		if !ok {
+			return nil, fmt.Errorf("key %q: want a boolean, got %s", key, jsonType(val))
+		}
+		if !b {
+			return nil, nil
+		}
+		return []string{key}, nil
+	}
+	if !a.prefix {
+		switch v := val.(type) {
+		case string:
+			return []string{key + "=" + v}, nil
+		case json.Number:
+			return []string{key + "=" + v.String()}, nil
+		}
+		return nil, fmt.Errorf("key %q: want a string or a number, got %s", key, jsonType(val))
+	}
+	var args []string
+	switch v := val.(type) {
+	case []any:
+		for i, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %q: item %d: want a string, got %s", key, i, jsonType(e))
+			}
+			args = append(args, key+s)
+		}
+	case map[string]any:
+		names := make([]string, 0, len(v))
+		for n := range v {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			s, ok := v[n].(string)
+			if !ok {
+				return nil, fmt.Errorf("key %q: entry %q: want a string, got %s", key, n, jsonType(v[n]))
+			}
+			args = append(args, key+n+"="+s)
+		}
+	default:
+		return nil, fmt.Errorf("key %q: want a list or a map, got %s", key, jsonType(val))
+	}
+	return args, nil
+}
+
+// jsonType describes the type of a decoded JSON value in error messages.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a map"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	conf "github.com/googleapis/gapic-generator-go/internal/grpc_service_config"
+)
+
+func TestConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	yamlFile := writeConfig("gapic.yaml", `
+go-gapic-package: path/to/out;pkg
+module: path
+transport: grpc+rest
+metadata: true
+omit-snippets: false
+copyright-year: 2019
+M:
+  google/example/library/v1/library.proto: new/import/path;pkg
+  google/example/library/v1/shelf.proto: shelf/import/path;pkg
+F_:
+  - ordered_routing_headers
+`)
+	jsonFile := writeConfig("gapic.json", `{"go-gapic-package": "path;pkg", "generate-tests": true, "F_": ["wrapper_types_for_page_size"]}`)
+	fromFile := &generatorConfig{
+		transports:            []transport{grpc, rest},
+		pkgPath:               "path/to/out",
+		pkgName:               "pkg",
+		outDir:                "to/out",
+		modulePrefix:          "path",
+		generateGAPICMetadata: true,
+		copyrightYear:         2019,
+		pkgOverrides: map[string]string{
+			"google/example/library/v1/library.proto": "new/import/path;pkg",
+			"google/example/library/v1/shelf.proto":   "shelf/import/path;pkg",
+		},
+		featureEnablement: map[featureID]struct{}{
+			OrderedRoutingHeadersFeature: struct{}{},
+		},
+	}
+
+	for _, tst := range []struct {
+		name  string
+		param string
+		want  *generatorConfig
+	}{
+		{
+			name:  "yaml",
+			param: "config-file=" + yamlFile,
+			want:  fromFile,
+		},
+		{
+			name:  "json",
+			param: "config-file=" + jsonFile,
+			want: &generatorConfig{
+				transports:    []transport{grpc},
+				pkgPath:       "path",
+				pkgName:       "pkg",
+				outDir:        "path",
+				generateTests: true,
+				featureEnablement: map[featureID]struct{}{
+					WrapperTypesForPageSizeFeature: struct{}{},
+				},
+			},
+		},
+		{
+			name:  "inline args take precedence",
+			param: "transport=rest,config-file=" + yamlFile + ",omit-snippets,Mgoogle/example/library/v1/shelf.proto=other/path;pkg",
+			want: &generatorConfig{
+				transports:            []transport{rest},
+				pkgPath:               "path/to/out",
+				pkgName:               "pkg",
+				outDir:                "to/out",
+				modulePrefix:          "path",
+				generateGAPICMetadata: true,
+				omitSnippets:          true,
+				copyrightYear:         2019,
+				pkgOverrides: map[string]string{
+					"google/example/library/v1/library.proto": "new/import/path;pkg",
+					"google/example/library/v1/shelf.proto":   "other/path;pkg",
+				},
+				featureEnablement: map[featureID]struct{}{
+					OrderedRoutingHeadersFeature: struct{}{},
+				},
+			},
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			got, err := configFromRequest(&tst.param)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, tst.want, cmp.AllowUnexported(generatorConfig{}, conf.Config{})); diff != "" {
+				t.Errorf("got(-), want(+):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, tst := range []struct {
+		name    string
+		content string
+		param   string
+		want    []string
+	}{
+		{
+			name:    "unknown keys",
+			content: "go-gapic-package: path;pkg\ntransports: grpc\nmetdata: true\n",
+			want:    []string{`unknown key "metdata"`, `unknown key "transports"`},
+		},
+		{
+			name:    "mistyped values",
+			content: "go-gapic-package: [path;pkg]\nmetadata: yes please\nM: google/foo.proto\nF_: [1]\n",
+			want: []string{
+				`key "go-gapic-package": want a string or a number, got a list`,
+				`key "metadata": want a boolean, got a string`,
+				`key "M": want a list or a map, got a string`,
+				`key "F_": item 0: want a string, got a number`,
+			},
+		},
+		{
+			name:    "deprecated",
+			content: "gapic-service-config: foo.yaml\n",
+			want:    []string{`key "gapic-service-config": removed`},
+		},
+		{
+			name:    "nested",
+			content: "config-file: other.yaml\n",
+			want:    []string{"config files cannot be nested"},
+		},
+		{
+			name:    "not a map",
+			content: "- metadata\n",
+			want:    []string{"is not a map of plugin args"},
+		},
+		{
+			name:    "invalid value",
+			content: "go-gapic-package: path;pkg\ntransport: tcp\n",
+			want:    []string{`invalid transport option: "tcp"`},
+		},
+		{
+			name:  "missing file",
+			param: "config-file=" + filepath.Join(dir, "missing.yaml"),
+			want:  []string{"error reading config file"},
+		},
+		{
+			name:  "more than once",
+			param: "config-file=a.yaml,config-file=b.yaml",
+			want:  []string{`plugin arg "config-file" given more than once`},
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			param := tst.param
+			if param == "" {
+				path := filepath.Join(dir, strings.ReplaceAll(tst.name, " ", "_")+".yaml")
+				if err := os.WriteFile(path, []byte(tst.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+				param = "config-file=" + path
+			}
+			_, err := configFromRequest(&param)
+			if err == nil {
+				t.Fatalf("configFromRequest(%q) expected error", param)
+			}
+			for _, w := range tst.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("configFromRequest(%q) = %v, want error containing %q", param, err, w)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,674 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package connectconn contains the connection pool used by clients of the
+// Connect transport. It carries the calls of the gRPC API client over HTTP,
+// with either the Connect protocol or gRPC-Web, for services only reachable
+// through proxies speaking those protocols.
+//
+// Calls fail with gRPC status errors, so that the retry settings and error
+// handling of the gRPC transport apply unchanged.
+//
+// The declarations following the import block are copied verbatim into the
+// generated auxiliary.go of any client package with the Connect transport, so
+// they must depend only on the standard library, gRPC and protobuf, and must
+// not reference anything declared outside of this file.
+package connectconn
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Content types of the Connect unary and streaming protocols, and of gRPC-Web.
+const (
+	connectUnaryContentType  = "application/proto"
+	connectStreamContentType = "application/connect+proto"
+	grpcWebContentType       = "application/grpc-web+proto"
+)
+
+// Flags of the envelope of a streamed message.
+const (
+	connectFlagCompressed = 0x01
+	connectFlagEndStream  = 0x02
+	grpcWebFlagTrailer    = 0x80
+)
+
+// connectCodes maps the codes of Connect errors to gRPC codes.
+var connectCodes = map[string]codes.Code{
+	"canceled":            codes.Canceled,
+	"unknown":             codes.Unknown,
+	"invalid_argument":    codes.InvalidArgument,
+	"deadline_exceeded":   codes.DeadlineExceeded,
+	"not_found":           codes.NotFound,
+	"already_exists":      codes.AlreadyExists,
+	"permission_denied":   codes.PermissionDenied,
+	"resource_exhausted":  codes.ResourceExhausted,
+	"failed_precondition": codes.FailedPrecondition,
+	"aborted":             codes.Aborted,
+	"out_of_range":        codes.OutOfRange,
+	"unimplemented":       codes.Unimplemented,
+	"internal":            codes.Internal,
+	"unavailable":         codes.Unavailable,
+	"data_loss":           codes.DataLoss,
+	"unauthenticated":     codes.Unauthenticated,
+}
+
+// connectHTTPCode returns the code of a call which response has the given
+// HTTP status, but no status of its own.
+func connectHTTPCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// connectConnPool implements gtransport.ConnPool on top of an HTTP client,
+// speaking the Connect protocol or gRPC-Web to the service.
+type connectConnPool struct {
+	httpClient *http.Client
+	endpoint   string
+	grpcWeb    bool
+}
+
+var _ grpc.ClientConnInterface = (*connectConnPool)(nil)
+
+// newConnectConnPool returns a pool calling the service at endpoint, its base
+// URL, with httpClient. Calls use gRPC-Web if grpcWeb is set, and the Connect
+// protocol otherwise.
+func newConnectConnPool(httpClient *http.Client, endpoint string, grpcWeb bool) *connectConnPool {
+	return &connectConnPool{
+		httpClient: httpClient,
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		grpcWeb:    grpcWeb,
+	}
+}
+
+// Conn returns nil, as no gRPC connection underlies the pool.
+func (p *connectConnPool) Conn() *grpc.ClientConn {
+	return nil
+}
+
+// Num returns the number of connections of the pool. Connections are managed
+// by the HTTP client, so it is always one.
+func (p *connectConnPool) Num() int {
+	return 1
+}
+
+// Close closes the idle connections of the HTTP client.
+func (p *connectConnPool) Close() error {
+	p.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// connectCallInfo holds the gRPC call options honored by the pool.
+type connectCallInfo struct {
+	header  *metadata.MD
+	trailer *metadata.MD
+	maxSend int
+	maxRecv int
+}
+
+func newConnectCallInfo(opts []grpc.CallOption) *connectCallInfo {
+	// Generated clients lift the gRPC limit on received messages, which is
+	// therefore not applied by default.
+	ci := &connectCallInfo{maxSend: math.MaxInt32, maxRecv: math.MaxInt32}
+	for _, o := range opts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			ci.header = o.HeaderAddr
+		case grpc.TrailerCallOption:
+			ci.trailer = o.TrailerAddr
+		case grpc.MaxSendMsgSizeCallOption:
+			ci.maxSend = o.MaxSendMsgSize
+		case grpc.MaxRecvMsgSizeCallOption:
+			ci.maxRecv = o.MaxRecvMsgSize
+		}
+	}
+	return ci
+}
+
+// newRequest returns the request calling method with body, carrying the
+// outgoing metadata and the deadline of ctx.
+func (p *connectConnPool) newRequest(ctx context.Context, method, contentType string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+method, body)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	for k, vs := range md {
+		for _, v := range vs {
+			if strings.HasSuffix(k, "-bin") {
+				v = base64.RawStdEncoding.EncodeToString([]byte(v))
+			}
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", contentType)
+	if p.grpcWeb {
+		req.Header.Set("X-Grpc-Web", "1")
+	} else {
+		req.Header.Set("Connect-Protocol-Version", "1")
+	}
+	if d, ok := ctx.Deadline(); ok {
+		ms := time.Until(d).Milliseconds()
+		if ms <= 0 {
+			return nil, status.Error(codes.DeadlineExceeded, context.DeadlineExceeded.Error())
+		}
+		// Both protocols bound the number of digits of the timeout.
+		if ms > 99999999 {
+			ms = 99999999
+		}
+		if p.grpcWeb {
+			req.Header.Set("Grpc-Timeout", strconv.FormatInt(ms, 10)+"m")
+		} else {
+			req.Header.Set("Connect-Timeout-Ms", strconv.FormatInt(ms, 10))
+		}
+	}
+	return req, nil
+}
+
+// Invoke performs a unary RPC.
+func (p *connectConnPool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	if p.grpcWeb {
+		// gRPC-Web has no unary protocol, unary calls are streams of a single
+		// message.
+		s, err := p.newStream(ctx, &grpc.StreamDesc{}, method, opts...)
+		if err != nil {
+			return err
+		}
+		// An error sending is reported by RecvMsg.
+		if err := s.SendMsg(args); err != nil && err != io.EOF {
+			s.end(err)
+			return err
+		}
+		s.CloseSend()
+		return s.RecvMsg(reply)
+	}
+
+	ci := newConnectCallInfo(opts)
+	b, err := connectMarshal(args, ci.maxSend)
+	if err != nil {
+		return err
+	}
+	req, err := p.newRequest(ctx, method, connectUnaryContentType, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return connectTransportError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	header, trailer := metadata.MD{}, metadata.MD{}
+	for k, vs := range resp.Header {
+		if t, ok := strings.CutPrefix(k, "Trailer-"); ok {
+			connectAddMetadata(trailer, t, vs)
+		} else {
+			connectAddMetadata(header, k, vs)
+		}
+	}
+	if ci.header != nil {
+		*ci.header = header
+	}
+	if ci.trailer != nil {
+		*ci.trailer = trailer
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		code := connectHTTPCode(resp.StatusCode)
+		var werr connectWireError
+		if b, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)); err == nil && json.Unmarshal(b, &werr) == nil && werr.Code != "" {
+			return werr.err(code)
+		}
+		return status.Errorf(code, "unexpected HTTP status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != connectUnaryContentType {
+		return status.Errorf(codes.Internal, "unexpected content type %q", ct)
+	}
+	b, err = io.ReadAll(io.LimitReader(resp.Body, int64(ci.maxRecv)+1))
+	if err != nil {
+		return connectTransportError(ctx, err)
+	}
+	if len(b) > ci.maxRecv {
+		return status.Errorf(codes.ResourceExhausted, "received message larger than max (%d)", ci.maxRecv)
+	}
+	return connectUnmarshal(b, reply)
+}
+
+// NewStream begins a streaming RPC.
+func (p *connectConnPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.newStream(ctx, desc, method, opts...)
+}
+
+func (p *connectConnPool) newStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (*connectStream, error) {
+	contentType, endFlag := connectStreamContentType, byte(connectFlagEndStream)
+	if p.grpcWeb {
+		contentType, endFlag = grpcWebContentType, grpcWebFlagTrailer
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	req, err := p.newRequest(ctx, method, contentType, pr)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s := &connectStream{
+		ctx:     ctx,
+		cancel:  cancel,
+		desc:    desc,
+		ci:      newConnectCallInfo(opts),
+		grpcWeb: p.grpcWeb,
+		endFlag: endFlag,
+		pw:      pw,
+		done:    make(chan struct{}),
+	}
+	// Writing the request stream fails once the call is over.
+	context.AfterFunc(ctx, func() { pr.CloseWithError(io.EOF) })
+	go func() {
+		defer close(s.done)
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			pr.CloseWithError(io.EOF)
+			s.err = connectTransportError(ctx, err)
+			return
+		}
+		s.resp = resp
+		s.header = metadata.MD{}
+		for k, vs := range resp.Header {
+			connectAddMetadata(s.header, k, vs)
+		}
+		if s.ci.header != nil {
+			*s.ci.header = s.header
+		}
+		if err := s.checkResponse(); err != nil {
+			// The call is over, and the rest of the request is not needed.
+			pr.CloseWithError(io.EOF)
+			s.err = err
+		}
+	}()
+	return s, nil
+}
+
+// connectStream is a grpc.ClientStream carried by a single HTTP request, which
+// body is the request stream and which response is the response stream.
+type connectStream struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	desc    *grpc.StreamDesc
+	ci      *connectCallInfo
+	grpcWeb bool
+	endFlag byte
+
+	pw         *io.PipeWriter
+	sendMu     sync.Mutex
+	sendClosed bool
+
+	// done is closed once the response headers are received, or the request
+	// failed with err.
+	done   chan struct{}
+	resp   *http.Response
+	header metadata.MD
+	err    error
+
+	trailer metadata.MD
+	// recvErr is the error that ended the response stream, if any.
+	recvErr error
+}
+
+// checkResponse returns the status of a response that carries no stream.
+func (s *connectStream) checkResponse() error {
+	resp := s.resp
+	if s.grpcWeb {
+		// A response without messages may carry its status in its headers.
+		if resp.Header.Get("Grpc-Status") != "" {
+			s.trailer = s.header
+			return grpcWebStatus(resp.Header)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return status.Errorf(connectHTTPCode(resp.StatusCode), "unexpected HTTP status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/grpc-web") {
+			return status.Errorf(codes.Internal, "unexpected content type %q", ct)
+		}
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return status.Errorf(connectHTTPCode(resp.StatusCode), "unexpected HTTP status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != connectStreamContentType {
+		return status.Errorf(codes.Internal, "unexpected content type %q", ct)
+	}
+	return nil
+}
+
+func (s *connectStream) wait() error {
+	select {
+	case <-s.done:
+		return s.err
+	case <-s.ctx.Done():
+		return status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+// Header returns the header metadata received from the server.
+func (s *connectStream) Header() (metadata.MD, error) {
+	if err := s.wait(); err != nil {
+		return s.header, err
+	}
+	return s.header, nil
+}
+
+// Trailer returns the trailer metadata received from the server, once
+// RecvMsg returned a non-nil error.
+func (s *connectStream) Trailer() metadata.MD {
+	return s.trailer
+}
+
+// Context returns the context of the stream.
+func (s *connectStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg sends m on the request stream. It returns io.EOF if the call is
+// already over, the status of which is then reported by RecvMsg.
+func (s *connectStream) SendMsg(m any) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.sendClosed {
+		return status.Error(codes.Internal, "SendMsg called after CloseSend")
+	}
+	b, err := connectMarshal(m, s.ci.maxSend)
+	if err != nil {
+		return err
+	}
+	env := make([]byte, 5+len(b))
+	binary.BigEndian.PutUint32(env[1:], uint32(len(b)))
+	copy(env[5:], b)
+	if _, err := s.pw.Write(env); err != nil {
+		return io.EOF
+	}
+	return nil
+}
+
+// CloseSend ends the request stream.
+func (s *connectStream) CloseSend() error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if s.sendClosed {
+		return nil
+	}
+	s.sendClosed = true
+	return s.pw.Close()
+}
+
+// RecvMsg receives the next message of the response stream into m. It returns
+// io.EOF once the stream ended with an OK status, and the status error of the
+// call otherwise.
+func (s *connectStream) RecvMsg(m any) error {
+	if s.recvErr != nil {
+		return s.recvErr
+	}
+	err := s.wait()
+	if err == nil {
+		err = s.recvMsg(m)
+		if err == io.EOF && !s.desc.ServerStreams {
+			err = status.Error(codes.Internal, "cardinality violation: received no response message from a non-streaming RPC")
+		}
+		if err == nil && !s.desc.ServerStreams {
+			// Read up to the end of the stream to report the status of the
+			// call, like gRPC does.
+			if err = s.recvMsg(nil); err == io.EOF {
+				err = nil
+				s.end(io.EOF)
+			}
+		}
+	}
+	if err != nil {
+		s.end(err)
+	}
+	return err
+}
+
+// end records the error ending the response stream, and releases the call.
+func (s *connectStream) end(err error) {
+	s.recvErr = err
+	s.cancel()
+	// The request returns promptly once canceled.
+	<-s.done
+	if s.resp != nil {
+		s.resp.Body.Close()
+	}
+	if s.ci.trailer != nil {
+		*s.ci.trailer = s.trailer
+	}
+}
+
+// recvMsg reads the next envelope of the response stream. m is nil if no
+// further message is expected.
+func (s *connectStream) recvMsg(m any) error {
+	var hdr [5]byte
+	if _, err := io.ReadFull(s.resp.Body, hdr[:]); err != nil {
+		if err == io.EOF {
+			return status.Error(codes.Internal, "response stream ended without a status")
+		}
+		return s.readError(err)
+	}
+	flags, n := hdr[0], binary.BigEndian.Uint32(hdr[1:])
+	if flags&s.endFlag == 0 && int64(n) > int64(s.ci.maxRecv) {
+		return status.Errorf(codes.ResourceExhausted, "received message larger than max (%d vs. %d)", n, s.ci.maxRecv)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(s.resp.Body, data); err != nil {
+		return s.readError(err)
+	}
+	if flags&s.endFlag != 0 {
+		return s.endOfStream(data)
+	}
+	if flags&connectFlagCompressed != 0 {
+		return status.Error(codes.Internal, "received a compressed message, but compression was not requested")
+	}
+	if m == nil {
+		return status.Error(codes.Internal, "cardinality violation: received more than one response message from a non-streaming RPC")
+	}
+	return connectUnmarshal(data, m)
+}
+
+func (s *connectStream) readError(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return connectTransportError(s.ctx, err)
+}
+
+// endOfStream parses the message ending the response stream. It returns io.EOF
+// if the status of the call is OK.
+func (s *connectStream) endOfStream(data []byte) error {
+	if s.grpcWeb {
+		h := http.Header{}
+		for _, line := range strings.Split(string(data), "\r\n") {
+			k, v, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			h.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+		s.trailer = metadata.MD{}
+		for k, vs := range h {
+			connectAddMetadata(s.trailer, k, vs)
+		}
+		return grpcWebStatus(h)
+	}
+
+	var end struct {
+		Error    *connectWireError   `json:"error"`
+		Metadata map[string][]string `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &end); err != nil {
+		return status.Errorf(codes.Internal, "invalid end of stream message: %v", err)
+	}
+	s.trailer = metadata.MD{}
+	for k, vs := range end.Metadata {
+		connectAddMetadata(s.trailer, k, vs)
+	}
+	if end.Error != nil {
+		return end.Error.err(codes.Unknown)
+	}
+	return io.EOF
+}
+
+// connectWireError is the JSON representation of a Connect error.
+type connectWireError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"details"`
+}
+
+// err returns e as a status error, with the given code if e has none.
+func (e *connectWireError) err(fallback codes.Code) error {
+	c, ok := connectCodes[e.Code]
+	if !ok {
+		c = fallback
+	}
+	st := &spb.Status{Code: int32(c), Message: e.Message}
+	for _, d := range e.Details {
+		v, err := connectDecodeBinary(d.Value)
+		if err != nil {
+			continue
+		}
+		st.Details = append(st.Details, &anypb.Any{TypeUrl: "type.googleapis.com/" + d.Type, Value: v})
+	}
+	return status.ErrorProto(st)
+}
+
+// grpcWebStatus returns the status carried by the given gRPC-Web trailers, or
+// io.EOF if it is OK.
+func grpcWebStatus(h http.Header) error {
+	c, err := strconv.ParseUint(h.Get("Grpc-Status"), 10, 32)
+	if err != nil {
+		return status.Errorf(codes.Internal, "invalid grpc-status %q", h.Get("Grpc-Status"))
+	}
+	if c == uint64(codes.OK) {
+		return io.EOF
+	}
+	if details := h.Get("Grpc-Status-Details-Bin"); details != "" {
+		st := &spb.Status{}
+		if b, err := connectDecodeBinary(details); err == nil && proto.Unmarshal(b, st) == nil && st.GetCode() == int32(c) {
+			return status.ErrorProto(st)
+		}
+	}
+	msg := h.Get("Grpc-Message")
+	if m, err := url.PathUnescape(msg); err == nil {
+		msg = m
+	}
+	return status.Error(codes.Code(c), msg)
+}
+
+// connectAddMetadata adds the values of the HTTP header k to md, unless it is
+// part of the protocol.
+func connectAddMetadata(md metadata.MD, k string, vs []string) {
+	k = strings.ToLower(k)
+	switch {
+	case strings.HasPrefix(k, "connect-"), k == "grpc-status", k == "grpc-message", k == "grpc-status-details-bin":
+		return
+	}
+	for _, v := range vs {
+		if strings.HasSuffix(k, "-bin") {
+			if b, err := connectDecodeBinary(v); err == nil {
+				v = string(b)
+			}
+		}
+		md.Append(k, v)
+	}
+}
+
+// connectDecodeBinary decodes the value of a binary header, which may or may
+// not be padded.
+func connectDecodeBinary(v string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(v, "="))
+}
+
+func connectMarshal(m any, max int) ([]byte, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "%T is not a protocol buffer message", m)
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error marshaling the request: %v", err)
+	}
+	if len(b) > max {
+		return nil, status.Errorf(codes.ResourceExhausted, "trying to send message larger than max (%d vs. %d)", len(b), max)
+	}
+	return b, nil
+}
+
+func connectUnmarshal(b []byte, m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "%T is not a protocol buffer message", m)
+	}
+	if err := proto.Unmarshal(b, msg); err != nil {
+		return status.Errorf(codes.Internal, "error unmarshaling the response: %v", err)
+	}
+	return nil
+}
+
+// connectTransportError converts an error of the HTTP client into a status
+// error.
+func connectTransportError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return status.FromContextError(ctxErr).Err()
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &se) {
+		return se.GRPCStatus().Err()
+	}
+	return status.Error(codes.Unavailable, fmt.Sprintf("connection error: %v", err))
+}
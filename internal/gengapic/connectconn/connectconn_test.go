@@ -0,0 +1,341 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connectconn
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echoServer serves the Connect protocol and gRPC-Web. Unary Connect calls
+// respond with the request followed by "!". Streams respond to every request
+// message with one message per word of its value, and end with an ABORTED
+// status on the value "fail".
+func echoServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Echo-Method", r.URL.Path)
+		w.Header().Set("X-Echo-Routing", r.Header.Get("X-Goog-Request-Params"))
+		w.Header().Set("X-Echo-Timeout", r.Header.Get("Connect-Timeout-Ms")+r.Header.Get("Grpc-Timeout"))
+		w.Header().Set("X-Echo-Bin", r.Header.Get("X-Custom-Bin"))
+		switch r.Header.Get("Content-Type") {
+		case connectUnaryContentType:
+			serveConnectUnary(t, w, r)
+		case connectStreamContentType, grpcWebContentType:
+			serveStream(t, w, r)
+		default:
+			http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		}
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	return srv
+}
+
+func serveConnectUnary(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Connect-Protocol-Version") != "1" {
+		http.Error(w, "missing Connect-Protocol-Version", http.StatusBadRequest)
+		return
+	}
+	b, _ := io.ReadAll(r.Body)
+	req := &wrapperspb.StringValue{}
+	if err := proto.Unmarshal(b, req); err != nil {
+		t.Error(err)
+		return
+	}
+	switch req.GetValue() {
+	case "fail":
+		detail, _ := proto.Marshal(wrapperspb.String("detail"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, `{"code":"unavailable","message":"try again","details":[{"type":"google.protobuf.StringValue","value":%q}]}`,
+			base64.RawStdEncoding.EncodeToString(detail))
+	case "proxy":
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	case "slow":
+		<-r.Context().Done()
+	default:
+		w.Header().Set("Content-Type", connectUnaryContentType)
+		w.Header().Set("Trailer-X-Trailer", "trailer")
+		b, _ := proto.Marshal(wrapperspb.String(req.GetValue() + "!"))
+		w.Write(b)
+	}
+}
+
+func serveStream(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	grpcWeb := r.Header.Get("X-Grpc-Web") == "1"
+	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+	rc := http.NewResponseController(w)
+	if grpcWeb {
+		// Send the status in the headers of a response without messages.
+		if r.Header.Get("X-Trailers-Only") != "" {
+			w.Header().Set("Grpc-Status", fmt.Sprint(int(codes.NotFound)))
+			w.Header().Set("Grpc-Message", "no%20such%20thing")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	} else if err := rc.EnableFullDuplex(); err != nil {
+		t.Error(err)
+	}
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	writeEnvelope := func(flags byte, b []byte) {
+		env := binary.BigEndian.AppendUint32([]byte{flags}, uint32(len(b)))
+		w.Write(append(env, b...))
+		rc.Flush()
+	}
+	end := func(failed bool) {
+		if grpcWeb {
+			trailer := "grpc-status: 0\r\nx-trailer: trailer\r\n"
+			if failed {
+				trailer = fmt.Sprintf("grpc-status: %d\r\ngrpc-message: failed%%20here\r\nx-trailer: trailer\r\n", codes.Aborted)
+			}
+			writeEnvelope(grpcWebFlagTrailer, []byte(trailer))
+			return
+		}
+		end := `{"metadata":{"x-trailer":["trailer"]}}`
+		if failed {
+			end = `{"error":{"code":"aborted","message":"failed here"},"metadata":{"x-trailer":["trailer"]}}`
+		}
+		writeEnvelope(connectFlagEndStream, []byte(end))
+	}
+
+	for {
+		var hdr [5]byte
+		if _, err := io.ReadFull(r.Body, hdr[:]); err == io.EOF {
+			end(false)
+			return
+		} else if err != nil {
+			t.Error(err)
+			return
+		}
+		b := make([]byte, binary.BigEndian.Uint32(hdr[1:]))
+		io.ReadFull(r.Body, b)
+		req := &wrapperspb.StringValue{}
+		if err := proto.Unmarshal(b, req); err != nil {
+			t.Error(err)
+			return
+		}
+		if req.GetValue() == "fail" {
+			end(true)
+			return
+		}
+		for _, word := range strings.Fields(req.GetValue()) {
+			b, _ := proto.Marshal(wrapperspb.String(word))
+			writeEnvelope(0, b)
+		}
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	srv := echoServer(t)
+	defer srv.Close()
+
+	for _, grpcWeb := range []bool{false, true} {
+		p := newConnectConnPool(srv.Client(), srv.URL+"/", grpcWeb)
+		t.Run(fmt.Sprintf("grpcWeb=%v", grpcWeb), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			ctx = metadata.AppendToOutgoingContext(ctx, "x-goog-request-params", "name=foo", "x-custom-bin", "\x00\x01")
+
+			var header, trailer metadata.MD
+			reply := &wrapperspb.StringValue{}
+			err := p.Invoke(ctx, "/test.Echo/Echo", wrapperspb.String("hello"), reply, grpc.Header(&header), grpc.Trailer(&trailer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "hello!"
+			if grpcWeb {
+				want = "hello"
+			}
+			if reply.GetValue() != want {
+				t.Errorf("got reply %q, want %q", reply.GetValue(), want)
+			}
+			for k, want := range map[string]string{
+				"x-echo-method":  "/test.Echo/Echo",
+				"x-echo-routing": "name=foo",
+				// Binary values are encoded on the wire both ways.
+				"x-echo-bin": "\x00\x01",
+			} {
+				if got := header.Get(k); len(got) != 1 || got[0] != want {
+					t.Errorf("got header %s = %q, want %q", k, got, want)
+				}
+			}
+			if got := header.Get("x-echo-timeout"); len(got) != 1 || got[0] == "" {
+				t.Error("the deadline of the call was not sent")
+			}
+			if got := trailer.Get("x-trailer"); len(got) != 1 || got[0] != "trailer" {
+				t.Errorf("got trailer %q, want %q", got, "trailer")
+			}
+		})
+	}
+}
+
+func TestInvoke_Errors(t *testing.T) {
+	srv := echoServer(t)
+	defer srv.Close()
+	connect := newConnectConnPool(srv.Client(), srv.URL, false)
+	grpcWeb := newConnectConnPool(srv.Client(), srv.URL, true)
+
+	for _, tst := range []struct {
+		name     string
+		pool     *connectConnPool
+		ctx      func() (context.Context, context.CancelFunc)
+		value    string
+		wantCode codes.Code
+		wantMsg  string
+	}{
+		{name: "connect error", pool: connect, value: "fail", wantCode: codes.Unavailable, wantMsg: "try again"},
+		{name: "connect http error", pool: connect, value: "proxy", wantCode: codes.Unavailable},
+		{
+			name:  "connect deadline",
+			pool:  connect,
+			value: "slow",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantCode: codes.DeadlineExceeded,
+		},
+		{name: "grpc-web error", pool: grpcWeb, value: "fail", wantCode: codes.Aborted, wantMsg: "failed here"},
+		{
+			name:  "grpc-web trailers only",
+			pool:  grpcWeb,
+			value: "hello",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx := metadata.AppendToOutgoingContext(context.Background(), "x-trailers-only", "1")
+				return context.WithCancel(ctx)
+			},
+			wantCode: codes.NotFound,
+			wantMsg:  "no such thing",
+		},
+		{name: "grpc-web no response", pool: grpcWeb, value: "", wantCode: codes.Internal},
+		{name: "grpc-web too many responses", pool: grpcWeb, value: "hello world", wantCode: codes.Internal},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if tst.ctx != nil {
+				ctx, cancel = tst.ctx()
+			}
+			defer cancel()
+			err := tst.pool.Invoke(ctx, "/test.Echo/Echo", wrapperspb.String(tst.value), &wrapperspb.StringValue{})
+			st, ok := status.FromError(err)
+			if !ok || st.Code() != tst.wantCode {
+				t.Fatalf("Invoke() error = %v, want code %v", err, tst.wantCode)
+			}
+			if tst.wantMsg != "" && st.Message() != tst.wantMsg {
+				t.Errorf("got message %q, want %q", st.Message(), tst.wantMsg)
+			}
+		})
+	}
+
+	t.Run("connect error details", func(t *testing.T) {
+		err := connect.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String("fail"), &wrapperspb.StringValue{})
+		details := status.Convert(err).Details()
+		if len(details) != 1 {
+			t.Fatalf("got details %v, want one", details)
+		}
+		if d, ok := details[0].(*wrapperspb.StringValue); !ok || d.GetValue() != "detail" {
+			t.Errorf("got detail %v, want %q", details[0], "detail")
+		}
+	})
+}
+
+func TestNewStream(t *testing.T) {
+	srv := echoServer(t)
+	defer srv.Close()
+
+	for _, grpcWeb := range []bool{false, true} {
+		p := newConnectConnPool(srv.Client(), srv.URL, grpcWeb)
+		desc := &grpc.StreamDesc{ServerStreams: true, ClientStreams: true}
+		recv := func(t *testing.T, s grpc.ClientStream) string {
+			t.Helper()
+			m := &wrapperspb.StringValue{}
+			if err := s.RecvMsg(m); err != nil {
+				t.Fatal(err)
+			}
+			return m.GetValue()
+		}
+
+		t.Run(fmt.Sprintf("bidi grpcWeb=%v", grpcWeb), func(t *testing.T) {
+			s, err := p.NewStream(context.Background(), desc, "/test.Echo/Chat")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SendMsg(wrapperspb.String("hello streaming")); err != nil {
+				t.Fatal(err)
+			}
+			if got := recv(t, s) + " " + recv(t, s); got != "hello streaming" {
+				t.Errorf("got %q", got)
+			}
+			header, err := s.Header()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := header.Get("x-echo-method"); len(got) != 1 || got[0] != "/test.Echo/Chat" {
+				t.Errorf("got header %q", got)
+			}
+			if err := s.SendMsg(wrapperspb.String("world")); err != nil {
+				t.Fatal(err)
+			}
+			if got := recv(t, s); got != "world" {
+				t.Errorf("got %q", got)
+			}
+			if err := s.CloseSend(); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.RecvMsg(&wrapperspb.StringValue{}); err != io.EOF {
+				t.Errorf("RecvMsg() error = %v, want io.EOF", err)
+			}
+			if got := s.Trailer().Get("x-trailer"); len(got) != 1 || got[0] != "trailer" {
+				t.Errorf("got trailer %q", got)
+			}
+		})
+
+		t.Run(fmt.Sprintf("error grpcWeb=%v", grpcWeb), func(t *testing.T) {
+			s, err := p.NewStream(context.Background(), desc, "/test.Echo/Chat")
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.SendMsg(wrapperspb.String("hello"))
+			s.SendMsg(wrapperspb.String("fail"))
+			s.CloseSend()
+			if got := recv(t, s); got != "hello" {
+				t.Errorf("got %q", got)
+			}
+			err = s.RecvMsg(&wrapperspb.StringValue{})
+			if status.Code(err) != codes.Aborted {
+				t.Errorf("RecvMsg() error = %v, want code %v", err, codes.Aborted)
+			}
+			if err2 := s.RecvMsg(&wrapperspb.StringValue{}); err2 != err {
+				t.Errorf("second RecvMsg() error = %v, want %v", err2, err)
+			}
+		})
+	}
+}
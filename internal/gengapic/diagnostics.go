@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// diagnosticClass identifies a kind of input the generator skips or adjusts
+// rather than failing on.
+type diagnosticClass string
+
+const (
+	// A snippet is not generated for an RPC.
+	skippedSnippet diagnosticClass = "skipped-snippet"
+	// An API of the service config is neither a mixin nor a known service.
+	unknownMixin diagnosticClass = "unknown-mixin"
+	// The max_attempts of a retry or hedging policy exceeds the allowed cap.
+	cappedMaxAttempts diagnosticClass = "capped-max-attempts"
+	// A method_config name of the gRPC service config matches no RPC.
+	unmatchedMethodConfig diagnosticClass = "unmatched-method-config"
+	// No resource name could be inferred from the HTTP path of an RPC.
+	unmatchedHeuristicTarget diagnosticClass = "unmatched-heuristic-target"
+	// A resource, or a pattern of it, has no typed resource name.
+	skippedResourceName diagnosticClass = "skipped-resource-name"
+	// A method_signature of an RPC has no flattened variant.
+	skippedFlattenedMethod diagnosticClass = "skipped-flattened-method"
+	// An HttpBody RPC has no download variant.
+	skippedHTTPBodyReader diagnosticClass = "skipped-http-body-reader"
+)
+
+// severity is how much a diagnostic is of concern.
+type severity string
+
+const (
+	severityInfo    severity = "INFO"
+	severityWarning severity = "WARNING"
+	severityError   severity = "ERROR"
+)
+
+// diagnosticClasses maps the known diagnostic classes to their severity,
+// unless they are made errors by the strict option.
+var diagnosticClasses = map[diagnosticClass]severity{
+	skippedSnippet:           severityInfo,
+	unknownMixin:             severityWarning,
+	cappedMaxAttempts:        severityWarning,
+	unmatchedMethodConfig:    severityWarning,
+	unmatchedHeuristicTarget: severityWarning,
+	skippedResourceName:      severityInfo,
+	skippedFlattenedMethod:   severityInfo,
+	skippedHTTPBodyReader:    severityInfo,
+}
+
+// diagnosticsFile is the name of the report of the diagnostics, in the output
+// directory.
+const diagnosticsFile = "generator_diagnostics.json"
+
+// diagnosticsOutput is where the diagnostics are written as text.
+var diagnosticsOutput io.Writer = os.Stderr
+
+// diagnostic records something the generator skipped or adjusted.
+type diagnostic struct {
+	Severity severity        `json:"severity"`
+	Class    diagnosticClass `json:"class"`
+	// The fully-qualified name of the proto element concerned, e.g.
+	// google.foo.v1.Foo.GetBar, or of the config entry naming it.
+	Element string `json:"element"`
+	Reason  string `json:"reason"`
+}
+
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", strings.ToLower(string(d.Severity)), d.Element, d.Reason, d.Class)
+}
+
+// diagnose records a diagnostic of the given class about the given element.
+// The same diagnostic is only recorded once.
+func (g *generator) diagnose(class diagnosticClass, element, format string, args ...interface{}) {
+	sev := diagnosticClasses[class]
+	if g.cfg != nil && g.cfg.strict[class] {
+		sev = severityError
+	}
+	g.addDiagnostic(diagnostic{
+		Severity: sev,
+		Class:    class,
+		Element:  element,
+		Reason:   fmt.Sprintf(format, args...),
+	})
+}
+
+func (g *generator) addDiagnostic(d diagnostic) {
+	for _, e := range g.diagnostics {
+		if e == d {
+			return
+		}
+	}
+	g.diagnostics = append(g.diagnostics, d)
+}
+
+// reportDiagnostics writes the diagnostics recorded while generating to
+// stderr and to the diagnostics file, as configured. If the strict option
+// made any of them errors, it returns an error listing those.
+func (g *generator) reportDiagnostics() error {
+	if g.cfg.diagnosticsStderr {
+		for _, d := range g.diagnostics {
+			fmt.Fprintln(diagnosticsOutput, d)
+		}
+	}
+
+	var errs []string
+	for _, d := range g.diagnostics {
+		if d.Severity == severityError {
+			errs = append(errs, d.String())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("strict mode failed on %d diagnostic(s):\n%s", len(errs), strings.Join(errs, "\n"))
+	}
+
+	if !g.cfg.diagnostics {
+		return nil
+	}
+	report := struct {
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}{
+		Diagnostics: append([]diagnostic{}, g.diagnostics...),
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	g.resp.File = append(g.resp.File, &pluginpb.CodeGeneratorResponse_File{
+		Name:    proto.String(filepath.Join(g.cfg.outDir, diagnosticsFile)),
+		Content: proto.String(string(data) + "\n"),
+	})
+	return nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// diagnosticsRequest returns the request of thingsRequest, with a server
+// streaming RPC and configs that the generator partially ignores.
+func diagnosticsRequest(t *testing.T, param string) *pluginpb.CodeGeneratorRequest {
+	dir := t.TempDir()
+	serviceYAML := filepath.Join(dir, "my_v1.yaml")
+	err := os.WriteFile(serviceYAML, []byte(`type: google.api.Service
+config_version: 3
+name: my.googleapis.com
+apis:
+- name: my.pkg.Foo
+- name: google.example.Unknown
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcJSON := filepath.Join(dir, "my_grpc_service_config.json")
+	err = os.WriteFile(grpcJSON, []byte(`{
+  "methodConfig": [
+    {
+      "name": [{"service": "my.pkg.Foo"}, {"service": "my.pkg.Bar", "method": "DeleteThings"}],
+      "timeout": "60s",
+      "retryPolicy": {
+        "maxAttempts": 8,
+        "initialBackoff": "0.1s",
+        "maxBackoff": "1s",
+        "backoffMultiplier": 1.3,
+        "retryableStatusCodes": ["UNAVAILABLE"]
+      }
+    },
+    {
+      "name": [{"service": "my.pkg.Gone"}, {}]
+    }
+  ]
+}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := thingsRequest("go-gapic-package=github.com/googleapis/mypkg;mypkg,api-service-config=" + serviceYAML + ",grpc-service-config=" + grpcJSON + "," + param)
+	foo := req.GetProtoFile()[0].GetService()[0]
+	foo.Method = append(foo.Method, &descriptorpb.MethodDescriptorProto{
+		Name:            proto.String("WatchThings"),
+		InputType:       proto.String(".my.pkg.ListThingsRequest"),
+		OutputType:      proto.String(".my.pkg.Thing"),
+		ServerStreaming: proto.Bool(true),
+	})
+	return req
+}
+
+func TestDiagnostics(t *testing.T) {
+	var stderr bytes.Buffer
+	defer func(w io.Writer) { diagnosticsOutput = w }(diagnosticsOutput)
+	diagnosticsOutput = &stderr
+
+	resp, err := gen(diagnosticsRequest(t, "diagnostics,diagnostics-stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report string
+	for _, f := range resp.GetFile() {
+		if f.GetName() == filepath.Join("github.com/googleapis/mypkg", diagnosticsFile) {
+			report = f.GetContent()
+		}
+	}
+	if report == "" {
+		t.Fatalf("%s was not generated", diagnosticsFile)
+	}
+	txtdiff.Diff(t, report, filepath.Join("testdata", "generator_diagnostics.want"))
+
+	wantStderr := []string{
+		"warning: google.example.Unknown: API of the service config is neither a supported mixin nor a known service, it is ignored [unknown-mixin]",
+		"warning: my.pkg.Foo: retry policy max_attempts 8 is capped to 5 [capped-max-attempts]",
+		"warning: my.pkg.Bar.DeleteThings: method_config name matches no RPC, it is ignored [unmatched-method-config]",
+		"warning: my.pkg.Gone: method_config name matches no RPC, it is ignored [unmatched-method-config]",
+		"info: my.pkg.Foo.WatchThings: snippets are not generated for client or server streaming RPCs [skipped-snippet]",
+	}
+	if got, want := stderr.String(), strings.Join(wantStderr, "\n")+"\n"; got != want {
+		t.Errorf("diagnostics written to stderr:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Without the options, nothing is reported.
+	stderr.Reset()
+	resp, err = gen(diagnosticsRequest(t, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range resp.GetFile() {
+		if filepath.Base(f.GetName()) == diagnosticsFile {
+			t.Errorf("%s generated without the diagnostics option", diagnosticsFile)
+		}
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("diagnostics written to stderr without the diagnostics-stderr option:\n%s", stderr.String())
+	}
+}
+
+func TestDiagnosticsStrict(t *testing.T) {
+	for _, tst := range []struct {
+		strict string
+		want   []string
+	}{
+		{
+			strict: "skipped-snippet",
+			want: []string{
+				"strict mode failed on 1 diagnostic(s)",
+				"error: my.pkg.Foo.WatchThings: snippets are not generated",
+			},
+		},
+		{
+			strict: "capped-max-attempts+unmatched-method-config",
+			want: []string{
+				"strict mode failed on 3 diagnostic(s)",
+				"error: my.pkg.Foo: retry policy max_attempts 8 is capped to 5 [capped-max-attempts]",
+				"error: my.pkg.Bar.DeleteThings",
+				"error: my.pkg.Gone",
+			},
+		},
+		{
+			strict: "all",
+			want:   []string{"strict mode failed on 5 diagnostic(s)"},
+		},
+	} {
+		_, err := gen(diagnosticsRequest(t, "strict="+tst.strict))
+		if err == nil {
+			t.Errorf("strict=%s: expected error", tst.strict)
+			continue
+		}
+		for _, w := range tst.want {
+			if !strings.Contains(err.Error(), w) {
+				t.Errorf("strict=%s: got error %q, want it to contain %q", tst.strict, err, w)
+			}
+		}
+	}
+
+	// Classes without diagnostics do not fail.
+	if _, err := gen(diagnosticsRequest(t, "strict=unmatched-heuristic-target")); err != nil {
+		t.Errorf("strict=unmatched-heuristic-target: got unexpected error: %v", err)
+	}
+}
+
+func TestDiagnoseHeuristicTarget(t *testing.T) {
+	batchGet := &descriptorpb.MethodDescriptorProto{
+		Name:      proto.String("BatchGetFoos"),
+		InputType: proto.String(".google.foo.v1.BatchGetFoosRequest"),
+		Options:   &descriptorpb.MethodOptions{},
+	}
+	proto.SetExtension(batchGet.GetOptions(), annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{
+			Get: "/v1/foos:batchGet",
+		},
+	})
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("google.foo.v1"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("google.golang.org/genproto/googleapis/foo/v1"),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name:   proto.String("FooService"),
+				Method: []*descriptorpb.MethodDescriptorProto{batchGet},
+			},
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{Name: proto.String("BatchGetFoosRequest")},
+		},
+	}
+	g, err := newGenerator(&pluginpb.CodeGeneratorRequest{
+		ProtoFile: []*descriptorpb.FileDescriptorProto{file},
+		Parameter: proto.String("go-gapic-package=cloud.google.com/go/foo/apiv1;foo,F_dynamic_resource_heuristics"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := g.resourceNameField(batchGet); got != nil {
+		t.Fatalf("resourceNameField() = %v, want nil", got)
+	}
+	want := []diagnostic{
+		{
+			Severity: severityWarning,
+			Class:    unmatchedHeuristicTarget,
+			Element:  "google.foo.v1.FooService.BatchGetFoos",
+			Reason:   `no resource name inferred from the HTTP path "/v1/foos:batchGet"`,
+		},
+	}
+	if diff := cmp.Diff(g.diagnostics, want); diff != "" {
+		t.Errorf("got(-),want(+):\n%s", diff)
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/googleapis/gapic-generator-go/internal/license"
 	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
 	"github.com/googleapis/gapic-generator-go/internal/printer"
 	"google.golang.org/protobuf/types/descriptorpb"
@@ -38,7 +37,7 @@ func (g *generator) genDocFile(year int, services []*descriptorpb.ServiceDescrip
 
 	p := g.printf
 
-	p(license.Apache, year)
+	p("%s", strings.TrimSpace(g.licenseHeader(year)))
 	p("")
 
 	if g.apiName != "" {
@@ -129,9 +128,11 @@ func (g *generator) genDocFile(year int, services []*descriptorpb.ServiceDescrip
 	p("// Use of Context")
 	p("//")
 	clientName := servName
-	// Guard against double-suffixing if the caller already appended "REST".
-	if len(g.cfg.transports) == 1 && g.cfg.transports[0] == rest && !strings.HasSuffix(servName, restClientSuffix) {
-		clientName += restClientSuffix
+	// Guard against double-suffixing if the caller already appended the suffix.
+	if len(g.cfg.transports) == 1 {
+		if suffix := clientSuffix(g.cfg.transports[0]); !strings.HasSuffix(servName, suffix) {
+			clientName += suffix
+		}
 	}
 	p("// The ctx passed to New%sClient is used for authentication requests and", clientName)
 	p("// for creating the underlying connection, but is not used for subsequent calls.")
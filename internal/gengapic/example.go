@@ -24,7 +24,23 @@ import (
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
-const restClientSuffix = "REST"
+const (
+	restClientSuffix    = "REST"
+	connectClientSuffix = "Connect"
+)
+
+// clientSuffix returns the suffix of the name of the constructor of the
+// client for transport t, which is New<Service><suffix>Client.
+func clientSuffix(t transport) string {
+	switch t {
+	case rest:
+		return restClientSuffix
+	case connect:
+		return connectClientSuffix
+	default:
+		return ""
+	}
+}
 
 func (g *generator) genExampleFile(serv *descriptorpb.ServiceDescriptorProto) error {
 	g.clientProtoPkg = g.descInfo.ParentFile[serv].GetPackage()
@@ -69,6 +85,7 @@ func (g *generator) genExampleIteratorFile(serv *descriptorpb.ServiceDescriptorP
 		if pf == nil {
 			continue
 		}
+		g.startRPC(m)
 
 		p := g.printf
 
@@ -86,10 +103,7 @@ func (g *generator) genExampleIteratorFile(serv *descriptorpb.ServiceDescriptorP
 		// Pick the first transport for simplicity. We don't need examples
 		// of each method for both transports when they have the same surface.
 		t := g.cfg.transports[0]
-		s := servName
-		if t == rest {
-			s += restClientSuffix
-		}
+		s := servName + clientSuffix(t)
 		p("func Example%sClient_%s_all() {", servName, m.GetName())
 		g.exampleInitClient(pkgName, s)
 
@@ -111,10 +125,12 @@ func (g *generator) genExampleIteratorFile(serv *descriptorpb.ServiceDescriptorP
 func (g *generator) exampleClientFactory(pkgName, servName string) {
 	p := g.printf
 	for _, t := range g.cfg.transports {
-		s := servName
-		if t == rest {
-			s += restClientSuffix
+		// The in-process client needs a server implementation rather than a
+		// context, and is not meant for application code.
+		if t == inprocess {
+			continue
 		}
+		s := servName + clientSuffix(t)
 
 		p("func ExampleNew%sClient() {", s)
 		g.exampleInitClient(pkgName, s)
@@ -144,9 +160,11 @@ func (g *generator) exampleInitClientWithOpts(pkgName, servName string, isPackag
 	p("// - It may require specifying regional endpoints when creating the service client as shown in:")
 	p("//   https://pkg.go.dev/cloud.google.com/go#hdr-Client_Options")
 	clientName := servName
-	// Guard against double-suffixing if the caller already appended "REST".
-	if len(g.cfg.transports) == 1 && g.cfg.transports[0] == rest && !strings.HasSuffix(servName, restClientSuffix) {
-		clientName += restClientSuffix
+	// Guard against double-suffixing if the caller already appended the suffix.
+	if len(g.cfg.transports) == 1 {
+		if suffix := clientSuffix(g.cfg.transports[0]); !strings.HasSuffix(servName, suffix) {
+			clientName += suffix
+		}
 	}
 	p("c, err := %s.New%sClient(ctx)", pkgName, clientName)
 	p("if err != nil {")
@@ -158,6 +176,7 @@ func (g *generator) exampleInitClientWithOpts(pkgName, servName string, isPackag
 }
 
 func (g *generator) exampleMethod(pkgName, servName string, m *descriptorpb.MethodDescriptorProto) error {
+	g.startRPC(m)
 	if m.GetClientStreaming() != m.GetServerStreaming() {
 		// TODO(pongad): implement this correctly.
 		return nil
@@ -211,10 +230,7 @@ func (g *generator) exampleMethodBodyWithOpts(pkgName, servName string, m *descr
 	// Pick the first transport for simplicity. We don't need examples
 	// of each method for both transports when they have the same surface.
 	t := g.cfg.transports[0]
-	s := servName
-	if t == rest {
-		s += restClientSuffix
-	}
+	s := servName + clientSuffix(t)
 	if !isPackageDoc {
 		g.exampleInitClient(pkgName, s)
 	}
@@ -231,7 +247,9 @@ func (g *generator) exampleMethodBodyWithOpts(pkgName, servName string, m *descr
 	if err != nil {
 		return err
 	}
-	if pf != nil {
+	if g.isMediaUpload(m) {
+		g.exampleUploadCall(m)
+	} else if pf != nil {
 		if err := g.examplePagingCall(m); err != nil {
 			return err
 		}
@@ -289,6 +307,27 @@ func (g *generator) exampleUnaryCall(m *descriptorpb.MethodDescriptorProto) {
 	p("_ = resp")
 }
 
+func (g *generator) exampleUploadCall(m *descriptorpb.MethodDescriptorProto) {
+	p := g.printf
+
+	p("// TODO: Provide the media to upload.")
+	p("var media io.Reader")
+	g.imports[pbinfo.ImportSpec{Path: "io"}] = true
+	if m.GetOutputType() == emptyType {
+		p("err = c.%s(ctx, req).Media(media, \"\").Do()", m.GetName())
+		p("if err != nil {")
+		p("  // TODO: Handle error.")
+		p("}")
+		return
+	}
+	p("resp, err := c.%s(ctx, req).Media(media, \"\").Do()", m.GetName())
+	p("if err != nil {")
+	p("  // TODO: Handle error.")
+	p("}")
+	p("// TODO: Use resp.")
+	p("_ = resp")
+}
+
 func (g *generator) exampleEmptyCall(m *descriptorpb.MethodDescriptorProto) {
 	p := g.printf
 
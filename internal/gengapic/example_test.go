@@ -346,11 +346,13 @@ func TestGenSnippetFile(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
-			g.commitWithBuildTag(filepath.Join(sample.SnippetsDirectory, "main.go"), "main", "examples")
+			if _, err := g.commitWithBuildTag(filepath.Join(sample.SnippetsDirectory, "main.go"), "main", "examples"); err != nil {
+				t.Fatal(err)
+			}
 			if diff := cmp.Diff(test.imports, g.imports); diff != "" {
 				t.Errorf("TestExample(%s) imports mismatch: (-want +got):\n%s", test.name, diff)
 			}
-			got := *g.resp.File[0].Content + *g.resp.File[1].Content
+			got := g.resp.File[0].GetContent()
 			txtdiff.Diff(t, got, filepath.Join("testdata", test.name+".want"))
 		})
 	}
@@ -29,12 +29,17 @@ type featureInfo struct {
 
 // Define feature ID strings here.  More details about features are kept in the featureRegistry map.
 const (
+	AutoUpdateMaskFeature            featureID = "auto_update_mask"
 	DynamicResourceHeuristicsFeature featureID = "dynamic_resource_heuristics"
 	ExportSetGoogleClientInfoFeature featureID = "export_set_google_client_info"
+	FlattenedMethodsFeature          featureID = "flattened_methods"
+	HTTPBodyReaderFeature            featureID = "http_body_reader"
 	MediaUploadFeature               featureID = "enable_media_upload"
 	MTLSHardBoundTokensFeature       featureID = "mtls_hard_bound_tokens"
 	OpenTelemetryAttributesFeature   featureID = "open_telemetry_attributes"
 	OrderedRoutingHeadersFeature     featureID = "ordered_routing_headers"
+	RequiredFieldValidationFeature   featureID = "required_field_validation"
+	ResourceNamesFeature             featureID = "resource_names"
 	SelectiveGapicGenerationFeature  featureID = "selective_gapic_generation"
 	WrapperTypesForPageSizeFeature   featureID = "wrapper_types_for_page_size"
 )
@@ -45,6 +50,9 @@ const (
 // who attempt to do so will be given a stern talking to.
 var featureRegistry = map[featureID]*featureInfo{
 
+	AutoUpdateMaskFeature: {
+		Description: "Compute the update_mask of the requests of Update RPCs not setting one from the populated fields of their resource, other than its output only and immutable fields.",
+	},
 	DynamicResourceHeuristicsFeature: {
 		Description: "Enable dynamic resource name heuristics for unannotated legacy services.",
 		TrackingID:  "b/476980139",
@@ -53,6 +61,12 @@ var featureRegistry = map[featureID]*featureInfo{
 		Description: "Generated exported SetGoogleClientInfo function in client",
 		TrackingID:  "b/489495186",
 	},
+	FlattenedMethodsFeature: {
+		Description: "Generate flattened variants of RPCs from their google.api.method_signature annotations.",
+	},
+	HTTPBodyReaderFeature: {
+		Description: "Generate streaming download variants of RPCs responding with google.api.HttpBody.",
+	},
 	MediaUploadFeature: {
 		Description: "support media upload as part of RPC definitions",
 		TrackingID:  "b/484135845",
@@ -68,6 +82,12 @@ var featureRegistry = map[featureID]*featureInfo{
 	OrderedRoutingHeadersFeature: {
 		Description: "Specify that routing headers are emitted in a deterministic fashion.  Primarily used for firestore.",
 	},
+	RequiredFieldValidationFeature: {
+		Description: "Validate the REQUIRED fields and path parameters of requests on the client, before sending them.",
+	},
+	ResourceNamesFeature: {
+		Description: "Generate typed resource names from google.api.resource annotations.",
+	},
 	SelectiveGapicGenerationFeature: {
 		Description: "Enable selective GAPIC generation, reducing public surface area based on config.",
 	},
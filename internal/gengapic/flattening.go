@@ -0,0 +1,380 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// flattening is a flattened variant of a method, taking the fields of one of
+// its google.api.method_signature annotations as parameters rather than the
+// request, e.g. GetTopicByName for the signature "name" of GetTopic.
+type flattening struct {
+	name string
+	// fields are the field paths of the signature, e.g. topic.name.
+	fields []string
+	params []flattenedParam
+	// req are the fields of the request set from the parameters.
+	req []*requestField
+}
+
+type flattenedParam struct {
+	name, typ string
+}
+
+// flattenings returns the flattened variants of the methods of serv, if
+// enabled. Variants are named after their method and the fields of their
+// signature: GetTopicByName for the signature "name", or
+// CreateTopicFlattened for "parent,topic", and the signatures of which the
+// name of the variant is taken by another method are skipped.
+func (g *generator) flattenings(serv *descriptorpb.ServiceDescriptorProto) map[*descriptorpb.MethodDescriptorProto][]*flattening {
+	if !g.featureEnabled(FlattenedMethodsFeature) {
+		return nil
+	}
+
+	taken := g.clientMembers(serv)
+	methods := g.getMethods(serv)
+	for _, m := range methods {
+		if g.hasHTTPBodyReader(serv, m) {
+			taken[g.httpBodyReaderName(m)] = true
+		}
+	}
+
+	fl := map[*descriptorpb.MethodDescriptorProto][]*flattening{}
+	for _, m := range methods {
+		// The variants call the exported method with a request.
+		if g.isMethodInternal(m) || m.GetClientStreaming() || g.isMediaUpload(m) {
+			continue
+		}
+		seen := map[string]bool{}
+		sigs, _ := proto.GetExtension(m.GetOptions(), annotations.E_MethodSignature).([]string)
+		for _, sig := range sigs {
+			var fields []string
+			for _, f := range strings.Split(sig, ",") {
+				if f = strings.TrimSpace(f); f != "" {
+					fields = append(fields, f)
+				}
+			}
+			key := strings.Join(fields, ",")
+			if len(fields) == 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			f, err := g.flattening(m, fields)
+			if err != nil {
+				g.diagnose(skippedFlattenedMethod, g.fqn(m), "method_signature %q is skipped: %v", sig, err)
+				continue
+			}
+			var byFields []string
+			for _, p := range fields {
+				byFields = append(byFields, snakeToCamel(strings.ReplaceAll(p, ".", "_")))
+			}
+			f.name = m.GetName() + "By" + strings.Join(byFields, "And")
+			if len(fields) > 1 && !taken[m.GetName()+"Flattened"] {
+				f.name = m.GetName() + "Flattened"
+			}
+			if taken[f.name] {
+				g.diagnose(skippedFlattenedMethod, g.fqn(m), "method_signature %q is skipped: %s is already a method of the client", sig, f.name)
+				continue
+			}
+			taken[f.name] = true
+			fl[m] = append(fl[m], f)
+		}
+	}
+	return fl
+}
+
+// clientMembers returns the names of the methods and fields of the client of
+// serv, and of the types generated for its LRO methods, before any variant of
+// its RPCs is added.
+func (g *generator) clientMembers(serv *descriptorpb.ServiceDescriptorProto) map[string]bool {
+	names := map[string]bool{"CallOptions": true, "Close": true, "Connection": true, "SetGoogleClientInfo": true}
+	for _, m := range g.getMethods(serv) {
+		names[g.methodName(m)] = true
+		if g.isLRO(m) {
+			names[lroTypeName(m)] = true
+		}
+	}
+	return names
+}
+
+// flattening returns the flattened variant of m taking the given fields of
+// its request.
+func (g *generator) flattening(m *descriptorpb.MethodDescriptorProto, fields []string) (*flattening, error) {
+	inType, ok := g.descInfo.Type[m.GetInputType()].(*descriptorpb.DescriptorProto)
+	if !ok {
+		return nil, fmt.Errorf("unknown request type %s", m.GetInputType())
+	}
+
+	f := &flattening{fields: fields}
+	params := map[string]bool{"c": true, "ctx": true, "opts": true, "req": true}
+	for _, path := range fields {
+		param := lowerFirst(snakeToCamel(strings.ReplaceAll(path, ".", "_")))
+		if token.IsKeyword(param) || params[param] {
+			param += "Arg"
+		}
+		if params[param] {
+			return nil, fmt.Errorf("fields map to the same parameter %s", param)
+		}
+		params[param] = true
+
+		typ, err := g.setFlattenedField(&f.req, inType, strings.Split(path, "."), param)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", path, err)
+		}
+		f.params = append(f.params, flattenedParam{name: param, typ: typ})
+	}
+	return f, nil
+}
+
+// setFlattenedField adds the field of the given path in msg to fields, set to
+// the parameter param, and returns the Go type of the parameter.
+func (g *generator) setFlattenedField(fields *[]*requestField, msg *descriptorpb.DescriptorProto, path []string, param string) (string, error) {
+	var f *descriptorpb.FieldDescriptorProto
+	for _, mf := range msg.GetField() {
+		if mf.GetName() == path[0] {
+			f = mf
+			break
+		}
+	}
+	if f == nil {
+		return "", fmt.Errorf("no field %s in %s", path[0], msg.GetName())
+	}
+	if f.OneofIndex != nil && !f.GetProto3Optional() {
+		return "", fmt.Errorf("oneof fields are not supported")
+	}
+	name := snakeToCamel(f.GetName())
+	var parent *requestField
+	for _, mf := range *fields {
+		if mf.name == name {
+			parent = mf
+			break
+		}
+	}
+
+	if len(path) == 1 {
+		if parent != nil {
+			return "", fmt.Errorf("%s is set by another field of the signature", path[0])
+		}
+		typ, err := g.goFieldType(f)
+		if err != nil {
+			return "", err
+		}
+		value := param
+		if g.hasPointerScalar(f) {
+			value = "&" + param
+		}
+		*fields = append(*fields, &requestField{name: name, value: value})
+		return typ, nil
+	}
+
+	if f.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE || f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return "", fmt.Errorf("%s is not a singular message field", path[0])
+	}
+	sub, ok := g.descInfo.Type[f.GetTypeName()].(*descriptorpb.DescriptorProto)
+	if !ok {
+		return "", fmt.Errorf("unknown message type %s", f.GetTypeName())
+	}
+	if parent == nil {
+		subSpec, err := g.descInfo.ImportSpec(sub)
+		if err != nil {
+			return "", err
+		}
+		g.imports[subSpec] = true
+		parent = &requestField{name: name, typ: fmt.Sprintf("%s.%s", subSpec.Name, g.nestedName(sub))}
+		*fields = append(*fields, parent)
+	} else if parent.typ == "" {
+		return "", fmt.Errorf("%s is set by another field of the signature", path[0])
+	}
+	return g.setFlattenedField(&parent.fields, sub, path[1:], param)
+}
+
+// goFieldType returns the Go type of field f.
+func (g *generator) goFieldType(f *descriptorpb.FieldDescriptorProto) (string, error) {
+	if entry, ok := g.descInfo.Type[f.GetTypeName()].(*descriptorpb.DescriptorProto); ok && entry.GetOptions().GetMapEntry() {
+		var key, val string
+		for _, ef := range entry.GetField() {
+			t, err := g.goElemType(ef)
+			if err != nil {
+				return "", err
+			}
+			if ef.GetName() == "key" {
+				key = t
+			} else {
+				val = t
+			}
+		}
+		return fmt.Sprintf("map[%s]%s", key, val), nil
+	}
+	t, err := g.goElemType(f)
+	if err != nil {
+		return "", err
+	}
+	if f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		t = "[]" + t
+	}
+	return t, nil
+}
+
+// goElemType returns the Go type of a single value of field f.
+func (g *generator) goElemType(f *descriptorpb.FieldDescriptorProto) (string, error) {
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP, descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		typ := g.descInfo.Type[f.GetTypeName()]
+		if typ == nil {
+			return "", fmt.Errorf("unknown type %s", f.GetTypeName())
+		}
+		spec, err := g.descInfo.ImportSpec(typ)
+		if err != nil {
+			return "", err
+		}
+		g.imports[spec] = true
+		name := fmt.Sprintf("%s.%s", spec.Name, g.nestedName(typ))
+		if f.GetType() == descriptorpb.FieldDescriptorProto_TYPE_ENUM {
+			return name, nil
+		}
+		return "*" + name, nil
+	}
+	return pbinfo.GoTypeForPrim[f.GetType()], nil
+}
+
+// hasPointerScalar reports whether field f is a scalar generated as a pointer
+// in Go, as the fields with explicit presence are, except bytes fields.
+func (g *generator) hasPointerScalar(f *descriptorpb.FieldDescriptorProto) bool {
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP, descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return false
+	}
+	return g.descInfo.HasExplicitPresence(f)
+}
+
+// flattenedWrapperMethods generates the flattened variants of m on the client.
+func (g *generator) flattenedWrapperMethods(m *descriptorpb.MethodDescriptorProto, serv *descriptorpb.ServiceDescriptorProto, servName string, fl []*flattening) error {
+	if len(fl) == 0 {
+		return nil
+	}
+	results, err := g.flattenedResults(m, serv)
+	if err != nil {
+		return err
+	}
+	inType := g.descInfo.Type[m.GetInputType()]
+	inSpec, err := g.descInfo.ImportSpec(inType)
+	if err != nil {
+		return err
+	}
+	g.imports[inSpec] = true
+
+	p := g.printf
+	for _, f := range fl {
+		var params []string
+		for _, fp := range f.params {
+			params = append(params, fp.name+" "+fp.typ)
+		}
+		values := "value"
+		if len(f.fields) > 1 {
+			values = "values"
+		}
+		p("// %s calls %s with a request setting %s to the given %s.", f.name, g.methodName(m), joinWords(f.fields), values)
+		if m.GetOptions().GetDeprecated() {
+			p("//")
+			p("// Deprecated: %s may be removed in a future version.", f.name)
+		}
+		p("func (c *%sClient) %s(ctx context.Context, %s, opts ...gax.CallOption) %s {", servName, f.name, strings.Join(params, ", "), results)
+		p("  req := &%s.%s{", inSpec.Name, inType.GetName())
+		g.requestFields(f.req)
+		p("  }")
+		p("  return c.%s(ctx, req, opts...)", g.methodName(m))
+		p("}")
+		p("")
+	}
+	return nil
+}
+
+// flattenedIntfMethods generates the flattened variants of m in the body of
+// the client interface.
+func (g *generator) flattenedIntfMethods(m *descriptorpb.MethodDescriptorProto, serv *descriptorpb.ServiceDescriptorProto, fl []*flattening) error {
+	if len(fl) == 0 {
+		return nil
+	}
+	results, err := g.flattenedResults(m, serv)
+	if err != nil {
+		return err
+	}
+	for _, f := range fl {
+		var types []string
+		for _, fp := range f.params {
+			types = append(types, fp.typ)
+		}
+		g.printf("%s(context.Context, %s, ...gax.CallOption) %s", f.name, strings.Join(types, ", "), results)
+	}
+	return nil
+}
+
+// flattenedResults returns the results of the client method of m, which its
+// flattened variants share.
+func (g *generator) flattenedResults(m *descriptorpb.MethodDescriptorProto, serv *descriptorpb.ServiceDescriptorProto) (string, error) {
+	if m.GetOutputType() == emptyType {
+		return "error", nil
+	}
+	if g.isLRO(m) {
+		return fmt.Sprintf("(*%s, error)", lroTypeName(m)), nil
+	}
+	if pf, _, err := g.getPagingFields(m); err != nil {
+		return "", err
+	} else if pf != nil {
+		iter, err := g.iterTypeOf(pf)
+		if err != nil {
+			return "", err
+		}
+		return "*" + iter.iterTypeName, nil
+	}
+	if m.GetServerStreaming() {
+		servSpec, err := g.descInfo.ImportSpec(serv)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s.%s_%sClient, error)", servSpec.Name, serv.GetName(), m.GetName()), nil
+	}
+	retTyp, err := g.returnType(m)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("(%s, error)", retTyp), nil
+}
+
+// flattenedNames returns the names of the flattened variants in fl.
+func flattenedNames(fl []*flattening) []string {
+	var names []string
+	for _, f := range fl {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// joinWords joins the given words as in prose, e.g. "a, b and c".
+func joinWords(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFlattenedMethods(t *testing.T) {
+	field := func(name string, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Type:     typ.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	str := func(name string) *descriptorpb.FieldDescriptorProto {
+		return field(name, descriptorpb.FieldDescriptorProto_TYPE_STRING, "")
+	}
+	repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return f
+	}
+	inOneof := func(f *descriptorpb.FieldDescriptorProto, i int32, synthetic bool) *descriptorpb.FieldDescriptorProto {
+		f.OneofIndex = proto.Int32(i)
+		f.Proto3Optional = proto.Bool(synthetic)
+		return f
+	}
+
+	thing := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Thing"),
+		Field: []*descriptorpb.FieldDescriptorProto{str("name"), str("display_name")},
+	}
+	state := &descriptorpb.EnumDescriptorProto{Name: proto.String("State")}
+	getReq := &descriptorpb.DescriptorProto{
+		Name:  proto.String("GetThingRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{str("name"), str("etag")},
+	}
+	labelsEntry := &descriptorpb.DescriptorProto{
+		Name:    proto.String("LabelsEntry"),
+		Field:   []*descriptorpb.FieldDescriptorProto{str("key"), str("value")},
+		Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+	}
+	createReq := &descriptorpb.DescriptorProto{
+		Name: proto.String("CreateThingRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			str("parent"),
+			field("thing", descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".my.pkg.Thing"),
+			inOneof(str("request_id"), 0, true),
+			repeated(field("labels", descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".my.pkg.CreateThingRequest.LabelsEntry")),
+			repeated(str("tags")),
+			field("state", descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".my.pkg.State"),
+			str("type"),
+			inOneof(str("choice"), 1, false),
+		},
+		NestedType: []*descriptorpb.DescriptorProto{labelsEntry},
+		OneofDecl:  []*descriptorpb.OneofDescriptorProto{{Name: proto.String("_request_id")}, {Name: proto.String("kind")}},
+	}
+
+	method := func(name, in, out string, sigs ...string) *descriptorpb.MethodDescriptorProto {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_MethodSignature, sigs)
+		return &descriptorpb.MethodDescriptorProto{
+			Name:       proto.String(name),
+			InputType:  proto.String(in),
+			OutputType: proto.String(out),
+			Options:    opts,
+		}
+	}
+	getThing := method("GetThing", ".my.pkg.GetThingRequest", ".my.pkg.Thing", "name", "name,etag", "name, etag")
+	getThingByName := method("GetThingByName", ".my.pkg.GetThingRequest", ".my.pkg.Thing")
+	createThing := method("CreateThing", ".my.pkg.CreateThingRequest", ".my.pkg.Thing",
+		"parent,thing",
+		"parent,thing.name,thing.display_name",
+		"request_id,labels,tags,state,type",
+		"choice",
+		"missing",
+		"thing,thing.name",
+		"")
+	deleteThing := method("DeleteThing", ".my.pkg.GetThingRequest", emptyType, "name")
+	deleteThing.Options.Deprecated = proto.Bool(true)
+	updateThing := method("UpdateThing", ".my.pkg.GetThingRequest", operationType, "name")
+	serv := &descriptorpb.ServiceDescriptorProto{
+		Name:   proto.String("ThingService"),
+		Method: []*descriptorpb.MethodDescriptorProto{getThing, getThingByName, createThing, deleteThing, updateThing},
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Package:     proto.String("my.pkg"),
+		Syntax:      proto.String("proto3"),
+		Options:     &descriptorpb.FileOptions{GoPackage: proto.String("mypackage")},
+		MessageType: []*descriptorpb.DescriptorProto{thing, getReq, createReq},
+		EnumType:    []*descriptorpb.EnumDescriptorProto{state},
+		Service:     []*descriptorpb.ServiceDescriptorProto{serv},
+	}
+
+	g := &generator{
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg: &generatorConfig{
+			transports:        []transport{grpc},
+			featureEnablement: map[featureID]struct{}{FlattenedMethodsFeature: {}},
+		},
+		descInfo: pbinfo.Info{
+			Type: map[string]pbinfo.ProtoType{
+				".my.pkg.Thing":                          thing,
+				".my.pkg.State":                          state,
+				".my.pkg.GetThingRequest":                getReq,
+				".my.pkg.CreateThingRequest":             createReq,
+				".my.pkg.CreateThingRequest.LabelsEntry": labelsEntry,
+			},
+			ParentFile: map[protoreflect.ProtoMessage]*descriptorpb.FileDescriptorProto{
+				thing:       file,
+				state:       file,
+				getReq:      file,
+				createReq:   file,
+				labelsEntry: file,
+				serv:        file,
+			},
+			ParentElement: map[pbinfo.ProtoType]pbinfo.ProtoType{
+				labelsEntry: createReq,
+			},
+		},
+	}
+	for _, m := range serv.GetMethod() {
+		g.descInfo.ParentFile[m] = file
+		g.descInfo.ParentElement[m] = serv
+	}
+
+	fl := g.flattenings(serv)
+	for _, m := range serv.GetMethod() {
+		if err := g.flattenedWrapperMethods(m, serv, "Thing", fl[m]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.printf("type ThingClientInterface interface {")
+	for _, m := range serv.GetMethod() {
+		if err := g.flattenedIntfMethods(m, serv, fl[m]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.printf("}")
+	txtdiff.Diff(t, g.pt.String(), filepath.Join("testdata", "flattened_methods.want"))
+
+	wantImports := map[pbinfo.ImportSpec]bool{
+		{Name: "mypackagepb", Path: "mypackage"}: true,
+	}
+	if diff := cmp.Diff(g.imports, wantImports); diff != "" {
+		t.Errorf("imports got(-),want(+):\n%s", diff)
+	}
+
+	var got []string
+	for _, d := range g.diagnostics {
+		got = append(got, d.Element+": "+d.Reason)
+	}
+	want := []string{
+		`my.pkg.ThingService.GetThing: method_signature "name" is skipped: GetThingByName is already a method of the client`,
+		`my.pkg.ThingService.CreateThing: method_signature "choice" is skipped: field choice: oneof fields are not supported`,
+		`my.pkg.ThingService.CreateThing: method_signature "missing" is skipped: field missing: no field missing in CreateThingRequest`,
+		`my.pkg.ThingService.CreateThing: method_signature "thing,thing.name" is skipped: field thing.name: thing is set by another field of the signature`,
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("diagnostics got(-),want(+):\n%s", diff)
+	}
+
+	delete(g.cfg.featureEnablement, FlattenedMethodsFeature)
+	if fl := g.flattenings(serv); len(fl) != 0 {
+		t.Errorf("flattenings() = %v with the feature disabled, want none", fl)
+	}
+}
+
+func TestFlattenedMethodsGen(t *testing.T) {
+	req := thingsRequest("go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,metadata,client-interface,F_flattened_methods")
+	for _, s := range req.GetProtoFile()[0].GetService() {
+		proto.SetExtension(s.GetMethod()[0].GetOptions(), annotations.E_MethodSignature, []string{"parent"})
+	}
+	resp, err := gen(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"foo_client.go": {
+			"func (c *FooClient) ListThingsByParent(ctx context.Context, parent string, opts ...gax.CallOption) *ThingIterator {",
+			"ListThingsByParent(context.Context, string, ...gax.CallOption) *ThingIterator",
+		},
+		"gapic_metadata.json": {`"ListThings",`, `"ListThingsByParent"`},
+	}
+	for _, f := range resp.GetFile() {
+		for _, w := range want[filepath.Base(f.GetName())] {
+			if !strings.Contains(f.GetContent(), w) {
+				t.Errorf("%s does not contain %q", f.GetName(), w)
+			}
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// connectConnSource is the implementation of the connection pool of Connect
+// clients. Everything following its import block is emitted into the
+// auxiliary.go of packages generated with the Connect transport.
+//
+//go:embed connectconn/connectconn.go
+var connectConnSource string
+
+func lowcaseConnectClientName(servName string) string {
+	if servName == "" {
+		return "connectClient"
+	}
+
+	return lowerFirst(servName + "ConnectClient")
+}
+
+// genConnectMethods generates the methods of the Connect client. The Connect
+// client calls the service through the gRPC stub, like the gRPC client, so
+// its methods are generated the same way.
+func (g *generator) genConnectMethods(serv *descriptorpb.ServiceDescriptorProto, servName string) error {
+	return g.genStubMethods(serv, servName, connect)
+}
+
+func (g *generator) connectClientOptions(serv *descriptorpb.ServiceDescriptorProto, servName string) {
+	if !proto.HasExtension(serv.GetOptions(), annotations.E_DefaultHost) {
+		// Not an error, just doesn't apply to us.
+		return
+	}
+
+	p := g.printf
+
+	eHost := proto.GetExtension(serv.GetOptions(), annotations.E_DefaultHost)
+
+	// Both protocols are carried over HTTP, secure by default.
+	host := fmt.Sprintf("https://%s", eHost.(string))
+
+	p("func default%sConnectClientOptions() []option.ClientOption {", servName)
+	p("  return []option.ClientOption{")
+	p("    internaloption.WithDefaultEndpoint(%q),", host)
+	p("    internaloption.WithDefaultEndpointTemplate(%q),", generateDefaultEndpointTemplate(host))
+	p("    internaloption.WithDefaultMTLSEndpoint(%q),", generateDefaultMTLSEndpoint(host))
+	p("    internaloption.WithDefaultUniverseDomain(%q),", googleDefaultUniverse)
+	p("    internaloption.WithDefaultAudience(%q),", generateDefaultAudience(host))
+	p("    internaloption.WithDefaultScopes(DefaultAuthScopes()...),")
+	p("    internaloption.EnableNewAuthLibrary(),")
+	p("  }")
+	p("}")
+	p("")
+
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/option"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/option/internaloption"}] = true
+}
+
+// connectCallOptions generates the default call options of the Connect client,
+// which retry settings are those of the gRPC client.
+func (g *generator) connectCallOptions(serv *descriptorpb.ServiceDescriptorProto, servName string) {
+	g.stubCallOptions(serv, servName, fmt.Sprintf("default%sConnectCallOptions", servName))
+}
+
+// connPoolClient holds the parts of a client calling the gRPC stub over a
+// connection pool other than gRPC that differ between the transports, which
+// are the Connect and in-process ones.
+type connPoolClient struct {
+	// name is the name of the unexported client type.
+	name string
+	// over ends the doc of the client type, e.g. "over the Connect protocol".
+	over string
+	// connPoolDoc documents the connPool field of the client.
+	connPoolDoc string
+	// kind names the client in the deprecation of its Connection method.
+	kind string
+	// closeDoc documents the Close method of the client.
+	closeDoc []string
+	// logger is the expression of the logger of the client.
+	logger string
+	// hasCtx reports whether ctx and err are in scope of the constructor.
+	hasCtx bool
+}
+
+// connPoolClientType generates the type of client c of serv.
+func (g *generator) connPoolClientType(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, imp pbinfo.ImportSpec, hasRPCForLRO bool, c connPoolClient) {
+	p := g.printf
+
+	p("// %s is a client for interacting with %s %s.", c.name, g.apiName, c.over)
+	p("//")
+	p("// Methods, except Close, may be called concurrently. However, fields must not be modified concurrently with method calls.")
+	p("type %s struct {", c.name)
+	p("// %s", c.connPoolDoc)
+	p("connPool gtransport.ConnPool")
+	p("")
+
+	p("// Points back to the CallOptions field of the containing %sClient", clientName)
+	p("CallOptions **%sCallOptions", optsName)
+	p("")
+
+	p("// The gRPC API client.")
+	p("%s %s.%sClient", grpcClientField(clientName), imp.Name, serv.GetName())
+	p("")
+
+	if hasRPCForLRO {
+		p("// LROClient is used internally to handle long-running operations.")
+		p("// It is exposed so that its CallOptions can be modified if required.")
+		p("// Users should not Close this client.")
+		p("LROClient **lroauto.OperationsClient")
+		p("")
+		g.imports[pbinfo.ImportSpec{Name: "lroauto", Path: "cloud.google.com/go/longrunning/autogen"}] = true
+	}
+
+	g.mixinStubs()
+
+	p("// The x-goog-* metadata to be sent with each request.")
+	p("xGoogHeaders []string")
+	p("")
+	p("logger *slog.Logger")
+	p("}")
+	p("")
+
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "log/slog"}] = true
+	g.imports[imp] = true
+}
+
+// connPoolClientNew generates the end of a constructor of client c of serv,
+// creating c over the connPool and client in scope and returning client.
+func (g *generator) connPoolClientNew(serv *descriptorpb.ServiceDescriptorProto, clientName string, imp pbinfo.ImportSpec, hasRPCForLRO bool, c connPoolClient) {
+	p := g.printf
+
+	p("  c := &%s{", c.name)
+	p("    connPool:    connPool,")
+	p("    %s: %s.New%sClient(connPool),", grpcClientField(clientName), imp.Name, serv.GetName())
+	p("    CallOptions: &client.CallOptions,")
+	p("    logger: %s,", c.logger)
+	g.mixinStubsInit()
+	p("")
+	p("  }")
+	p("  c.setGoogleClientInfo()")
+	p("")
+	p("  client.internalClient = c")
+	p("")
+
+	if hasRPCForLRO {
+		ctx := "ctx"
+		if !c.hasCtx {
+			ctx = "context.Background()"
+			p("  var err error")
+		}
+		p("  client.LROClient, err = lroauto.NewOperationsClient(%s, gtransport.WithConnPool(connPool))", ctx)
+		p("  if err != nil {")
+		p("    // This error \"should not happen\", since we are just reusing old connection pool")
+		p("    // and never actually need to dial.")
+		p("    return nil, err")
+		p("  }")
+		p("  c.LROClient = &client.LROClient")
+		g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+	}
+
+	p("  return &client, nil")
+	p("}")
+	p("")
+
+	g.imports[pbinfo.ImportSpec{Name: "gtransport", Path: "google.golang.org/api/transport/grpc"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/option/internaloption"}] = true
+}
+
+// connPoolClientMethods generates the Connection, setGoogleClientInfo and
+// Close methods of client c of serv.
+func (g *generator) connPoolClientMethods(serv *descriptorpb.ServiceDescriptorProto, c connPoolClient) {
+	p := g.printf
+
+	// Connection method
+	p("// Connection returns a connection to the API service.")
+	p("//")
+	p("// Deprecated: The %s client has no gRPC connection, so this method", c.kind)
+	p("// always returns nil.")
+	p("func (c *%s) Connection() *grpc.ClientConn {", c.name)
+	p("  return c.connPool.Conn()")
+	p("}")
+	p("")
+
+	apiVersion := proto.GetExtension(serv.Options, annotations.E_ApiVersion).(string)
+
+	// setGoogleClientInfo method
+	p("// setGoogleClientInfo sets the name and version of the application in")
+	p("// the `x-goog-api-client` header passed on each request. Intended for")
+	p("// use by Google-written clients.")
+	p("func (c *%s) setGoogleClientInfo(keyval ...string) {", c.name)
+	p(`  kv := append([]string{"gl-go", gax.GoVersion}, keyval...)`)
+	p(`  kv = append(kv, "gapic", getVersionClient(), "gax", gax.Version, "grpc", grpc.Version, "pb", protoVersion)`)
+	p(`  c.xGoogHeaders = []string{`)
+	p(`    "x-goog-api-client", gax.XGoogHeader(kv...),`)
+	if apiVersion != "" {
+		p(`    "x-goog-api-version", %q,`, apiVersion)
+	}
+	p("  }")
+	p("}")
+	p("")
+
+	// Close method
+	for _, l := range c.closeDoc {
+		p("// %s", l)
+	}
+	p("func (c *%s) Close() error {", c.name)
+	p("  return c.connPool.Close()")
+	p("}")
+	p("")
+}
+
+// connectClient returns the parts of the Connect client of clientName.
+func connectClient(clientName string) connPoolClient {
+	return connPoolClient{
+		// We DON'T want to export the transport layers.
+		name:        lowcaseConnectClientName(clientName),
+		over:        "over the Connect protocol or gRPC-Web",
+		connPoolDoc: "Connection pool carrying the calls of the gRPC API client over HTTP.",
+		kind:        "Connect",
+		closeDoc: []string{
+			"Close closes the connection to the API service. **Always** call Close() when",
+			"the client is no longer required.",
+		},
+		logger: "internaloption.GetLogger(opts)",
+		hasCtx: true,
+	}
+}
+
+func (g *generator) connectClientInit(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, imp pbinfo.ImportSpec, hasRPCForLRO bool) {
+	g.connPoolClientType(serv, clientName, optsName, imp, hasRPCForLRO, connectClient(clientName))
+	g.connectClientUtilities(serv, clientName, optsName, imp, hasRPCForLRO)
+	g.aux.connectConn = true
+}
+
+func (g *generator) connectClientUtilities(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, imp pbinfo.ImportSpec, hasRPCForLRO bool) {
+	p := g.printf
+
+	docLibName := serv.GetName()
+	if override := g.getServiceNameOverride(serv); override != "" {
+		docLibName = override
+	}
+	docLibName = camelToSnake(docLibName)
+	docLibName = strings.Replace(docLibName, "_", " ", -1)
+	c := connectClient(clientName)
+
+	// Factory functions
+	p("// New%sConnectClient creates a new %s client based on the Connect protocol.", clientName, docLibName)
+	p("// It is meant for services reached through proxies speaking the Connect protocol, but not gRPC.")
+	p("// The returned client must be Closed when it is done being used to clean up its underlying connections.")
+	g.serviceDoc(serv, false) // exclude API version docs
+	p("func New%[1]sConnectClient(ctx context.Context, opts ...option.ClientOption) (*%[1]sClient, error) {", clientName)
+	p("  return new%sConnectClient(ctx, false, opts...)", clientName)
+	p("}")
+	p("")
+
+	p("// New%sGRPCWebClient creates a new %s client based on gRPC-Web.", clientName, docLibName)
+	p("// It is meant for services reached through proxies speaking gRPC-Web, but not gRPC.")
+	p("// The returned client must be Closed when it is done being used to clean up its underlying connections.")
+	g.serviceDoc(serv, false) // exclude API version docs
+	p("func New%[1]sGRPCWebClient(ctx context.Context, opts ...option.ClientOption) (*%[1]sClient, error) {", clientName)
+	p("  return new%sConnectClient(ctx, true, opts...)", clientName)
+	p("}")
+	p("")
+
+	p("func new%[1]sConnectClient(ctx context.Context, grpcWeb bool, opts ...option.ClientOption) (*%[1]sClient, error) {", clientName)
+	p("  clientOpts := append(default%sConnectClientOptions(), opts...)", clientName)
+	p("  httpClient, endpoint, err := httptransport.NewClient(ctx, clientOpts...)")
+	p("  if err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  connPool := newConnectConnPool(httpClient, endpoint, grpcWeb)")
+	p("  client := %[1]sClient{CallOptions: default%[2]sConnectCallOptions()}", clientName, optsName)
+	p("")
+	g.connPoolClientNew(serv, clientName, imp, hasRPCForLRO, c)
+
+	g.imports[pbinfo.ImportSpec{Name: "httptransport", Path: "google.golang.org/api/transport/http"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+
+	g.connPoolClientMethods(serv, c)
+}
+
+// genConnectConn emits the implementation of the connection pool of Connect
+// clients, if any was generated.
+func (g *generator) genConnectConn() error {
+	if !g.aux.connectConn {
+		return nil
+	}
+	return g.genEmbeddedSource(connectConnSource)
+}
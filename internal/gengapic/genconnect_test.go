@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	metadatapb "google.golang.org/genproto/googleapis/gapic/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGenConnectMethods(t *testing.T) {
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("my.pkg"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("mypackage"),
+		},
+	}
+	opts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(opts, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=foos/*}"},
+	})
+	m := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("GetFoo"),
+		InputType:  proto.String(".my.pkg.InputType"),
+		OutputType: proto.String(".my.pkg.OutputType"),
+		Options:    opts,
+	}
+	serv := &descriptorpb.ServiceDescriptorProto{
+		Name:   proto.String("Foo"),
+		Method: []*descriptorpb.MethodDescriptorProto{m},
+	}
+	inputType := &descriptorpb.DescriptorProto{
+		Name: proto.String("InputType"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("name"),
+				JsonName: proto.String("name"),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			},
+		},
+	}
+	outputType := &descriptorpb.DescriptorProto{Name: proto.String("OutputType")}
+
+	g := &generator{
+		metadata: &metadatapb.GapicMetadata{},
+		imports:  map[pbinfo.ImportSpec]bool{},
+		aux:      &auxTypes{},
+		cfg: &generatorConfig{
+			pkgName:    "pkg",
+			transports: []transport{connect},
+		},
+		descInfo: pbinfo.Info{
+			Type: map[string]pbinfo.ProtoType{
+				".my.pkg.InputType":  inputType,
+				".my.pkg.OutputType": outputType,
+			},
+			ParentFile: map[proto.Message]*descriptorpb.FileDescriptorProto{
+				serv:       file,
+				m:          file,
+				inputType:  file,
+				outputType: file,
+			},
+			ParentElement: map[pbinfo.ProtoType]pbinfo.ProtoType{
+				m: serv,
+			},
+		},
+	}
+	g.addMetadataServiceEntry("Foo", "v1")
+
+	if err := g.genConnectMethods(serv, "Foo"); err != nil {
+		t.Fatal(err)
+	}
+	if g.stubTransport != grpc {
+		t.Errorf("stubTransport is still %v after generating the Connect methods", g.stubTransport)
+	}
+	txtdiff.Diff(t, g.pt.String(), filepath.Join("testdata", "connect_GetFoo.want"))
+
+	want := &metadatapb.GapicMetadata{
+		Services: map[string]*metadatapb.GapicMetadata_ServiceForTransport{
+			"Foo": {
+				ApiVersion: "v1",
+				Clients: map[string]*metadatapb.GapicMetadata_ServiceAsClient{
+					"connect": {
+						LibraryClient: "FooClient",
+						Rpcs: map[string]*metadatapb.GapicMetadata_MethodList{
+							"GetFoo": {Methods: []string{"GetFoo"}},
+						},
+					},
+				},
+			},
+		},
+	}
+	if diff := cmp.Diff(g.metadata, want, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("gapic_metadata got(-),want(+):\n%s", diff)
+	}
+}
+
+func TestGenConnectConn(t *testing.T) {
+	g := &generator{
+		aux:     &auxTypes{},
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg:     &generatorConfig{},
+	}
+	if err := g.genConnectConn(); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.pt.String(); got != "" {
+		t.Errorf("connection pool emitted although unused:\n%s", got)
+	}
+
+	g.aux.connectConn = true
+	if err := g.genConnectConn(); err != nil {
+		t.Fatal(err)
+	}
+	src := "package foo\n\n" + g.pt.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "auxiliary.go", src, parser.AllErrors); err != nil {
+		t.Errorf("generated connection pool does not parse: %v", err)
+	}
+	for _, imp := range []pbinfo.ImportSpec{
+		{Path: "google.golang.org/grpc"},
+		{Path: "google.golang.org/grpc/status"},
+		{Name: "spb", Path: "google.golang.org/genproto/googleapis/rpc/status"},
+	} {
+		if !g.imports[imp] {
+			t.Errorf("missing import %v", imp)
+		}
+	}
+}
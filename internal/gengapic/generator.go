@@ -15,7 +15,14 @@
 package gengapic
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
 	"strings"
 	"time"
 
@@ -81,11 +88,43 @@ type generator struct {
 	// context of the host service, which is especially important for mixins.
 	clientProtoPkg string
 
+	// stubTransport is the transport of the client the methods calling the
+	// gRPC stub are currently generated for: grpc, connect or inprocess.
+	stubTransport transport
+
 	// sggConfigs caches the resolved SGG configuration per proto package.
 	sggConfigs map[string]*sggConfig
+
+	// rpcLines records the lines printed to pt at which the code generated
+	// for each RPC starts.
+	rpcLines []rpcLine
+
+	// diagnostics records what was skipped or adjusted while generating.
+	diagnostics []diagnostic
+
+	// surface records the exported declarations of the generated files, for
+	// the manifest of the public API.
+	surface surfaceDecls
+}
+
+// rpcLine is the line at which the code generated for an RPC starts.
+type rpcLine struct {
+	line int
+	rpc  *descriptorpb.MethodDescriptorProto
 }
 
 func newGenerator(req *pluginpb.CodeGeneratorRequest) (*generator, error) {
+	// Build and validate the immutable configuration from the CodeGeneratorRequest plugin args.
+	cfg, err := configFromRequest(req.Parameter)
+	if err != nil {
+		return nil, err
+	}
+	return newGeneratorWithConfig(req, cfg)
+}
+
+// newGeneratorWithConfig returns the generator of req configured by cfg
+// rather than by the parameter of req.
+func newGeneratorWithConfig(req *pluginpb.CodeGeneratorRequest, cfg *generatorConfig) (*generator, error) {
 	g := &generator{
 		metadata: &metadata.GapicMetadata{
 			Schema:   "1.0",
@@ -102,15 +141,10 @@ func newGenerator(req *pluginpb.CodeGeneratorRequest) (*generator, error) {
 			iters:           map[string]*iterType{},
 			methodToWrapper: map[*descriptorpb.MethodDescriptorProto]operationWrapper{},
 			opWrappers:      map[string]operationWrapper{},
+			uploads:         map[string]*uploadType{},
 		},
 	}
 
-	// Build and validate the immutable configuration from the CodeGeneratorRequest plugin args.
-	cfg, err := configFromRequest(req.Parameter)
-	if err != nil {
-		return nil, err
-	}
-
 	// attach config to generator.
 	g.cfg = cfg
 
@@ -132,6 +166,8 @@ func newGenerator(req *pluginpb.CodeGeneratorRequest) (*generator, error) {
 	if len(g.cfg.pkgOverrides) > 0 {
 		g.descInfo.PkgOverrides = g.cfg.pkgOverrides
 	}
+	g.diagnoseMixins()
+	g.diagnoseGRPCServiceConfig()
 
 	for _, f := range files {
 		for _, loc := range f.GetSourceCodeInfo().GetLocation() {
@@ -184,25 +220,22 @@ func (g *generator) printf(s string, a ...interface{}) {
 	g.pt.Printf(s, a...)
 }
 
-// TODO(chrisdsmith): Add generator_test.go with TestCommit
-
-func (g *generator) commit(fileName, pkgName string) int {
+func (g *generator) commit(fileName, pkgName string) (int, error) {
 	return g.commitWithBuildTag(fileName, pkgName, "")
 }
 
-// commit adds header, etc to current pt and returns the line length of the
-// final file output.
-func (g *generator) commitWithBuildTag(fileName, pkgName, buildTag string) int {
+// commit adds header, etc to current pt, formats the file with gofmt and
+// returns the number of lines of the final file output.
+func (g *generator) commitWithBuildTag(fileName, pkgName, buildTag string) (int, error) {
 	var header strings.Builder
-	fmt.Fprintf(&header, license.Apache, time.Now().Year())
+	header.WriteString(g.licenseHeader(g.copyrightYear()))
 	header.WriteString(g.headerComments.String() + "\n")
 	if buildTag != "" {
 		fmt.Fprintf(&header, "//go:build %s\n\n", buildTag)
 	}
 	fmt.Fprintf(&header, "package %s\n\n", pkgName)
 
-	var imps []pbinfo.ImportSpec
-	dupCheck := map[string]bool{}
+	var specs []pbinfo.ImportSpec
 	for imp := range g.imports {
 		// TODO(codyoss): This if can be removed once the public protos
 		// have been migrated to their new package. This should be soon after this
@@ -213,6 +246,14 @@ func (g *generator) commitWithBuildTag(fileName, pkgName, buildTag string) int {
 		if imp.Path == "google.golang.org/genproto/googleapis/iam/v1" {
 			imp.Path = "cloud.google.com/go/iam/apiv1/iampb"
 		}
+		specs = append(specs, imp)
+	}
+	// Sort before removing the duplicate paths, so the same import is kept
+	// regardless of the iteration order of the map.
+	sortImports(specs)
+	var imps []pbinfo.ImportSpec
+	dupCheck := map[string]bool{}
+	for _, imp := range specs {
 		if exists := dupCheck[imp.Path]; !exists {
 			dupCheck[imp.Path] = true
 			imps = append(imps, imp)
@@ -239,11 +280,6 @@ func (g *generator) commitWithBuildTag(fileName, pkgName, buildTag string) int {
 		writeImp(imp)
 	}
 	header.WriteString(")\n\n")
-	lineCount := len(strings.Split(header.String(), "\n"))
-	g.resp.File = append(g.resp.File, &pluginpb.CodeGeneratorResponse_File{
-		Name:    &fileName,
-		Content: proto.String(header.String()),
-	})
 
 	// Trim trailing newlines so we have only one.
 	// NOTE(pongad): This might be an overkill since we have gofmt,
@@ -259,15 +295,93 @@ func (g *generator) commitWithBuildTag(fileName, pkgName, buildTag string) int {
 		}
 	}
 
+	content, err := g.formatGo(fileName, header.String(), body)
+	if err != nil {
+		return 0, err
+	}
 	g.resp.File = append(g.resp.File, &pluginpb.CodeGeneratorResponse_File{
-		Content: proto.String(body),
+		Name:    &fileName,
+		Content: proto.String(content),
 	})
 
-	return lineCount + len(strings.Split(body, "\n"))
+	return strings.Count(content, "\n"), nil
+}
+
+// formatGo formats the Go file made of the given header and the body printed
+// by g with gofmt. If the file does not parse, the error names the file, the
+// line and the RPC whose code is at that line, if any.
+func (g *generator) formatGo(fileName, header, body string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fileName, header+body, parser.ParseComments)
+	if err != nil {
+		var errs scanner.ErrorList
+		if !errors.As(err, &errs) || len(errs) == 0 {
+			return "", fmt.Errorf("error parsing generated file %s: %v", fileName, err)
+		}
+		line := errs[0].Pos.Line
+		if rpc := g.rpcAt(line - strings.Count(header, "\n")); rpc != nil {
+			return "", fmt.Errorf("error in generated file %s, line %d, rpc %s: %s", fileName, line, g.fqn(rpc), errs[0].Msg)
+		}
+		return "", fmt.Errorf("error in generated file %s, line %d: %s", fileName, line, errs[0].Msg)
+	}
+	ast.SortImports(fset, f)
+	if g.cfg != nil && g.cfg.apiSurface {
+		g.recordSurface(fileName, f)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return "", fmt.Errorf("error formatting generated file %s: %v", fileName, err)
+	}
+	return buf.String(), nil
+}
+
+// startRPC records that the code printed next is generated for the given RPC,
+// for errors about the generated code to name it.
+func (g *generator) startRPC(m *descriptorpb.MethodDescriptorProto) {
+	g.rpcLines = append(g.rpcLines, rpcLine{line: bytes.Count(g.pt.Bytes(), []byte("\n")) + 1, rpc: m})
+}
+
+// rpcAt returns the RPC whose code is at the given line printed by g, if any.
+func (g *generator) rpcAt(line int) *descriptorpb.MethodDescriptorProto {
+	var rpc *descriptorpb.MethodDescriptorProto
+	for _, rl := range g.rpcLines {
+		if rl.line > line {
+			break
+		}
+		rpc = rl.rpc
+	}
+	return rpc
+}
+
+// copyrightYear returns the year of the copyright notices of the generated
+// files.
+func (g *generator) copyrightYear() int {
+	if g.cfg != nil && g.cfg.copyrightYear != 0 {
+		return g.cfg.copyrightYear
+	}
+	return time.Now().Year()
+}
+
+// licenseHeader returns the header of the generated files, with the
+// configured license and the copyright notice of the given year.
+func (g *generator) licenseHeader(year int) string {
+	if g.cfg != nil && g.cfg.licenseText != "" {
+		return license.Header(g.cfg.licenseText)
+	}
+	id, holder := license.DefaultID, license.DefaultHolder
+	if g.cfg != nil && g.cfg.license != "" {
+		id = g.cfg.license
+	}
+	if g.cfg != nil && g.cfg.copyrightHolder != "" {
+		holder = g.cfg.copyrightHolder
+	}
+	return license.Header(license.Notice(id, holder, year))
 }
 
 func (g *generator) reset() {
 	g.pt.Reset()
+	g.rpcLines = g.rpcLines[:0]
 	g.headerComments.Reset()
 	for k := range g.imports {
 		delete(g.imports, k)
@@ -15,12 +15,17 @@
 package gengapic
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/snippets/metadata"
 	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/genproto/googleapis/api/serviceconfig"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/testing/protocmp"
 	"google.golang.org/protobuf/types/descriptorpb"
@@ -138,3 +143,146 @@ func TestAutoPopulatedFields(t *testing.T) {
 		t.Errorf("got(-),want(+):\n%s", diff)
 	}
 }
+
+func TestCommit(t *testing.T) {
+	m := &descriptorpb.MethodDescriptorProto{Name: proto.String("GetThing")}
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("my.pkg"),
+		Service: []*descriptorpb.ServiceDescriptorProto{
+			{
+				Name:   proto.String("Foo"),
+				Method: []*descriptorpb.MethodDescriptorProto{m},
+			},
+		},
+	}
+	g := &generator{
+		imports:  map[pbinfo.ImportSpec]bool{},
+		descInfo: pbinfo.Of([]*descriptorpb.FileDescriptorProto{file}),
+		cfg:      &generatorConfig{copyrightYear: 2019},
+	}
+
+	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+	g.printf("var  ctx   = context.Background()")
+	g.printf("")
+	g.startRPC(m)
+	g.printf("func getThing( ) {")
+	g.printf("}")
+	lines, err := g.commit("foo.go", "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(g.resp.GetFile()); got != 1 {
+		t.Fatalf("commit() added %d files, want 1", got)
+	}
+	content := g.resp.GetFile()[0].GetContent()
+	if got, want := lines, strings.Count(content, "\n"); got != want {
+		t.Errorf("commit() = %d lines, want %d", got, want)
+	}
+	for _, want := range []string{"Copyright 2019 Google LLC", "package foo\n", "var ctx = context.Background()\n", "func getThing() {\n}\n"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("commit() content does not contain %q:\n%s", want, content)
+		}
+	}
+
+	for _, tst := range []struct {
+		name    string
+		rpc     bool
+		wantErr string
+	}{
+		{name: "rpc", rpc: true, wantErr: "error in generated file foo.go, line 27, rpc my.pkg.Foo.GetThing: "},
+		{name: "no_rpc", wantErr: "error in generated file foo.go, line 27: "},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			g.reset()
+			g.printf("var ctx = context.Background()")
+			g.printf("")
+			if tst.rpc {
+				g.startRPC(m)
+			}
+			g.printf("func getThing() {")
+			g.printf("  return ctx.")
+			g.printf("}")
+			_, err := g.commit("foo.go", "foo")
+			if err == nil || !strings.HasPrefix(err.Error(), tst.wantErr) {
+				t.Errorf("commit() = %v, want an error starting with %q", err, tst.wantErr)
+			}
+		})
+	}
+}
+
+func TestLicenseHeader(t *testing.T) {
+	licenseFile := filepath.Join(t.TempDir(), "LICENSE")
+	if err := os.WriteFile(licenseFile, []byte("Copyright Acme Corp.\n\nAll rights reserved.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tst := range []struct {
+		name   string
+		param  string
+		header string
+	}{
+		{
+			name:   "default",
+			param:  "",
+			header: "// Copyright 2019 Google LLC\n//\n// Licensed under the Apache License, Version 2.0",
+		},
+		{
+			name:   "holder",
+			param:  ",copyright-holder=Acme Corp.",
+			header: "// Copyright 2019 Acme Corp.\n//\n// Licensed under the Apache License, Version 2.0",
+		},
+		{
+			name:   "spdx",
+			param:  ",license=MIT,copyright-holder=Acme Corp.",
+			header: "// Copyright 2019 Acme Corp.\n// SPDX-License-Identifier: MIT\n\n// Code generated by protoc-gen-go_gapic. DO NOT EDIT.\n\n",
+		},
+		{
+			name:   "none",
+			param:  ",license=none",
+			header: "// Code generated by protoc-gen-go_gapic. DO NOT EDIT.\n\n",
+		},
+		{
+			name:   "file",
+			param:  ",license-file=" + licenseFile,
+			header: "// Copyright Acme Corp.\n//\n// All rights reserved.\n\n// Code generated by protoc-gen-go_gapic. DO NOT EDIT.\n\n",
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			resp, err := gen(thingsRequest("go-gapic-package=github.com/googleapis/mypkg;mypkg,copyright-year=2019" + tst.param))
+			if err != nil {
+				t.Fatal(err)
+			}
+			files := map[string]string{}
+			for _, f := range resp.GetFile() {
+				files[strings.TrimPrefix(f.GetName(), "github.com/googleapis/mypkg/")] = f.GetContent()
+			}
+			for _, name := range []string{"doc.go", "foo_client.go", "helpers.go", "internal/snippets/FooClient/ListThings/main.go"} {
+				if !strings.HasPrefix(files[name], tst.header) {
+					t.Errorf("%s does not start with %q:\n%s", name, tst.header, files[name])
+				}
+			}
+
+			// The snippet of FooClient.ListThings starts after its START
+			// region tag, whatever the length of the license header.
+			var index metadata.Index
+			if err := protojson.Unmarshal([]byte(files["internal/snippets/snippet_metadata.my.pkg.json"]), &index); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(files["internal/snippets/FooClient/ListThings/main.go"], "\n")
+			var found bool
+			for _, snp := range index.GetSnippets() {
+				if snp.GetFile() != "FooClient/ListThings/main.go" {
+					continue
+				}
+				found = true
+				start := snp.GetSegments()[0].GetStart()
+				if got := lines[start-2]; !strings.HasPrefix(got, "// [START ") {
+					t.Errorf("line %d of the snippet is %q, want the START region tag", start-1, got)
+				}
+			}
+			if !found {
+				t.Error("no snippet metadata for FooClient/ListThings/main.go")
+			}
+		})
+	}
+}
@@ -15,13 +15,14 @@
 package gengapic
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
 	"github.com/googleapis/gapic-generator-go/internal/printer"
@@ -51,20 +52,54 @@ const (
 
 var headerParamRegexp = regexp.MustCompile(`{([_.a-z0-9]+)`)
 
-// Gen is the entry point for GAPIC generation via the protoc pluginpb.
-func Gen(genReq *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
-	genResp, err := gen(genReq)
+// The Protobuf Editions supported by the generator. Edition 2024 makes the
+// opaque API the default of protoc-gen-go, which has no exported fields for
+// the generated code to set.
+const (
+	minimumEdition = descriptorpb.Edition_EDITION_2023
+	maximumEdition = descriptorpb.Edition_EDITION_2023
+)
+
+// NewResponse returns the response of the protoc plugin with the given
+// generated files, or the given error, and the features supported by the
+// generator.
+func NewResponse(files []*pluginpb.CodeGeneratorResponse_File, err error) *pluginpb.CodeGeneratorResponse {
+	genResp := &pluginpb.CodeGeneratorResponse{}
 	if err != nil {
-		genResp = &pluginpb.CodeGeneratorResponse{
-			Error: proto.String(err.Error()),
-		}
+		genResp.Error = proto.String(err.Error())
+	} else {
+		genResp.File = files
 	}
-	genResp.SupportedFeatures = proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL))
+	genResp.SupportedFeatures = proto.Uint64(uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL | pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS))
+	genResp.MinimumEdition = proto.Int32(int32(minimumEdition))
+	genResp.MaximumEdition = proto.Int32(int32(maximumEdition))
 	return genResp
 }
 
+// Generate generates the files of the given request, configured by cfg
+// rather than by the parameter of the request, or stops early with the error
+// of ctx once it is done.
+func Generate(ctx context.Context, genReq *pluginpb.CodeGeneratorRequest, cfg *Config) ([]*pluginpb.CodeGeneratorResponse_File, error) {
+	resp, err := genConfig(ctx, genReq, cfg.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetFile(), nil
+}
+
 func gen(genReq *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
-	g, err := newGenerator(genReq)
+	cfg, err := configFromRequest(genReq.Parameter)
+	if err != nil {
+		return nil, err
+	}
+	return genConfig(context.Background(), genReq, cfg)
+}
+
+func genConfig(ctx context.Context, genReq *pluginpb.CodeGeneratorRequest, cfg *generatorConfig) (*pluginpb.CodeGeneratorResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	g, err := newGeneratorWithConfig(genReq, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -105,67 +140,22 @@ func gen(genReq *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse
 		return &g.resp, fmt.Errorf("error generating helper file: %v", err)
 	}
 
-	for _, s := range genServs {
-		if len(g.getMethods(s)) == 0 {
-			continue
-		}
-		g.clientProtoPkg = g.descInfo.ParentFile[s].GetPackage()
-		// TODO(pongad): gapic-generator does not remove the package name here,
-		// so even though the client for LoggingServiceV2 is just "Client"
-		// the file name is "logging_client.go".
-		// Keep the current behavior for now, but we could revisit this later.
-		override := g.getServiceNameOverride(s)
-		servName := pbinfo.ReduceServNameWithOverride(s.GetName(), "", override)
-		outFile := camelToSnake(servName)
-		outFile = filepath.Join(g.cfg.outDir, outFile)
-
-		if err := g.genAndCommitSnippets(s); err != nil {
-			return &g.resp, fmt.Errorf("error generating snippets for %s: %v ", s.GetName(), err)
-		}
-
-		g.reset()
-		// If the service has no REST-able RPCs, then a REGAPIC should not be
-		// generated for it, even if REST is an enabled transport.
-		transports := g.cfg.transports
-		hasREST := g.hasRESTMethod(s)
-		if !hasREST {
-			g.cfg.transports = []transport{grpc}
-		}
-		if err := g.gen(s); err != nil {
-			return nil, err
-		}
-		g.commit(outFile+"_client.go", g.cfg.pkgName)
-
-		if !g.isInternalService(s) {
-			g.reset()
-			if err := g.genExampleFile(s); err != nil {
-				return &g.resp, fmt.Errorf("error generating example for %q; %v", s.GetName(), err)
-			}
-			g.imports[pbinfo.ImportSpec{Name: g.cfg.pkgName, Path: g.cfg.pkgPath}] = true
-			g.commit(outFile+"_client_example_test.go", g.cfg.pkgName+"_test")
-
-			g.reset()
-			if err := g.genExampleIteratorFile(s); err != nil {
-				return &g.resp, fmt.Errorf("error generating iter example for %q; %v", s.GetName(), err)
-			}
-			g.imports[pbinfo.ImportSpec{Name: g.cfg.pkgName, Path: g.cfg.pkgPath}] = true
-			g.commitWithBuildTag(outFile+"_client_example_go123_test.go", g.cfg.pkgName+"_test", "go1.23")
-		}
-
-		// Replace original set of transports for the next service that may have
-		// REST-able RPCs.
-		if !hasREST {
-			g.cfg.transports = transports
-		}
+	if err := g.genServices(ctx, genServs, protoPkg, runtime.GOMAXPROCS(0)); err != nil {
+		return &g.resp, err
 	}
 	if err := g.genAndCommitSnippetMetadata(protoPkg); err != nil {
 		return nil, err
 	}
 	g.reset()
-	g.genDocFile(time.Now().Year(), genServs)
+	g.genDocFile(g.copyrightYear(), genServs)
+	docFile := filepath.Join(g.cfg.outDir, "doc.go")
+	doc, err := g.formatGo(docFile, "", g.pt.String())
+	if err != nil {
+		return nil, err
+	}
 	g.resp.File = append(g.resp.File, &pluginpb.CodeGeneratorResponse_File{
-		Name:    proto.String(filepath.Join(g.cfg.outDir, "doc.go")),
-		Content: proto.String(g.pt.String()),
+		Name:    proto.String(docFile),
+		Content: proto.String(doc),
 	})
 
 	if g.cfg.generateGAPICMetadata {
@@ -182,7 +172,16 @@ func gen(genReq *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse
 		if err := g.customOperationType(); err != nil {
 			return nil, err
 		}
-		g.commit(filepath.Join(g.cfg.outDir, "operations.go"), g.cfg.pkgName)
+		if _, err := g.commit(filepath.Join(g.cfg.outDir, "operations.go"), g.cfg.pkgName); err != nil {
+			return nil, err
+		}
+	}
+
+	if g.featureEnabled(ResourceNamesFeature) {
+		g.reset()
+		if err := g.genResourceNames(g.filesToGenerate(genReq)); err != nil {
+			return nil, err
+		}
 	}
 
 	g.reset()
@@ -190,6 +189,16 @@ func gen(genReq *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse
 		return nil, err
 	}
 
+	if g.cfg.apiSurface {
+		if err := g.genAPISurfaceFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := g.reportDiagnostics(); err != nil {
+		return nil, err
+	}
+
 	return &g.resp, nil
 }
 
@@ -229,6 +238,18 @@ func (g *generator) collectServicesAndScopes(genReq *pluginpb.CodeGeneratorReque
 	return
 }
 
+// filesToGenerate returns the proto files of the request to generate the
+// package of, excluding the mixin files which are not generated.
+func (g *generator) filesToGenerate(genReq *pluginpb.CodeGeneratorRequest) []*descriptorpb.FileDescriptorProto {
+	var files []*descriptorpb.FileDescriptorProto
+	for _, f := range genReq.GetProtoFile() {
+		if strContains(genReq.GetFileToGenerate(), f.GetName()) && g.includeMixinInputFile(f.GetName()) {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
 // getAndCommitHelpers commits shared generated code that should be defined only once.
 // Currently, this includes functionality for reporting default scopes, version information,
 // and client constructors hooks.
@@ -319,7 +340,7 @@ func (g *generator) genAndCommitHelpers(scopes []string) error {
 		p("")
 	}
 
-	if containsTransport(g.cfg.transports, grpc) {
+	if usesGRPCStub(g.cfg.transports) {
 		g.imports[pbinfo.ImportSpec{Path: "log/slog"}] = true
 		g.imports[pbinfo.ImportSpec{Path: "github.com/googleapis/gax-go/v2/internallog/grpclog"}] = true
 		g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc"}] = true
@@ -332,15 +353,18 @@ func (g *generator) genAndCommitHelpers(scopes []string) error {
 		p("  if err != nil {")
 		p("    return zero, err")
 		p("  }")
+		g.throttleSuccess("opts")
 		p(`  logger.DebugContext(ctx, "api response", "serviceName", serviceName, "rpcName", rpc, "response", grpclog.ProtoMessageResponse(resp))`)
 		p("  return resp, err")
 		p("}")
 		p("")
 	}
 
+	g.genRetryThrottling()
+
 	outFile := filepath.Join(g.cfg.outDir, "helpers.go")
-	g.commit(outFile, g.cfg.pkgName)
-	return nil
+	_, err := g.commit(outFile, g.cfg.pkgName)
+	return err
 }
 
 // gen generates client for the given service.
@@ -369,12 +393,24 @@ func (g *generator) gen(serv *descriptorpb.ServiceDescriptorProto) error {
 			if err := g.genRESTMethods(serv, clientName); err != nil {
 				return err
 			}
+		case connect:
+			if err := g.genConnectMethods(serv, clientName); err != nil {
+				return err
+			}
+		case inprocess:
+			if err := g.genInProcessMethods(serv, clientName); err != nil {
+				return err
+			}
 		}
 	}
 
 	return g.genOperationBuilders(serv, clientName)
 }
 
+// getFormattedValue returns the expression formatting the given field of the
+// request of m, read by accessor, as a request header value. The accessor must
+// use getters, so that fields with explicit presence, i.e. proto3 optional
+// fields and most fields of Editions files, are formatted by value.
 func (g *generator) getFormattedValue(m *descriptorpb.MethodDescriptorProto, field string, accessor string) (string, error) {
 	f := g.lookupField(m.GetInputType(), field)
 	value := ""
@@ -609,7 +645,7 @@ func (g *generator) initializeAutoPopulatedFields(servName string, m *descriptor
 	p := g.printf
 	for _, apf := range apfs {
 		f := buildAccessor(apf.GetName(), true)
-		if apf.GetProto3Optional() {
+		if g.descInfo.HasExplicitPresence(apf) {
 			// Type will be *string if field has explicit presence.
 			p("if req != nil && req%s == nil {", f)
 			p("  req%s = proto.String(uuid.NewString())", f)
@@ -735,14 +771,24 @@ func containsDeprecated(com string) bool {
 
 func (g *generator) methodDoc(m *descriptorpb.MethodDescriptorProto, serv *descriptorpb.ServiceDescriptorProto) {
 	com := g.comments[m]
+	maskDoc := g.updateMaskDoc(m)
 
 	// If there's no comment and the method is not deprecated, adding method name is just confusing.
-	if !m.GetOptions().GetDeprecated() && com == "" {
+	if !m.GetOptions().GetDeprecated() && com == "" && maskDoc == "" {
 		return
 	}
 
-	if containsTransport(g.cfg.transports, rest) && m.GetClientStreaming() {
-		com = fmt.Sprintf("%s\n\nThis method is not supported for the REST transport.", com)
+	if usesGRPCStub(g.cfg.transports) && g.isMediaUpload(m) {
+		com = fmt.Sprintf("%s\n\nMedia upload is only supported for the REST transport.", com)
+	}
+	// The computation of the update_mask of the request follows the comment,
+	// or stands for it.
+	if maskDoc != "" {
+		if com == "" {
+			com = fmt.Sprintf("\n %s", maskDoc)
+		} else {
+			com = fmt.Sprintf("%s\n\n%s %s", com, m.GetName(), maskDoc)
+		}
 	}
 	// If the method is marked as deprecated and there is no comment, then add default deprecation comment.
 	// If the method has a comment but it does not include a deprecation notice, then append a default deprecation notice.
@@ -951,7 +997,14 @@ func (g *generator) resourceNameField(m *descriptorpb.MethodDescriptorProto) *he
 			return nil
 		}
 		target, err := identifyHeuristicTarget(m, h, g.vocabulary)
-		if err != nil || target == nil {
+		if err != nil {
+			g.diagnose(unmatchedHeuristicTarget, g.fqn(m), "no resource name inferred from the HTTP path: %v", err)
+			return nil
+		}
+		if target == nil {
+			if patterns := getHTTPPatterns(h); len(patterns) > 0 {
+				g.diagnose(unmatchedHeuristicTarget, g.fqn(m), "no resource name inferred from the HTTP path %q", patterns[0])
+			}
 			return nil
 		}
 		return target
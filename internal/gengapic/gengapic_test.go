@@ -17,6 +17,7 @@ package gengapic
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -882,7 +883,7 @@ func TestMethodDoc(t *testing.T) {
 		},
 		{
 			in:              "Does client streaming stuff.\nIt also does other stuffs.",
-			want:            "// MyMethod does client streaming stuff.\n// It also does other stuffs.\n//\n// This method is not supported for the REST transport.\n",
+			want:            "// MyMethod does client streaming stuff.\n// It also does other stuffs.\n",
 			clientStreaming: true,
 			cfg:             &generatorConfig{transports: []transport{rest}},
 		},
@@ -890,7 +891,7 @@ func TestMethodDoc(t *testing.T) {
 		g.cfg = tst.cfg
 		sm := snippets.NewMetadata("mypackage", "github.com/googleapis/mypackage", "mypackagego")
 		sm.AddService(servName, "mypackage.googleapis.com")
-		sm.AddMethod(servName, methodName, "mypackage", servName, 50)
+		sm.AddMethod(servName, methodName, "mypackage", servName, 17, 50)
 		g.snippetMetadata = sm
 		g.comments[m] = tst.in
 		m.Options = &descriptorpb.MethodOptions{
@@ -1994,3 +1995,210 @@ func TestSelectiveGapicMetadata(t *testing.T) {
 		t.Errorf("gapic_metadata got(-),want(+):\n%s", diff)
 	}
 }
+
+func TestReproducible(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1546300800")
+	req := thingsRequest("go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,metadata,reproducible")
+
+	first, err := gen(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Files are written as golden files of the second generation, which must
+	// produce the same files, in the same order.
+	dir := t.TempDir()
+	var names []string
+	for i, f := range first.GetFile() {
+		names = append(names, f.GetName())
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprint(i)), []byte(f.GetContent()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	second, err := gen(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var secondNames []string
+	for _, f := range second.GetFile() {
+		secondNames = append(secondNames, f.GetName())
+	}
+	if diff := cmp.Diff(names, secondNames); diff != "" {
+		t.Fatalf("generated files differ: got(-),want(+):\n%s", diff)
+	}
+	for i, f := range second.GetFile() {
+		txtdiff.Diff(t, f.GetContent(), filepath.Join(dir, fmt.Sprint(i)))
+	}
+
+	var routed bool
+	for _, f := range first.GetFile() {
+		routed = routed || strings.Contains(f.GetContent(), "routingHeadersMap[")
+		if strings.Contains(f.GetContent(), "Copyright") && !strings.Contains(f.GetContent(), "Copyright 2019 Google LLC") {
+			t.Errorf("%s: copyright notice does not use the year of SOURCE_DATE_EPOCH", f.GetName())
+		}
+		if strings.Contains(f.GetContent(), "range routingHeadersMap") {
+			t.Errorf("%s: routing headers are not ordered", f.GetName())
+		}
+	}
+	if !routed {
+		t.Error("no routing headers were generated")
+	}
+}
+
+func TestGenEditions(t *testing.T) {
+	str := func(name string, num int32, opts *descriptorpb.FieldOptions) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(num),
+			Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			JsonName: proto.String(name),
+			Options:  opts,
+		}
+	}
+	implicit := &descriptorpb.FieldOptions{
+		Features: &descriptorpb.FeatureSet{
+			FieldPresence: descriptorpb.FeatureSet_IMPLICIT.Enum(),
+		},
+	}
+	uuid4 := &descriptorpb.FieldOptions{}
+	proto.SetExtension(uuid4, annotations.E_FieldInfo, &annotations.FieldInfo{Format: annotations.FieldInfo_UUID4})
+
+	listReq := &descriptorpb.DescriptorProto{
+		Name: proto.String("ListThingsRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			str("parent", 1, nil),
+			{
+				Name:     proto.String("page_size"),
+				Number:   proto.Int32(2),
+				Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				JsonName: proto.String("pageSize"),
+			},
+			str("page_token", 3, nil),
+			str("filter", 4, implicit),
+			str("order_by", 5, nil),
+		},
+	}
+	listResp := &descriptorpb.DescriptorProto{
+		Name: proto.String("ListThingsResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("things"),
+				Number:   proto.Int32(1),
+				Label:    labelp(descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+				Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_MESSAGE),
+				TypeName: proto.String(".my.pkg.Thing"),
+				JsonName: proto.String("things"),
+			},
+			str("next_page_token", 2, nil),
+		},
+	}
+	createReq := &descriptorpb.DescriptorProto{
+		Name: proto.String("CreateThingRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			str("parent", 1, nil),
+			str("request_id", 2, uuid4),
+			str("legacy_request_id", 3, func() *descriptorpb.FieldOptions {
+				o := proto.Clone(uuid4).(*descriptorpb.FieldOptions)
+				o.Features = implicit.GetFeatures()
+				return o
+			}()),
+		},
+	}
+	thing := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Thing"),
+		Field: []*descriptorpb.FieldDescriptorProto{str("name", 1, nil)},
+	}
+
+	sOpts := &descriptorpb.ServiceOptions{}
+	proto.SetExtension(sOpts, annotations.E_DefaultHost, "my.googleapis.com")
+	list := &descriptorpb.MethodOptions{}
+	proto.SetExtension(list, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/{parent=projects/*}/things"},
+	})
+	proto.SetExtension(list, annotations.E_Routing, &annotations.RoutingRule{
+		RoutingParameters: []*annotations.RoutingParameter{
+			{Field: "parent", PathTemplate: "{project=projects/*}"},
+		},
+	})
+	create := &descriptorpb.MethodOptions{}
+	proto.SetExtension(create, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/v1/{parent=projects/*}/things"},
+		Body:    "*",
+	})
+
+	serviceYAML := filepath.Join(t.TempDir(), "my_v1.yaml")
+	err := os.WriteFile(serviceYAML, []byte(`type: google.api.Service
+config_version: 3
+name: my.googleapis.com
+publishing:
+  method_settings:
+  - selector: my.pkg.Foo.CreateThing
+    auto_populated_fields:
+    - request_id
+    - legacy_request_id
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"my/pkg/things.proto"},
+		Parameter:      proto.String("go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,generate-tests,omit-snippets,copyright-year=2019,api-service-config=" + serviceYAML),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("my/pkg/things.proto"),
+				Package: proto.String("my.pkg"),
+				Syntax:  proto.String("editions"),
+				Edition: descriptorpb.Edition_EDITION_2023.Enum(),
+				Options: &descriptorpb.FileOptions{
+					GoPackage: proto.String("github.com/googleapis/mypkg/pb;pkgpb"),
+				},
+				MessageType: []*descriptorpb.DescriptorProto{listReq, listResp, createReq, thing},
+				Service: []*descriptorpb.ServiceDescriptorProto{
+					{
+						Name:    proto.String("Foo"),
+						Options: sOpts,
+						Method: []*descriptorpb.MethodDescriptorProto{
+							{
+								Name:       proto.String("ListThings"),
+								InputType:  proto.String(".my.pkg.ListThingsRequest"),
+								OutputType: proto.String(".my.pkg.ListThingsResponse"),
+								Options:    list,
+							},
+							{
+								Name:       proto.String("CreateThing"),
+								InputType:  proto.String(".my.pkg.CreateThingRequest"),
+								OutputType: proto.String(".my.pkg.Thing"),
+								Options:    create,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	out, err := gen(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := NewResponse(out.GetFile(), nil)
+	if resp.GetSupportedFeatures()&uint64(pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS) == 0 {
+		t.Error("NewResponse() does not declare support for editions")
+	}
+	if got, want := resp.GetMinimumEdition(), int32(descriptorpb.Edition_EDITION_2023); got != want {
+		t.Errorf("NewResponse() minimum edition = %d, want %d", got, want)
+	}
+	if got, want := resp.GetMaximumEdition(), int32(descriptorpb.Edition_EDITION_2023); got != want {
+		t.Errorf("NewResponse() maximum edition = %d, want %d", got, want)
+	}
+
+	for _, f := range resp.GetFile() {
+		switch filepath.Base(f.GetName()) {
+		case "foo_client.go":
+			txtdiff.Diff(t, f.GetContent(), filepath.Join("testdata", "editions_client.want"))
+		case "foo_client_mock_test.go":
+			txtdiff.Diff(t, f.GetContent(), filepath.Join("testdata", "editions_client_mock_test.want"))
+		}
+	}
+}
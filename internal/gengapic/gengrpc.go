@@ -15,7 +15,6 @@
 package gengapic
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 
@@ -35,15 +34,51 @@ func lowcaseGRPCClientName(servName string) string {
 	return lowerFirst(servName + "GRPCClient")
 }
 
+// lowcaseStubClientName returns the name of the client type of transport t,
+// one of the transports calling the service through the gRPC stub.
+func lowcaseStubClientName(t transport, servName string) string {
+	switch t {
+	case connect:
+		return lowcaseConnectClientName(servName)
+	case inprocess:
+		return lowcaseInProcessClientName(servName)
+	default:
+		return lowcaseGRPCClientName(servName)
+	}
+}
+
+// grpcClientTypeName returns the name of the client type the methods calling
+// the gRPC stub are currently generated for.
+func (g *generator) grpcClientTypeName(servName string) string {
+	return lowcaseStubClientName(g.stubTransport, servName)
+}
+
 func (g *generator) genGRPCMethods(serv *descriptorpb.ServiceDescriptorProto, servName string) error {
-	g.addMetadataServiceForTransport(serv.GetName(), "grpc", servName)
+	return g.genStubMethods(serv, servName, grpc)
+}
+
+// genStubMethods generates the methods of a client calling the gRPC stub of
+// serv, and records them as the client methods of transport t.
+func (g *generator) genStubMethods(serv *descriptorpb.ServiceDescriptorProto, servName string, t transport) error {
+	g.stubTransport = t
+	defer func() { g.stubTransport = grpc }()
+
+	g.addMetadataServiceForTransport(serv.GetName(), t.String(), servName)
 
 	methods := g.getMethods(serv)
+	fl := g.flattenings(serv)
 	for _, m := range methods {
 		if err := g.genGRPCMethod(servName, serv, m); err != nil {
 			return fmt.Errorf("error generating method %q: %v", m.GetName(), err)
 		}
-		g.addMetadataMethod(serv.GetName(), "grpc", m.GetName(), g.methodName(m))
+		clientMethods := append([]string{g.methodName(m)}, flattenedNames(fl[m])...)
+		if g.hasHTTPBodyReader(serv, m) {
+			if err := g.httpBodyReaderGRPCCall(servName, m); err != nil {
+				return fmt.Errorf("error generating method %q: %v", m.GetName(), err)
+			}
+			clientMethods = append(clientMethods, g.httpBodyReaderName(m))
+		}
+		g.addMetadataMethod(serv.GetName(), t.String(), m.GetName(), clientMethods...)
 	}
 	return nil
 }
@@ -51,6 +86,8 @@ func (g *generator) genGRPCMethods(serv *descriptorpb.ServiceDescriptorProto, se
 // genGRPCMethod generates a single method from a client. m must be a method declared in serv.
 // If the generated method requires an auxiliary type, it is added to aux.
 func (g *generator) genGRPCMethod(servName string, serv *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto) error {
+	g.startRPC(m)
+
 	// Check if the RPC returns google.longrunning.Operation.
 	if g.isLRO(m) {
 		if err := g.maybeAddOperationWrapper(m); err != nil {
@@ -60,9 +97,7 @@ func (g *generator) genGRPCMethod(servName string, serv *descriptorpb.ServiceDes
 	}
 
 	if g.isMediaUpload(m) {
-		// TODO: implement change to RPC signature to return an upload helper.
-		// In the meantime, we just fail.
-		return errors.New("media upload support unimplemented")
+		return g.mediaUploadGRPCCall(servName, m)
 	}
 
 	if m.GetOutputType() == emptyType {
@@ -105,7 +140,7 @@ func (g *generator) unaryGRPCCall(servName string, m *descriptorpb.MethodDescrip
 
 	p := g.printf
 
-	lowcaseServName := lowcaseGRPCClientName(servName)
+	lowcaseServName := g.grpcClientTypeName(servName)
 	retTyp := fmt.Sprintf("%s.%s", outSpec.Name, outType.GetName())
 	p("func (c *%s) %s(ctx context.Context, req *%s.%s, opts ...gax.CallOption) (*%s, error) {",
 		lowcaseServName, g.methodName(m), inSpec.Name, inType.GetName(), retTyp)
@@ -145,7 +180,7 @@ func (g *generator) emptyUnaryGRPCCall(servName string, m *descriptorpb.MethodDe
 
 	p := g.printf
 
-	lowcaseServName := lowcaseGRPCClientName(servName)
+	lowcaseServName := g.grpcClientTypeName(servName)
 
 	p("func (c *%s) %s(ctx context.Context, req *%s.%s, opts ...gax.CallOption) error {",
 		lowcaseServName, g.methodName(m), inSpec.Name, inType.GetName())
@@ -172,7 +207,12 @@ func (g *generator) grpcStubCall(method *descriptorpb.MethodDescriptorProto) str
 	service := g.descInfo.ParentElement[method].(*descriptorpb.ServiceDescriptorProto)
 	override := g.getServiceNameOverride(service)
 	stub := pbinfo.ReduceServNameWithOverride(service.GetName(), g.cfg.pkgName, override)
-	return fmt.Sprintf("executeRPC(ctx, c.%s.%s, req, settings.GRPC, c.logger, %q)", grpcClientField(stub), method.GetName(), method.GetName())
+	fn := fmt.Sprintf("c.%s.%s", grpcClientField(stub), method.GetName())
+	if _, ok := g.hedgingPolicy(method); ok {
+		fn = fmt.Sprintf("hedged(%s)", fn)
+		g.aux.hedging = true
+	}
+	return fmt.Sprintf("executeRPC(ctx, %s, req, settings.GRPC, c.logger, %q)", fn, method.GetName())
 }
 
 func (g *generator) grpcClientOptions(serv *descriptorpb.ServiceDescriptorProto, servName string) error {
@@ -213,6 +253,12 @@ func (g *generator) grpcClientOptions(serv *descriptorpb.ServiceDescriptorProto,
 }
 
 func (g *generator) grpcCallOptions(serv *descriptorpb.ServiceDescriptorProto, servName string) {
+	g.stubCallOptions(serv, servName, fmt.Sprintf("default%sCallOptions", servName))
+}
+
+// stubCallOptions generates the function funcName returning the default call
+// options of the clients calling the gRPC stub.
+func (g *generator) stubCallOptions(serv *descriptorpb.ServiceDescriptorProto, servName, funcName string) {
 	p := g.printf
 
 	// defaultCallOptions
@@ -221,12 +267,14 @@ func (g *generator) grpcCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 	methods := g.getMethods(serv)
 
 	// read retry params from gRPC ServiceConfig
-	p("func default%[1]sCallOptions() *%[1]sCallOptions {", servName)
+	p("func %s() *%sCallOptions {", funcName, servName)
+	g.retryThrottleVar(methods)
 	p("  return &%sCallOptions{", servName)
 	for _, m := range methods {
 		sFQN := g.fqn(g.descInfo.ParentElement[m])
 		mn := m.GetName()
 		p("%s: []gax.CallOption{", mn)
+		g.retryThrottleOption()
 		if maxReq, ok := c.RequestLimit(sFQN, mn); ok {
 			p("gax.WithGRPCOptions(grpc.MaxCallSendMsgSize(%d)),", maxReq)
 		}
@@ -235,6 +283,10 @@ func (g *generator) grpcCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 			p("gax.WithGRPCOptions(grpc.MaxCallRecvMsgSize(%d)),", maxRes)
 		}
 
+		if g.waitForReady(m) {
+			p("gax.WithGRPCOptions(grpc.WaitForReady(true)),")
+		}
+
 		streaming := m.GetClientStreaming() || m.GetServerStreaming()
 		if timeout, ok := c.Timeout(sFQN, mn); !streaming && ok {
 			p("gax.WithTimeout(%d * time.Millisecond),", timeout)
@@ -242,30 +294,24 @@ func (g *generator) grpcCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 		}
 
 		if rp, ok := c.RetryPolicy(sFQN, mn); ok && rp != nil {
+			open, close := g.retryerWrappers(m, rp, grpc)
 			p("gax.WithRetry(func() gax.Retryer {")
-			p("  return gax.OnCodes([]codes.Code{")
+			p("  return %sgax.OnCodes([]codes.Code{", open)
 			for _, c := range rp.GetRetryableStatusCodes() {
-				cstr := c.String()
-
-				// Go uses the American-English spelling with a single "L"
-				if c == code.Code_CANCELLED {
-					cstr = "Canceled"
-				}
-
-				p("    codes.%s,", snakeToCamel(cstr))
+				p("    codes.%s,", grpcCodeName(c))
 			}
 			p("	 }, gax.Backoff{")
-			// this ignores max_attempts
 			p("		Initial:    %d * time.Millisecond,", conf.ToMillis(rp.GetInitialBackoff()))
 			p("		Max:        %d * time.Millisecond,", conf.ToMillis(rp.GetMaxBackoff()))
 			p("		Multiplier: %.2f,", rp.GetBackoffMultiplier())
-			p("	 })")
+			p("	 })%s", close)
 			p("}),")
 
 			// include imports necessary for retry configuration
 			g.imports[pbinfo.ImportSpec{Path: "time"}] = true
 			g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/codes"}] = true
 		}
+		g.hedgingOption(m)
 		p("},")
 	}
 	p("  }")
@@ -273,6 +319,19 @@ func (g *generator) grpcCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 	p("")
 }
 
+// grpcCodeName returns the name of the constant of package
+// google.golang.org/grpc/codes for the given status code.
+func grpcCodeName(c code.Code) string {
+	cstr := c.String()
+
+	// Go uses the American-English spelling with a single "L"
+	if c == code.Code_CANCELLED {
+		cstr = "Canceled"
+	}
+
+	return snakeToCamel(cstr)
+}
+
 func (g *generator) grpcClientInit(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, imp pbinfo.ImportSpec, hasRPCForLRO bool) {
 	p := g.printf
 
@@ -0,0 +1,130 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// inProcessConnSource is the implementation of the connection pool of
+// in-process clients. Everything following its import block is emitted into
+// the auxiliary.go of packages generated with the in-process transport.
+//
+//go:embed inprocessconn/inprocessconn.go
+var inProcessConnSource string
+
+func lowcaseInProcessClientName(servName string) string {
+	if servName == "" {
+		return "inProcessClient"
+	}
+
+	return lowerFirst(servName + "InProcessClient")
+}
+
+// genInProcessMethods generates the methods of the in-process client, which
+// calls the server implementation through the gRPC stub, like the gRPC client.
+func (g *generator) genInProcessMethods(serv *descriptorpb.ServiceDescriptorProto, servName string) error {
+	return g.genStubMethods(serv, servName, inprocess)
+}
+
+// inProcessCallOptions generates the default call options of the in-process
+// client, which retry settings are those of the gRPC client.
+func (g *generator) inProcessCallOptions(serv *descriptorpb.ServiceDescriptorProto, servName string) {
+	g.stubCallOptions(serv, servName, fmt.Sprintf("default%sInProcessCallOptions", servName))
+}
+
+// inProcessClient returns the parts of the in-process client of clientName.
+func inProcessClient(clientName string) connPoolClient {
+	return connPoolClient{
+		// We DON'T want to export the transport layers.
+		name:        lowcaseInProcessClientName(clientName),
+		over:        "by calling a server implementation in the same process",
+		connPoolDoc: "Connection pool dispatching the calls of the gRPC API client to the server implementation.",
+		kind:        "in-process",
+		closeDoc: []string{
+			"Close releases the resources of the client. The server implementation",
+			"is left untouched.",
+		},
+		logger: "internaloption.GetLogger(nil)",
+	}
+}
+
+func (g *generator) inProcessClientInit(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, imp pbinfo.ImportSpec, hasRPCForLRO bool) {
+	g.connPoolClientType(serv, clientName, optsName, imp, hasRPCForLRO, inProcessClient(clientName))
+	g.inProcessClientUtilities(serv, clientName, optsName, imp, hasRPCForLRO)
+	g.aux.inProcessConn = true
+}
+
+func (g *generator) inProcessClientUtilities(serv *descriptorpb.ServiceDescriptorProto, clientName, optsName string, imp pbinfo.ImportSpec, hasRPCForLRO bool) {
+	p := g.printf
+
+	docLibName := serv.GetName()
+	if override := g.getServiceNameOverride(serv); override != "" {
+		docLibName = override
+	}
+	docLibName = camelToSnake(docLibName)
+	docLibName = strings.Replace(docLibName, "_", " ", -1)
+	c := inProcessClient(clientName)
+
+	// Mixin services are dispatched to srv as well, if it implements them.
+	var mixinDescs []string
+	if hasRPCForLRO || g.hasLROMixin() {
+		mixinDescs = append(mixinDescs, "longrunningpb.Operations_ServiceDesc")
+		g.imports[pbinfo.ImportSpec{Name: "longrunningpb", Path: "cloud.google.com/go/longrunning/autogen/longrunningpb"}] = true
+	}
+	if g.hasIAMPolicyMixin() {
+		mixinDescs = append(mixinDescs, "iampb.IAMPolicy_ServiceDesc")
+	}
+	if g.hasLocationMixin() {
+		mixinDescs = append(mixinDescs, "locationpb.Locations_ServiceDesc")
+	}
+
+	// Factory function
+	p("// New%sInProcessClient creates a new %s client calling srv, an implementation", clientName, docLibName)
+	p("// of the service in the same process, without any network connection.")
+	p("// It is meant for tests and for embedding the service. Call options, request")
+	p("// headers and long-running operations behave as with the gRPC client, and")
+	p("// interceptors, if any, are called around the unary methods of srv.")
+	if len(mixinDescs) > 0 {
+		p("// The calls of mixin services are dispatched to srv if it implements them,")
+		p("// and fail with codes.Unimplemented otherwise.")
+	}
+	g.serviceDoc(serv, false) // exclude API version docs
+	p("func New%[1]sInProcessClient(srv %[2]s.%[3]sServer, interceptors ...grpc.UnaryServerInterceptor) (*%[1]sClient, error) {", clientName, imp.Name, serv.GetName())
+	p("  connPool := newInProcessConnPool(interceptors...)")
+	p("  connPool.register(&%s.%s_ServiceDesc, srv)", imp.Name, serv.GetName())
+	for _, d := range mixinDescs {
+		p("  connPool.register(&%s, srv)", d)
+	}
+	p("  client := %[1]sClient{CallOptions: default%[2]sInProcessCallOptions()}", clientName, optsName)
+	p("")
+	g.connPoolClientNew(serv, clientName, imp, hasRPCForLRO, c)
+
+	g.connPoolClientMethods(serv, c)
+}
+
+// genInProcessConn emits the implementation of the connection pool of
+// in-process clients, if any was generated.
+func (g *generator) genInProcessConn() error {
+	if !g.aux.inProcessConn {
+		return nil
+	}
+	return g.genEmbeddedSource(inProcessConnSource)
+}
@@ -15,7 +15,6 @@
 package gengapic
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -108,13 +107,21 @@ func (g *generator) genRESTMethods(serv *descriptorpb.ServiceDescriptorProto, se
 	g.addMetadataServiceForTransport(serv.GetName(), "rest", servName)
 
 	methods := g.getMethods(serv)
+	fl := g.flattenings(serv)
 
 	for _, m := range methods {
 		g.methodDoc(m, serv)
 		if err := g.genRESTMethod(servName, serv, m); err != nil {
 			return fmt.Errorf("error generating method %q: %v", m.GetName(), err)
 		}
-		g.addMetadataMethod(serv.GetName(), "rest", m.GetName(), g.methodName(m))
+		clientMethods := append([]string{g.methodName(m)}, flattenedNames(fl[m])...)
+		if g.hasHTTPBodyReader(serv, m) {
+			if err := g.httpBodyReaderRESTCall(servName, m); err != nil {
+				return fmt.Errorf("error generating method %q: %v", m.GetName(), err)
+			}
+			clientMethods = append(clientMethods, g.httpBodyReaderName(m))
+		}
+		g.addMetadataMethod(serv.GetName(), "rest", m.GetName(), clientMethods...)
 	}
 
 	return nil
@@ -496,7 +503,7 @@ func (g *generator) generateQueryString(m *descriptorpb.MethodDescriptorProto) {
 			b.WriteString("}")
 			paramAdd = b.String()
 
-		} else if field.GetProto3Optional() {
+		} else if g.descInfo.HasExplicitPresence(field) {
 			// Split right before the raw access
 			toks := strings.Split(path, ".")
 			toks = toks[:len(toks)-1]
@@ -566,7 +573,10 @@ func getHTTPInfo(m *descriptorpb.MethodDescriptorProto) *httpInfo {
 
 	eHTTP := proto.GetExtension(m.GetOptions(), annotations.E_Http)
 
-	httpRule := eHTTP.(*annotations.HttpRule)
+	return httpRuleInfo(eHTTP.(*annotations.HttpRule))
+}
+
+func httpRuleInfo(httpRule *annotations.HttpRule) *httpInfo {
 	info := httpInfo{body: httpRule.GetBody()}
 
 	switch httpRule.GetPattern().(type) {
@@ -593,6 +603,8 @@ func getHTTPInfo(m *descriptorpb.MethodDescriptorProto) *httpInfo {
 // genRESTMethod generates a single method from a client. m must be a method declared in serv.
 // If the generated method requires an auxiliary type, it is added to aux.
 func (g *generator) genRESTMethod(servName string, serv *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto) error {
+	g.startRPC(m)
+
 	if g.isLRO(m) {
 		if err := g.maybeAddOperationWrapper(m); err != nil {
 			return err
@@ -601,9 +613,7 @@ func (g *generator) genRESTMethod(servName string, serv *descriptorpb.ServiceDes
 	}
 
 	if g.isMediaUpload(m) {
-		// TODO: implement change to RPC signature to return an upload helper.
-		// In the meantime, we just fail.
-		return errors.New("media upload support unimplemented")
+		return g.mediaUploadRESTCall(servName, m)
 	}
 
 	if m.GetOutputType() == emptyType {
@@ -622,8 +632,10 @@ func (g *generator) genRESTMethod(servName string, serv *descriptorpb.ServiceDes
 	}
 
 	switch {
+	case m.GetClientStreaming() && m.GetServerStreaming():
+		return g.bidiStreamRESTCall(servName, serv, m)
 	case m.GetClientStreaming():
-		return g.noRequestStreamRESTCall(servName, serv, m)
+		return g.clientStreamRESTCall(servName, serv, m)
 	case m.GetServerStreaming():
 		return g.serverStreamRESTCall(servName, serv, m)
 	default:
@@ -714,6 +726,7 @@ func (g *generator) serverStreamRESTCall(servName string, s *descriptorpb.Servic
 	p("  if err != nil{")
 	p("   return err")
 	p("  }")
+	g.throttleSuccess("settings.GRPC")
 	p("")
 	p("  streamClient = &%s{", streamClient)
 	p("    ctx: ctx,")
@@ -785,33 +798,6 @@ func (g *generator) serverStreamRESTCall(servName string, s *descriptorpb.Servic
 	return nil
 }
 
-func (g *generator) noRequestStreamRESTCall(servName string, s *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto) error {
-	// Streaming calls are not currently supported for REST clients,
-	// but the interface signature must be preserved.
-	// Unimplemented REST methods will always error.
-
-	p := g.printf
-
-	servSpec, err := g.descInfo.ImportSpec(s)
-	if err != nil {
-		return err
-	}
-	g.imports[servSpec] = true
-
-	lowcaseServName := lowcaseRestClientName(servName)
-
-	p("func (c *%s) %s(ctx context.Context, opts ...gax.CallOption) (%s.%s_%sClient, error) {",
-		lowcaseServName, g.methodName(m), servSpec.Name, s.GetName(), m.GetName())
-	p(`  return nil, errors.New("%s not yet supported for REST clients")`, m.GetName())
-	p("}")
-	p("")
-
-	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
-	g.imports[pbinfo.ImportSpec{Path: "errors"}] = true
-
-	return nil
-}
-
 func (g *generator) pagingRESTCall(servName string, m *descriptorpb.MethodDescriptorProto, elemField, pageSize *descriptorpb.FieldDescriptorProto, pt *iterType) error {
 	lowcaseServName := lowcaseRestClientName(servName)
 	p := g.printf
@@ -837,7 +823,7 @@ func (g *generator) pagingRESTCall(servName string, m *descriptorpb.MethodDescri
 
 	g.imports[pbinfo.ImportSpec{Path: "math"}] = true
 	tok := "pageToken"
-	if isOptional(inType, "page_token") {
+	if g.isOptional(inType, "page_token") {
 		tok = fmt.Sprintf("proto.String(%s)", tok)
 	}
 
@@ -886,6 +872,7 @@ func (g *generator) pagingRESTCall(servName string, m *descriptorpb.MethodDescri
 	p("    if err != nil{")
 	p(`     return err`)
 	p("    }")
+	g.throttleSuccess("settings.GRPC")
 	p("    if err := unm.Unmarshal(buf, resp); err != nil {")
 	p("      return err")
 	p("    }")
@@ -999,6 +986,7 @@ func (g *generator) lroRESTCall(servName string, m *descriptorpb.MethodDescripto
 	p("  if err != nil{")
 	p("   return err")
 	p("  }")
+	g.throttleSuccess("settings.GRPC")
 	p("  if err := unm.Unmarshal(buf, resp); err != nil {")
 	p("    return err")
 	p("  }")
@@ -1097,7 +1085,7 @@ func (g *generator) emptyUnaryRESTCall(servName string, m *descriptorpb.MethodDe
 	p("  httpReq.Header = headers")
 	p("")
 	p("  _, err = executeHTTPRequest(ctx, c.httpClient, httpReq, c.logger, %s, %q)", logBody, m.GetName())
-	p("  return err")
+	g.returnAttempt("err")
 	p("  }, opts...)")
 	p("}")
 
@@ -1202,6 +1190,7 @@ func (g *generator) unaryRESTCall(servName string, m *descriptorpb.MethodDescrip
 	p("  if err != nil{")
 	p("   return err")
 	p("  }")
+	g.throttleSuccess("settings.GRPC")
 	p("")
 	if isHTTPBodyMessage {
 		p("resp.Data = buf")
@@ -1257,11 +1246,13 @@ func (g *generator) restCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 
 	// read retry params from gRPC ServiceConfig
 	p("func default%[1]sRESTCallOptions() *%[1]sCallOptions {", servName)
+	g.retryThrottleVar(methods)
 	p("  return &%sCallOptions{", servName)
 	for _, m := range methods {
 		sFQN := g.fqn(g.descInfo.ParentElement[m])
 		mn := m.GetName()
 		p("%s: []gax.CallOption{", mn)
+		g.retryThrottleOption()
 
 		if timeout, ok := c.Timeout(sFQN, mn); ok {
 			p("gax.WithTimeout(%d * time.Millisecond),", timeout)
@@ -1269,9 +1260,9 @@ func (g *generator) restCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 		}
 
 		if rp, ok := c.RetryPolicy(sFQN, mn); ok && rp != nil && len(rp.GetRetryableStatusCodes()) > 0 {
+			open, close := g.retryerWrappers(m, rp, rest)
 			p("gax.WithRetry(func() gax.Retryer {")
-			p("  return gax.OnHTTPCodes(gax.Backoff{")
-			// this ignores max_attempts
+			p("  return %sgax.OnHTTPCodes(gax.Backoff{", open)
 			p("    Initial:    %d * time.Millisecond,", conf.ToMillis(rp.GetInitialBackoff()))
 			p("    Max:        %d * time.Millisecond,", conf.ToMillis(rp.GetMaxBackoff()))
 			p("    Multiplier: %.2f,", rp.GetBackoffMultiplier())
@@ -1281,7 +1272,7 @@ func (g *generator) restCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 			for ndx, c := range rc {
 				s := fmt.Sprintf("%s,", gRPCToHTTP[c])
 				if ndx == len(rc)-1 {
-					s = strings.ReplaceAll(s, ",", ")")
+					s = strings.ReplaceAll(s, ",", ")") + close
 				}
 
 				p(s)
@@ -1290,6 +1281,11 @@ func (g *generator) restCallOptions(serv *descriptorpb.ServiceDescriptorProto, s
 
 			// include imports necessary for retry configuration
 			g.imports[pbinfo.ImportSpec{Path: "time"}] = true
+		} else if g.waitForReady(m) {
+			p("gax.WithRetry(func() gax.Retryer {")
+			p("  return withWaitForReady(nil)")
+			p("}),")
+			g.aux.waitForReady = true
 		}
 		p("},")
 	}
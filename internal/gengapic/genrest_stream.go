@@ -0,0 +1,403 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// restStreamSource is the implementation of the REST transports of request
+// streams. Everything following its import block is emitted into the
+// auxiliary.go of packages with client-streaming or bidi-streaming RPCs.
+//
+//go:embed reststream/reststream.go
+var restStreamSource string
+
+// restStreamTypes holds the Go types involved in a REST request stream.
+type restStreamTypes struct {
+	inSpec, outSpec, servSpec pbinfo.ImportSpec
+	inType, outType           pbinfo.ProtoType
+
+	// streamClient is the name of the type implementing the stream.
+	streamClient string
+
+	// elem is the expression of the part of a request message req sent in
+	// the stream, as selected by the body of the HTTP binding.
+	elem string
+}
+
+func (g *generator) restStreamTypesOf(s *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto, info *httpInfo) (*restStreamTypes, error) {
+	t := &restStreamTypes{
+		inType:       g.descInfo.Type[m.GetInputType()],
+		outType:      g.descInfo.Type[m.GetOutputType()],
+		streamClient: fmt.Sprintf("%sRESTStreamClient", lowerFirst(m.GetName())),
+		elem:         "req",
+	}
+	var err error
+	if t.inSpec, err = g.descInfo.ImportSpec(t.inType); err != nil {
+		return nil, err
+	}
+	if t.outSpec, err = g.descInfo.ImportSpec(t.outType); err != nil {
+		return nil, err
+	}
+	if t.servSpec, err = g.descInfo.ImportSpec(s); err != nil {
+		return nil, err
+	}
+	if info.body != "" && info.body != "*" {
+		t.elem = "req" + fieldGetter(info.body)
+	}
+	g.imports[t.inSpec] = true
+	g.imports[t.outSpec] = true
+	g.imports[t.servSpec] = true
+	return t, nil
+}
+
+// restStreamURL generates the code building the URL and headers of a stream
+// request from the first request message, req.
+func (g *generator) restStreamURL(m *descriptorpb.MethodDescriptorProto, info *httpInfo) {
+	g.generateBaseURL(info, "return nil, err")
+	g.generateQueryString(m)
+	g.printf("// Build HTTP headers from client and context metadata.")
+	g.insertRequestHeaders(m, rest)
+	g.injectTelemetryContext(m, info)
+}
+
+// clientStreamRESTCall generates the REST implementation of a client-streaming
+// RPC. The request messages are sent as the elements of a JSON array in a
+// chunked request body, which URL is derived from the first message.
+func (g *generator) clientStreamRESTCall(servName string, s *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto) error {
+	info := getHTTPInfo(m)
+	if info == nil {
+		return fmt.Errorf("method has no http info: %s", m.GetName())
+	}
+	verb := strings.ToUpper(info.verb)
+	if verb == http.MethodGet || verb == http.MethodDelete {
+		return fmt.Errorf("invalid use of a request stream for a get/delete method %q", m.GetName())
+	}
+	t, err := g.restStreamTypesOf(s, m, info)
+	if err != nil {
+		return err
+	}
+
+	p := g.printf
+	lowcaseServName := lowcaseRestClientName(servName)
+	inTyp := fmt.Sprintf("%s.%s", t.inSpec.Name, t.inType.GetName())
+	outTyp := fmt.Sprintf("%s.%s", t.outSpec.Name, t.outType.GetName())
+
+	// rest-client method
+	p("func (c *%s) %s(ctx context.Context, opts ...gax.CallOption) (%s.%s_%sClient, error) {",
+		lowcaseServName, g.methodName(m), t.servSpec.Name, s.GetName(), m.GetName())
+	g.appendCallOpts(m)
+	p("streamClient := &%s{ctx: ctx}", t.streamClient)
+	p("streamClient.open = func(req *%s) (*requestStream, error) {", inTyp)
+	g.restStreamURL(m, info)
+	p("  // Of the call settings, only the path applies to a request that is not")
+	p("  // retried.")
+	p("  var settings gax.CallSettings")
+	p("  for _, o := range opts {")
+	p("    o.Resolve(&settings)")
+	p("  }")
+	p(`  if settings.Path != "" {`)
+	p("    baseUrl.Path = settings.Path")
+	p("  }")
+	p("")
+	p("  return newRequestStream(func(body io.Reader) (*http.Response, error) {")
+	p("    // The messages sent cannot be replayed, so the request is not retried.")
+	p(`    httpReq, err := http.NewRequest("%s", baseUrl.String(), body)`, verb)
+	p("    if err != nil {")
+	p("      return nil, err")
+	p("    }")
+	p("    httpReq = httpReq.WithContext(ctx)")
+	p("    httpReq.Header = headers")
+	p("")
+	p("    return executeStreamingHTTPRequest(ctx, c.httpClient, httpReq, c.logger, nil, %q)", m.GetName())
+	p("  }), nil")
+	p("}")
+	p("return streamClient, nil")
+	p("}")
+	p("")
+
+	// client-stream wrapper client
+	p("// %s is the stream client used to send the client stream created by", t.streamClient)
+	p("// the REST implementation of %s.", m.GetName())
+	p("type %s struct {", t.streamClient)
+	p("  ctx context.Context")
+	p("  md metadata.MD")
+	p("  open func(*%s) (*requestStream, error)", inTyp)
+	p("  stream *requestStream")
+	p("}")
+	p("")
+	p("func (c *%s) Send(req *%s) error {", t.streamClient, inTyp)
+	p("  if c.stream == nil {")
+	p("    stream, err := c.open(req)")
+	p("    if err != nil {")
+	p("      return err")
+	p("    }")
+	p("    c.stream = stream")
+	p("  }")
+	g.protoJSONMarshaler()
+	p("  msg, err := m.Marshal(%s)", t.elem)
+	p("  if err != nil {")
+	p("    return err")
+	p("  }")
+	p("  return c.stream.send(msg)")
+	p("}")
+	p("")
+	p("func (c *%s) CloseAndRecv() (*%s, error) {", t.streamClient, outTyp)
+	p("  if err := c.CloseSend(); err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  httpRsp, err := c.stream.response()")
+	p("  if err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  defer httpRsp.Body.Close()")
+	p("  c.md = metadata.MD(httpRsp.Header)")
+	p("")
+	p("  buf, err := io.ReadAll(httpRsp.Body)")
+	p("  if err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  unm := protojson.UnmarshalOptions{AllowPartial: true, DiscardUnknown: true}")
+	p("  resp := &%s{}", outTyp)
+	p("  if err := unm.Unmarshal(buf, resp); err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  return resp, nil")
+	p("}")
+	p("")
+	p("func (c *%s) Header() (metadata.MD, error) {", t.streamClient)
+	p("  return c.md, nil")
+	p("}")
+	p("")
+	p("func (c *%s) Trailer() metadata.MD {", t.streamClient)
+	p("  return c.md")
+	p("}")
+	p("")
+	p("func (c *%s) CloseSend() error {", t.streamClient)
+	p("  if c.stream == nil {")
+	p("    // The request is made even if no message was sent.")
+	p("    stream, err := c.open(&%s{})", inTyp)
+	p("    if err != nil {")
+	p("      return err")
+	p("    }")
+	p("    c.stream = stream")
+	p("  }")
+	p("  return c.stream.closeSend()")
+	p("}")
+	p("")
+	p("func (c *%s) Context() context.Context {", t.streamClient)
+	p("  return c.ctx")
+	p("}")
+	p("")
+	p("func (c *%s) SendMsg(m interface{}) error {", t.streamClient)
+	p("  return c.Send(m.(*%s))", inTyp)
+	p("}")
+	p("")
+	p("func (c *%s) RecvMsg(m interface{}) error {", t.streamClient)
+	p("  // This is a no-op to fulfill the interface.")
+	p(`  return errors.New("this method is not implemented, use CloseAndRecv")`)
+	p("}")
+	p("")
+
+	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "errors"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "io"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/metadata"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/encoding/protojson"}] = true
+	g.aux.restStreams = true
+	return nil
+}
+
+// bidiStreamRESTCall generates the REST implementation of a bidi-streaming
+// RPC. The messages are exchanged over a WebSocket connection, which URL is
+// derived from the first request message.
+func (g *generator) bidiStreamRESTCall(servName string, s *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto) error {
+	info := getHTTPInfo(m)
+	if info == nil {
+		return fmt.Errorf("method has no http info: %s", m.GetName())
+	}
+	t, err := g.restStreamTypesOf(s, m, info)
+	if err != nil {
+		return err
+	}
+
+	p := g.printf
+	lowcaseServName := lowcaseRestClientName(servName)
+	inTyp := fmt.Sprintf("%s.%s", t.inSpec.Name, t.inType.GetName())
+	outTyp := fmt.Sprintf("%s.%s", t.outSpec.Name, t.outType.GetName())
+
+	// rest-client method
+	p("func (c *%s) %s(ctx context.Context, opts ...gax.CallOption) (%s.%s_%sClient, error) {",
+		lowcaseServName, g.methodName(m), t.servSpec.Name, s.GetName(), m.GetName())
+	g.appendCallOpts(m)
+	p("streamClient := &%s{ctx: ctx, opened: make(chan struct{})}", t.streamClient)
+	p("streamClient.open = func(req *%s) (*webSocketStream, error) {", inTyp)
+	g.restStreamURL(m, info)
+	p("")
+	p("  return dialWebSocket(ctx, func(upgrade http.Header) (*http.Response, error) {")
+	p("    var httpRsp *http.Response")
+	p("    // The connection is used after the call returns, so the request is")
+	p("    // bound to ctx rather than to the context of the attempt.")
+	p("    e := gax.Invoke(ctx, func(_ context.Context, settings gax.CallSettings) error {")
+	p(`      if settings.Path != "" {`)
+	p("        baseUrl.Path = settings.Path")
+	p("      }")
+	p(`      httpReq, err := http.NewRequest("GET", baseUrl.String(), nil)`)
+	p("      if err != nil {")
+	p("        return err")
+	p("      }")
+	p("      httpReq = httpReq.WithContext(ctx)")
+	p("      httpReq.Header = headers.Clone()")
+	p("      for k, v := range upgrade {")
+	p("        httpReq.Header[k] = v")
+	p("      }")
+	p("")
+	p("      httpRsp, err = c.httpClient.Do(httpReq)")
+	p("      if err != nil {")
+	p("        return err")
+	p("      }")
+	p("      if httpRsp.StatusCode != http.StatusSwitchingProtocols {")
+	p("        defer httpRsp.Body.Close()")
+	p("        return googleapi.CheckResponse(httpRsp)")
+	p("      }")
+	p("      return nil")
+	p("    }, opts...)")
+	p("    return httpRsp, e")
+	p("  })")
+	p("}")
+	p("return streamClient, nil")
+	p("}")
+	p("")
+
+	// bidi-stream wrapper client
+	p("// %s is the stream client used to send and receive the messages of the", t.streamClient)
+	p("// bidi stream created by the REST implementation of %s.", m.GetName())
+	p("type %s struct {", t.streamClient)
+	p("  ctx context.Context")
+	p("  open func(*%s) (*webSocketStream, error)", inTyp)
+	p("")
+	p("  // The stream is opened once, by the first of Send or CloseSend, and")
+	p("  // opened is closed when it is.")
+	p("  once sync.Once")
+	p("  opened chan struct{}")
+	p("  stream *webSocketStream")
+	p("  err error")
+	p("}")
+	p("")
+	p("func (c *%s) start(req *%s) error {", t.streamClient, inTyp)
+	p("  c.once.Do(func() {")
+	p("    c.stream, c.err = c.open(req)")
+	p("    close(c.opened)")
+	p("  })")
+	p("  return c.err")
+	p("}")
+	p("")
+	p("// wait blocks until the stream is opened.")
+	p("func (c *%s) wait() error {", t.streamClient)
+	p("  select {")
+	p("  case <-c.opened:")
+	p("    return c.err")
+	p("  case <-c.ctx.Done():")
+	p("    return c.ctx.Err()")
+	p("  }")
+	p("}")
+	p("")
+	p("func (c *%s) Send(req *%s) error {", t.streamClient, inTyp)
+	p("  if err := c.start(req); err != nil {")
+	p("    return err")
+	p("  }")
+	g.protoJSONMarshaler()
+	p("  msg, err := m.Marshal(%s)", t.elem)
+	p("  if err != nil {")
+	p("    return err")
+	p("  }")
+	p("  return c.stream.send(msg)")
+	p("}")
+	p("")
+	p("func (c *%s) Recv() (*%s, error) {", t.streamClient, outTyp)
+	p("  if err := c.wait(); err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  buf, err := c.stream.recv()")
+	p("  if err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  unm := protojson.UnmarshalOptions{AllowPartial: true, DiscardUnknown: true}")
+	p("  resp := &%s{}", outTyp)
+	p("  if err := unm.Unmarshal(buf, resp); err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  return resp, nil")
+	p("}")
+	p("")
+	p("func (c *%s) Header() (metadata.MD, error) {", t.streamClient)
+	p("  if err := c.wait(); err != nil {")
+	p("    return nil, err")
+	p("  }")
+	p("  return metadata.MD(c.stream.header), nil")
+	p("}")
+	p("")
+	p("func (c *%s) Trailer() metadata.MD {", t.streamClient)
+	p("  // This is a no-op to fulfill the interface.")
+	p("  return nil")
+	p("}")
+	p("")
+	p("func (c *%s) CloseSend() error {", t.streamClient)
+	p("  // The stream is opened even if no message was sent.")
+	p("  if err := c.start(&%s{}); err != nil {", inTyp)
+	p("    return err")
+	p("  }")
+	p("  return c.stream.closeSend()")
+	p("}")
+	p("")
+	p("func (c *%s) Context() context.Context {", t.streamClient)
+	p("  return c.ctx")
+	p("}")
+	p("")
+	p("func (c *%s) SendMsg(m interface{}) error {", t.streamClient)
+	p("  return c.Send(m.(*%s))", inTyp)
+	p("}")
+	p("")
+	p("func (c *%s) RecvMsg(m interface{}) error {", t.streamClient)
+	p("  // This is a no-op to fulfill the interface.")
+	p(`  return errors.New("this method is not implemented, use Recv")`)
+	p("}")
+	p("")
+
+	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "errors"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "sync"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/googleapi"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/metadata"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/encoding/protojson"}] = true
+	g.aux.restStreams = true
+	return nil
+}
+
+// genRESTStreams emits the implementation of the REST request streams, if
+// any client-streaming or bidi-streaming RPC was generated.
+func (g *generator) genRESTStreams() error {
+	if !g.aux.restStreams {
+		return nil
+	}
+	return g.genEmbeddedSource(restStreamSource)
+}
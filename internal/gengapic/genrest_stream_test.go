@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+)
+
+func TestGenRESTStreams(t *testing.T) {
+	g := &generator{
+		aux:     &auxTypes{},
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg:     &generatorConfig{},
+	}
+	if err := g.genRESTStreams(); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.pt.String(); got != "" {
+		t.Errorf("stream transports emitted although unused:\n%s", got)
+	}
+
+	g.aux.restStreams = true
+	if err := g.genRESTStreams(); err != nil {
+		t.Fatal(err)
+	}
+	got := g.pt.String()
+	for _, decl := range []string{"func newRequestStream(", "func dialWebSocket("} {
+		if !strings.Contains(got, decl) {
+			t.Errorf("missing %q", decl)
+		}
+	}
+	src := "package foo\n\n" + got
+	if _, err := parser.ParseFile(token.NewFileSet(), "auxiliary.go", src, parser.AllErrors); err != nil {
+		t.Errorf("generated stream code does not parse: %v", err)
+	}
+	for _, path := range []string{"bufio", "context", "crypto/rand", "crypto/sha1", "encoding/base64", "encoding/binary", "errors", "fmt", "io", "net/http", "sync"} {
+		if !g.imports[pbinfo.ImportSpec{Path: path}] {
+			t.Errorf("missing import %q", path)
+		}
+	}
+}
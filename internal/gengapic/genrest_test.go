@@ -622,6 +622,15 @@ func TestGenRestMethod(t *testing.T) {
 		Options: unaryRPCOpt,
 	}
 
+	bidiStreamRPC := &descriptorpb.MethodDescriptorProto{
+		Name:            proto.String("BidiStreamRPC"),
+		InputType:       proto.String(foofqn),
+		OutputType:      proto.String(foofqn),
+		ClientStreaming: proto.Bool(true),
+		ServerStreaming: proto.Bool(true),
+		Options:         unaryRPCOpt,
+	}
+
 	lroRPCOpt := &descriptorpb.MethodOptions{}
 	proto.SetExtension(lroRPCOpt, annotations.E_Http, &annotations.HttpRule{
 		Pattern: &annotations.HttpRule_Post{
@@ -677,6 +686,42 @@ func TestGenRestMethod(t *testing.T) {
 		Options:    updateRPCOpt,
 	}
 
+	uploadRPCOpt := &descriptorpb.MethodOptions{}
+	proto.SetExtension(uploadRPCOpt, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{
+			Post: "/v1/foo",
+		},
+		Body: "*",
+		AdditionalBindings: []*annotations.HttpRule{
+			{
+				Pattern: &annotations.HttpRule_Post{
+					Post: "/upload/v1/foo",
+				},
+				Body: "*",
+			},
+		},
+	})
+	uploadRPC := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("UploadRPC"),
+		InputType:  proto.String(foofqn),
+		OutputType: proto.String(foofqn),
+		Options:    uploadRPCOpt,
+	}
+
+	// The media upload binding of UploadEmptyRPC comes from the service config.
+	uploadEmptyRPCOpt := &descriptorpb.MethodOptions{}
+	proto.SetExtension(uploadEmptyRPCOpt, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Put{
+			Put: "/v1/foo/{other=*}",
+		},
+	})
+	uploadEmptyRPC := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("UploadEmptyRPC"),
+		InputType:  proto.String(foofqn),
+		OutputType: proto.String(emptyType),
+		Options:    uploadEmptyRPCOpt,
+	}
+
 	s := &descriptorpb.ServiceDescriptorProto{
 		Name:    proto.String("FooService"),
 		Options: &descriptorpb.ServiceOptions{},
@@ -688,6 +733,7 @@ func TestGenRestMethod(t *testing.T) {
 
 	f := &descriptorpb.FileDescriptorProto{
 		Package: proto.String(pkg),
+		Syntax:  proto.String("proto3"),
 		Options: &descriptorpb.FileOptions{
 			GoPackage: proto.String("google.golang.org/genproto/cloud/foo/v1;foo"),
 		},
@@ -702,6 +748,7 @@ func TestGenRestMethod(t *testing.T) {
 			iters:           map[string]*iterType{},
 			methodToWrapper: map[*descriptorpb.MethodDescriptorProto]operationWrapper{},
 			opWrappers:      map[string]operationWrapper{},
+			uploads:         map[string]*uploadType{},
 		},
 		cfg: &generatorConfig{featureEnablement: map[featureID]struct{}{OpenTelemetryAttributesFeature: {}}},
 		customOpServices: map[*descriptorpb.ServiceDescriptorProto]*descriptorpb.ServiceDescriptorProto{
@@ -729,9 +776,12 @@ func TestGenRestMethod(t *testing.T) {
 				pagingRPC:           s,
 				serverStreamRPC:     s,
 				clientStreamRPC:     s,
+				bidiStreamRPC:       s,
 				lroRPC:              s,
 				httpBodyRPC:         s,
 				updateRPC:           s,
+				uploadRPC:           s,
+				uploadEmptyRPC:      s,
 				nameField:           op,
 				sizeField:           foo,
 				otherField:          foo,
@@ -836,13 +886,42 @@ func TestGenRestMethod(t *testing.T) {
 			},
 		},
 		{
-			name:   "no_request_stream_rpc",
+			name:   "client_stream_rpc",
 			method: clientStreamRPC,
 			cfg:    &generatorConfig{featureEnablement: map[featureID]struct{}{OpenTelemetryAttributesFeature: {}}},
 			imports: map[pbinfo.ImportSpec]bool{
 				{Path: "context"}: true,
 				{Path: "errors"}:  true,
+				{Path: "fmt"}:     true,
+				{Path: "github.com/googleapis/gax-go/v2/callctx"}:       true,
+				{Path: "google.golang.org/grpc/metadata"}:               true,
+				{Path: "google.golang.org/protobuf/encoding/protojson"}: true,
+				{Path: "io"}:      true,
+				{Path: "net/url"}: true,
+				{Path: "regexp"}:  true,
+				{Path: "strings"}: true,
+				{Name: "foopb", Path: "google.golang.org/genproto/cloud/foo/v1"}: true,
+				{Name: "gax", Path: "github.com/googleapis/gax-go/v2"}:           true,
+			},
+		},
+		{
+			name:   "bidi_stream_rpc",
+			method: bidiStreamRPC,
+			cfg:    &generatorConfig{featureEnablement: map[featureID]struct{}{OpenTelemetryAttributesFeature: {}}},
+			imports: map[pbinfo.ImportSpec]bool{
+				{Path: "context"}: true,
+				{Path: "errors"}:  true,
+				{Path: "fmt"}:     true,
+				{Path: "github.com/googleapis/gax-go/v2/callctx"}:       true,
+				{Path: "google.golang.org/grpc/metadata"}:               true,
+				{Path: "google.golang.org/protobuf/encoding/protojson"}: true,
+				{Path: "google.golang.org/api/googleapi"}:               true,
+				{Path: "net/url"}: true,
+				{Path: "regexp"}:  true,
+				{Path: "strings"}: true,
+				{Path: "sync"}:    true,
 				{Name: "foopb", Path: "google.golang.org/genproto/cloud/foo/v1"}: true,
+				{Name: "gax", Path: "github.com/googleapis/gax-go/v2"}:           true,
 			},
 		},
 		{
@@ -893,6 +972,36 @@ func TestGenRestMethod(t *testing.T) {
 				{Name: "gax", Path: "github.com/googleapis/gax-go/v2"}:           true,
 			},
 		},
+		{
+			name:   "upload_rpc",
+			method: uploadRPC,
+			cfg: &generatorConfig{featureEnablement: map[featureID]struct{}{
+				MediaUploadFeature:             {},
+				OpenTelemetryAttributesFeature: {},
+			}},
+			imports: map[pbinfo.ImportSpec]bool{
+				{Path: "fmt"}:     true,
+				{Path: "net/url"}: true,
+				{Path: "google.golang.org/api/googleapi"}:                        true,
+				{Path: "google.golang.org/protobuf/encoding/protojson"}:          true,
+				{Name: "foopb", Path: "google.golang.org/genproto/cloud/foo/v1"}: true,
+				{Path: "github.com/googleapis/gax-go/v2/callctx"}:                true,
+				{Name: "gax", Path: "github.com/googleapis/gax-go/v2"}:           true,
+			},
+		},
+		{
+			name:   "upload_empty_rpc",
+			method: uploadEmptyRPC,
+			cfg: &generatorConfig{featureEnablement: map[featureID]struct{}{
+				MediaUploadFeature: {},
+			}},
+			imports: map[pbinfo.ImportSpec]bool{
+				{Path: "fmt"}:     true,
+				{Path: "net/url"}: true,
+				{Path: "google.golang.org/api/googleapi"}:                        true,
+				{Name: "foopb", Path: "google.golang.org/genproto/cloud/foo/v1"}: true,
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf("%s_%s", t.Name(), tst.name), func(t *testing.T) {
 			s.Method = []*descriptorpb.MethodDescriptorProto{tst.method}
@@ -907,6 +1016,12 @@ func TestGenRestMethod(t *testing.T) {
 								Get: "/v1beta1/{name=projects/*/locations/*/operations/*}",
 							},
 						},
+						{
+							Selector: "google.cloud.foo.v1.FooService.UploadEmptyRPC",
+							Pattern: &annotations.HttpRule_Put{
+								Put: "/upload/v1/foo/{other=*}",
+							},
+						},
 					},
 				},
 				Publishing: &annotations.Publishing{
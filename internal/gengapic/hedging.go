@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	conf "github.com/googleapis/gapic-generator-go/internal/grpc_service_config"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// isIdempotent reports whether calling the given RPC more than once has the
+// same effect as calling it once. This is the case for RPCs declaring an
+// idempotency_level, and for RPCs bound to HTTP GET.
+func isIdempotent(m *descriptorpb.MethodDescriptorProto) bool {
+	switch m.GetOptions().GetIdempotencyLevel() {
+	case descriptorpb.MethodOptions_NO_SIDE_EFFECTS, descriptorpb.MethodOptions_IDEMPOTENT:
+		return true
+	}
+	info := getHTTPInfo(m)
+	return info != nil && strings.ToUpper(info.verb) == http.MethodGet
+}
+
+// hedgingPolicy returns the hedging policy of the given RPC in the gRPC
+// service config, and whether hedged requests are sent for it. Hedged
+// requests are only sent for idempotent unary RPCs, since they may execute
+// more than once on the server.
+func (g *generator) hedgingPolicy(m *descriptorpb.MethodDescriptorProto) (*conf.MethodConfig_HedgingPolicy, bool) {
+	if m.GetClientStreaming() || m.GetServerStreaming() || !isIdempotent(m) {
+		return nil, false
+	}
+	sFQN := g.fqn(g.descInfo.ParentElement[m])
+	hp, ok := g.cfg.gRPCServiceConfig.HedgingPolicy(sFQN, m.GetName())
+	if !ok || hp == nil || capAttempts(hp.GetMaxAttempts()) < 2 {
+		return nil, false
+	}
+	return hp, true
+}
+
+// hedgingOption generates the call option sending hedged requests for the
+// given RPC, if it has a hedging policy.
+func (g *generator) hedgingOption(m *descriptorpb.MethodDescriptorProto) {
+	hp, ok := g.hedgingPolicy(m)
+	if !ok {
+		return
+	}
+	args := []string{
+		fmt.Sprint(capAttempts(hp.GetMaxAttempts())),
+		fmt.Sprintf("%d * time.Millisecond", conf.ToMillis(hp.GetHedgingDelay())),
+	}
+	for _, c := range hp.GetNonFatalStatusCodes() {
+		args = append(args, "codes."+grpcCodeName(c))
+		g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/codes"}] = true
+	}
+	g.printf("withHedging(%s),", strings.Join(args, ", "))
+	g.imports[pbinfo.ImportSpec{Path: "time"}] = true
+	g.aux.hedging = true
+}
+
+// genHedgingHelpers generates the helpers sending hedged requests, if any
+// RPC has a hedging policy.
+func (g *generator) genHedgingHelpers() {
+	if !g.aux.hedging {
+		return
+	}
+	p := g.printf
+
+	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "time"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "github.com/googleapis/gax-go/v2"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/codes"}] = true
+	g.imports[pbinfo.ImportSpec{Name: "gstatus", Path: "google.golang.org/grpc/status"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/proto"}] = true
+
+	p("// hedgingPolicy is carried by the gRPC call options of a call to send hedged")
+	p("// requests for each of its attempts.")
+	p("type hedgingPolicy struct {")
+	p("  grpc.EmptyCallOption")
+	p("  maxAttempts int")
+	p("  delay       time.Duration")
+	p("  nonFatal    []codes.Code")
+	p("}")
+	p("")
+	p("// withHedging sends up to maxAttempts requests for each attempt of a call,")
+	p("// the first one immediately and the following ones every delay, until one")
+	p("// succeeds. A request failing with a status in nonFatal lets the other")
+	p("// requests continue and sends the next one without waiting for delay. Any")
+	p("// other failure cancels the outstanding requests.")
+	p("func withHedging(maxAttempts int, delay time.Duration, nonFatal ...codes.Code) gax.CallOption {")
+	p("  return gax.WithGRPCOptions(hedgingPolicy{maxAttempts: maxAttempts, delay: delay, nonFatal: nonFatal})")
+	p("}")
+	p("")
+	p("func (h hedgingPolicy) isFatal(err error) bool {")
+	p("  c := gstatus.Code(err)")
+	p("  for _, nf := range h.nonFatal {")
+	p("    if c == nf {")
+	p("      return false")
+	p("    }")
+	p("  }")
+	p("  return true")
+	p("}")
+	p("")
+	p("// hedged returns fn sending hedged requests for each of its calls, as allowed")
+	p("// by the hedging policy in the call options of the call, if any.")
+	p("func hedged[I proto.Message, O proto.Message](fn func(context.Context, I, ...grpc.CallOption) (O, error)) func(context.Context, I, ...grpc.CallOption) (O, error) {")
+	p("  return func(ctx context.Context, req I, opts ...grpc.CallOption) (O, error) {")
+	p("    var h hedgingPolicy")
+	p("    for i := len(opts) - 1; i >= 0; i-- {")
+	p("      if hp, ok := opts[i].(hedgingPolicy); ok {")
+	p("        h = hp")
+	p("        break")
+	p("      }")
+	p("    }")
+	p("    if h.maxAttempts < 2 {")
+	p("      return fn(ctx, req, opts...)")
+	p("    }")
+	p("")
+	p("    // Outstanding requests are canceled once a response is returned.")
+	p("    ctx, cancel := context.WithCancel(ctx)")
+	p("    defer cancel()")
+	p("")
+	p("    type result struct {")
+	p("      resp O")
+	p("      err  error")
+	p("    }")
+	p("    results := make(chan result, h.maxAttempts)")
+	p("    var sent int")
+	p("    var next <-chan time.Time")
+	p("    send := func() {")
+	p("      sent++")
+	p("      go func() {")
+	p("        resp, err := fn(ctx, req, opts...)")
+	p("        results <- result{resp: resp, err: err}")
+	p("      }()")
+	p("      next = nil")
+	p("      if sent < h.maxAttempts {")
+	p("        next = time.After(h.delay)")
+	p("      }")
+	p("    }")
+	p("")
+	if g.retryThrottled() {
+		p("    throttle := callRetryThrottle(opts)")
+	}
+	p("    send()")
+	p("    var last result")
+	p("    for received := 0; received < sent; {")
+	p("      select {")
+	p("      case r := <-results:")
+	p("        received++")
+	p("        if r.err == nil || h.isFatal(r.err) {")
+	p("          return r.resp, r.err")
+	p("        }")
+	p("        last = r")
+	if g.retryThrottled() {
+		p("        if throttle != nil && throttle.fail() {")
+		p("          next = nil")
+		p("        } else if sent < h.maxAttempts {")
+		p("          send()")
+		p("        }")
+		p("      case <-next:")
+		p("        if throttle != nil && throttle.throttled() {")
+		p("          next = nil")
+		p("        } else {")
+		p("          send()")
+		p("        }")
+	} else {
+		p("        if sent < h.maxAttempts {")
+		p("          send()")
+		p("        }")
+		p("      case <-next:")
+		p("        send()")
+	}
+	p("      }")
+	p("    }")
+	p("    return last.resp, last.err")
+	p("  }")
+	p("}")
+	p("")
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	conf "github.com/googleapis/gapic-generator-go/internal/grpc_service_config"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	code "google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	duration "google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestHedgingPolicy(t *testing.T) {
+	httpOpts := func(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, rule)
+		return opts
+	}
+	hedgingPolicy := func(maxAttempts uint32) *conf.MethodConfig_HedgingPolicy_ {
+		return &conf.MethodConfig_HedgingPolicy_{
+			HedgingPolicy: &conf.MethodConfig_HedgingPolicy{
+				MaxAttempts:  maxAttempts,
+				HedgingDelay: &duration.Duration{Nanos: 50000000},
+				NonFatalStatusCodes: []code.Code{
+					code.Code_UNAVAILABLE,
+					code.Code_CANCELLED,
+				},
+			},
+		}
+	}
+	cpb := &conf.ServiceConfig{
+		MethodConfig: []*conf.MethodConfig{
+			{
+				Name:                 []*conf.MethodConfig_Name{{Service: "my.pkg.Foo"}},
+				RetryOrHedgingPolicy: hedgingPolicy(8),
+			},
+			{
+				Name:                 []*conf.MethodConfig_Name{{Service: "my.pkg.Foo", Method: "Single"}},
+				RetryOrHedgingPolicy: hedgingPolicy(1),
+			},
+			{
+				Name: []*conf.MethodConfig_Name{{Service: "my.pkg.Foo", Method: "Retried"}},
+				RetryOrHedgingPolicy: &conf.MethodConfig_RetryPolicy_{
+					RetryPolicy: &conf.MethodConfig_RetryPolicy{
+						MaxAttempts:          3,
+						InitialBackoff:       &duration.Duration{Nanos: 100000000},
+						MaxBackoff:           &duration.Duration{Seconds: 1},
+						BackoffMultiplier:    1.3,
+						RetryableStatusCodes: []code.Code{code.Code_UNAVAILABLE},
+					},
+				},
+			},
+		},
+	}
+	data, err := protojson.Marshal(cpb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcConf, err := conf.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := httpOpts(&annotations.HttpRule{Pattern: &annotations.HttpRule_Get{Get: "/v1/foo"}})
+	serv := &descriptorpb.ServiceDescriptorProto{
+		Name: proto.String("Foo"),
+		Method: []*descriptorpb.MethodDescriptorProto{
+			{Name: proto.String("Get"), Options: get},
+			{Name: proto.String("Idempotent"), Options: &descriptorpb.MethodOptions{
+				IdempotencyLevel: descriptorpb.MethodOptions_IDEMPOTENT.Enum(),
+			}},
+			{Name: proto.String("Post"), Options: httpOpts(&annotations.HttpRule{Pattern: &annotations.HttpRule_Post{Post: "/v1/foo"}})},
+			{Name: proto.String("Streaming"), Options: get, ServerStreaming: proto.Bool(true)},
+			{Name: proto.String("Single"), Options: get},
+			{Name: proto.String("Retried"), Options: get},
+		},
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("my.pkg"),
+		Service: []*descriptorpb.ServiceDescriptorProto{serv},
+	}
+	g := &generator{
+		aux:      &auxTypes{},
+		imports:  map[pbinfo.ImportSpec]bool{},
+		descInfo: pbinfo.Of([]*descriptorpb.FileDescriptorProto{file}),
+		cfg: &generatorConfig{
+			transports:        []transport{grpc},
+			gRPCServiceConfig: grpcConf,
+		},
+	}
+
+	for i, want := range []bool{true, true, false, false, false, false} {
+		m := serv.GetMethod()[i]
+		if _, got := g.hedgingPolicy(m); got != want {
+			t.Errorf("hedgingPolicy(%s) = %v, want %v", m.GetName(), got, want)
+		}
+	}
+
+	m := serv.GetMethod()[0]
+	g.hedgingOption(m)
+	if got, want := strings.TrimSpace(g.pt.String()), "withHedging(5, 50 * time.Millisecond, codes.Unavailable, codes.Canceled),"; got != want {
+		t.Errorf("hedgingOption(Get) = %s, want %s", got, want)
+	}
+	if got, want := g.grpcStubCall(m), `executeRPC(ctx, hedged(c.fooClient.Get), req, settings.GRPC, c.logger, "Get")`; got != want {
+		t.Errorf("grpcStubCall(Get) = %s, want %s", got, want)
+	}
+	if !g.aux.hedging {
+		t.Error("hedgingOption(Get) did not require the hedging helpers")
+	}
+}
+
+func TestGenHedgingHelpers(t *testing.T) {
+	g := &generator{
+		aux:     &auxTypes{hedging: true},
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg:     &generatorConfig{transports: []transport{grpc}},
+	}
+	g.genHedgingHelpers()
+
+	got := g.pt.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "auxiliary.go", "package foo\n\n"+got, parser.AllErrors); err != nil {
+		t.Errorf("generated helpers do not parse: %v", err)
+	}
+	txtdiff.Diff(t, got, filepath.Join("testdata", "hedging_helpers.want"))
+	if !g.imports[pbinfo.ImportSpec{Name: "gstatus", Path: "google.golang.org/grpc/status"}] {
+		t.Error("missing import of google.golang.org/grpc/status")
+	}
+}
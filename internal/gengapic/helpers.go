@@ -15,7 +15,6 @@
 package gengapic
 
 import (
-	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -86,11 +85,12 @@ func snakeToCamel(s string) string {
 }
 
 // isOptional returns true if the named Field in the given Message
-// is proto3_optional.
-func isOptional(m *descriptorpb.DescriptorProto, n string) bool {
+// has explicit presence, either being proto3_optional, a field of a proto2
+// file or through the field_presence feature of Protobuf Editions.
+func (g *generator) isOptional(m *descriptorpb.DescriptorProto, n string) bool {
 	for _, f := range m.GetField() {
 		if f.GetName() == n {
-			return f.GetProto3Optional()
+			return g.descInfo.HasExplicitPresence(f)
 		}
 	}
 
@@ -169,6 +169,12 @@ func getMethod(service *descriptorpb.ServiceDescriptorProto, method string) *des
 
 // containsTransport determines if a set of transports contains a specific
 // transport.
+// usesGRPCStub reports whether any of the transports calls the service
+// through the gRPC stub.
+func usesGRPCStub(t []transport) bool {
+	return containsTransport(t, grpc) || containsTransport(t, connect) || containsTransport(t, inprocess)
+}
+
 func containsTransport(t []transport, tr transport) bool {
 	for _, x := range t {
 		if x == tr {
@@ -193,6 +199,12 @@ func containsService(s []*descriptorpb.ServiceDescriptorProto, srv *descriptorpb
 
 // isRequired returns if a field is annotated as REQUIRED or not.
 func isRequired(field *descriptorpb.FieldDescriptorProto) bool {
+	return hasFieldBehavior(field, annotations.FieldBehavior_REQUIRED)
+}
+
+// hasFieldBehavior reports whether field is annotated with the given
+// google.api.field_behavior.
+func hasFieldBehavior(field *descriptorpb.FieldDescriptorProto, behavior annotations.FieldBehavior) bool {
 	if field.GetOptions() == nil {
 		return false
 	}
@@ -201,7 +213,7 @@ func isRequired(field *descriptorpb.FieldDescriptorProto) bool {
 
 	behaviors := eBehav.([]annotations.FieldBehavior)
 	for _, b := range behaviors {
-		if b == annotations.FieldBehavior_REQUIRED {
+		if b == behavior {
 			return true
 		}
 	}
@@ -212,46 +224,58 @@ func isRequired(field *descriptorpb.FieldDescriptorProto) bool {
 // This takes in a path template from a routing annotation and converts it into a regex string.
 // The named capture is the named segment portion for the header itself.
 func convertPathTemplateToRegex(pattern string) string {
-	// If path template doesn't exist, then use a wildcard.
-	if pattern == "" {
+	t, err := parsePathTemplate(pattern)
+	// If path template doesn't exist, or is invalid, then use a wildcard.
+	if pattern == "" || err != nil {
 		return "(.*)"
 	}
-	// Replace name of header to named capture.
-	regexPattern := strings.ReplaceAll(pattern, "{", "(?P<")
-	regexPattern = strings.ReplaceAll(regexPattern, "}", ")")
-	// If not named, then entire segment is a wildcard.
-	if !strings.Contains(pattern, "=") || !strings.Contains(pattern, "/") {
-		regexPattern = strings.ReplaceAll(regexPattern, "*", "")
-		regexPattern = strings.ReplaceAll(regexPattern, "=", "")
-		regexPattern = strings.ReplaceAll(regexPattern, ")", ">.*)")
-		return regexPattern
-	}
-	// Replace segment wildcards with regex equivalent
-	regexPattern = strings.ReplaceAll(regexPattern, "/**", "(?:/.*)?")
-	regexPattern = strings.ReplaceAll(regexPattern, "/*", "/[^/]+")
-	regexPattern = strings.ReplaceAll(regexPattern, "=**", ">.*")
-	regexPattern = strings.ReplaceAll(regexPattern, "=*", ">[^/]+")
-	regexPattern = strings.ReplaceAll(regexPattern, "=", ">")
-	regexPattern = strings.ReplaceAll(regexPattern, "**", ".*")
-	return regexPattern
+	return t.routingRegexp()
 }
 
 // This intakes a path template and returns the name of the header to be returned.
 func getHeaderName(pattern string) string {
-	curlyBraceRegex := regexp.MustCompile(`{([^}]+)\}`)
-	// Path template should only contain one name (e.g. at most one `=`) or
-	// a collectionId, and should be contained within curly braces.
-	if strings.Count(pattern, "=") > 1 || !curlyBraceRegex.MatchString(pattern) {
+	t, err := parsePathTemplate(pattern)
+	if err != nil {
+		return ""
+	}
+	// Path template should only contain one variable with a template, or
+	// name a collectionId, which is its own name.
+	var named int
+	for _, s := range t {
+		if len(s.sub) > 0 {
+			named++
+		}
+	}
+	vars := t.variables()
+	if named > 1 || len(vars) == 0 {
 		return ""
 	}
-	// curlyBraceSegment returns the named capture within the path template that is within the curly braces.
-	curlyBraceSegment := curlyBraceRegex.FindStringSubmatch(pattern)[1]
-	// If there is no equal sign, then the path template is a collectionId which is its own name.
-	// and both the named segment and path template are wildcards.
-	if strings.Count(pattern, "=") < 1 {
-		return curlyBraceSegment
+	return vars[0]
+}
+
+// requestField is a field set in a request literal, such as the request of a
+// flattened method or of a mock test.
+type requestField struct {
+	// name is the Go name of the field.
+	name string
+	// value is the Go expression of a scalar field.
+	value string
+	// typ is the Go type of a message field, e.g. foopb.Bar, and fields are the
+	// fields set in it.
+	typ    string
+	fields []*requestField
+}
+
+// requestFields prints the given fields of a request literal.
+func (g *generator) requestFields(fields []*requestField) {
+	p := g.printf
+	for _, f := range fields {
+		if f.typ == "" {
+			p("%s: %s,", f.name, f.value)
+			continue
+		}
+		p("%s: &%s{", f.name, f.typ)
+		g.requestFields(f.fields)
+		p("},")
 	}
-	getBeforeEqualsSign := regexp.MustCompile("(?P<before>[^=]*)=.*")
-	matches := getBeforeEqualsSign.FindStringSubmatch(curlyBraceSegment)
-	return matches[1]
 }
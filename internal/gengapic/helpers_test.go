@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
 	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/descriptorpb"
@@ -97,22 +98,63 @@ func TestIsOptional(t *testing.T) {
 			},
 		},
 	}
+	editionsMsg := &descriptorpb.DescriptorProto{
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name: proto.String("explicit"),
+			},
+			{
+				Name: proto.String("implicit"),
+				Options: &descriptorpb.FieldOptions{
+					Features: &descriptorpb.FeatureSet{
+						FieldPresence: descriptorpb.FeatureSet_IMPLICIT.Enum(),
+					},
+				},
+			},
+		},
+	}
+	g := &generator{
+		descInfo: pbinfo.Of([]*descriptorpb.FileDescriptorProto{
+			{
+				Syntax:      proto.String("proto3"),
+				MessageType: []*descriptorpb.DescriptorProto{msg},
+			},
+			{
+				Syntax:      proto.String("editions"),
+				Edition:     descriptorpb.Edition_EDITION_2023.Enum(),
+				MessageType: []*descriptorpb.DescriptorProto{editionsMsg},
+			},
+		}),
+	}
 	for _, tst := range []struct {
+		msg   *descriptorpb.DescriptorProto
 		field string
 		want  bool
 	}{
 		{
+			msg:   msg,
 			field: "opt",
 			want:  true,
 		},
 		{
+			msg:   msg,
 			field: "not_opt",
 		},
 		{
+			msg:   msg,
 			field: "no_such_field",
 		},
+		{
+			msg:   editionsMsg,
+			field: "explicit",
+			want:  true,
+		},
+		{
+			msg:   editionsMsg,
+			field: "implicit",
+		},
 	} {
-		if got := isOptional(msg, tst.field); got != tst.want {
+		if got := g.isOptional(tst.msg, tst.field); got != tst.want {
 			t.Errorf("isOptional(%q) = got %v, want %v", tst.field, got, tst.want)
 		}
 	}
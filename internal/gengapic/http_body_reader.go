@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// httpBodyReaderSource is the implementation of HttpBodyReader. Everything
+// following its import block is emitted into the auxiliary.go of packages
+// with HttpBody download RPCs.
+//
+//go:embed httpbodyreader/httpbodyreader.go
+var httpBodyReaderSource string
+
+// hasHTTPBodyReader evaluates if a streaming download variant is generated
+// for the given RPC of serv, in addition to the RPC itself. This is the case
+// for unary RPCs responding with google.api.HttpBody in clients supporting the
+// REST transport, unless the name of the variant is taken by another method
+// of the client.
+func (g *generator) hasHTTPBodyReader(serv *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto) bool {
+	if !g.featureEnabled(HTTPBodyReaderFeature) || !containsTransport(g.cfg.transports, rest) {
+		return false
+	}
+	if m.GetOutputType() != httpBodyType || m.GetClientStreaming() || m.GetServerStreaming() {
+		return false
+	}
+	if g.isMediaUpload(m) || getHTTPInfo(m) == nil {
+		return false
+	}
+	if name := g.httpBodyReaderName(m); g.clientMembers(serv)[name] {
+		g.diagnose(skippedHTTPBodyReader, g.fqn(m), "the download variant is skipped: %s is already a method of the client", name)
+		return false
+	}
+	return true
+}
+
+// httpBodyReaderName returns the name of the download variant of m.
+func (g *generator) httpBodyReaderName(m *descriptorpb.MethodDescriptorProto) string {
+	return g.methodName(m) + "Reader"
+}
+
+// httpBodyReaderRESTCall generates the REST implementation of the download
+// variant of m. The response body is handed to the HttpBodyReader as it is
+// received, and requested again with a Range header to resume the download.
+func (g *generator) httpBodyReaderRESTCall(servName string, m *descriptorpb.MethodDescriptorProto) error {
+	info := getHTTPInfo(m)
+	if info == nil {
+		return fmt.Errorf("method has no http info: %s", m.GetName())
+	}
+
+	inType := g.descInfo.Type[m.GetInputType()]
+	inSpec, err := g.descInfo.ImportSpec(inType)
+	if err != nil {
+		return err
+	}
+
+	p := g.printf
+	lowcaseServName := lowcaseRestClientName(servName)
+	p("func (c *%s) %s(ctx context.Context, req *%s.%s, offset, length int64, opts ...gax.CallOption) (*HttpBodyReader, error) {",
+		lowcaseServName, g.httpBodyReaderName(m), inSpec.Name, inType.GetName())
+	g.initializeAutoPopulatedFields(servName, m)
+
+	body, logBody := "nil", "nil"
+	verb := strings.ToUpper(info.verb)
+	if info.body != "" {
+		if verb == http.MethodGet || verb == http.MethodDelete {
+			return fmt.Errorf("invalid use of body parameter for a get/delete method %q", m.GetName())
+		}
+		g.protoJSONMarshaler()
+		requestObject := "req"
+		if info.body != "*" {
+			requestObject = "body"
+			p("body := req%s", fieldGetter(info.body))
+		}
+		p("jsonReq, err := m.Marshal(%s)", requestObject)
+		p("if err != nil {")
+		p("  return nil, err")
+		p("}")
+		p("")
+
+		body = "bytes.NewReader(jsonReq)"
+		logBody = "jsonReq"
+		g.imports[pbinfo.ImportSpec{Path: "bytes"}] = true
+		g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/encoding/protojson"}] = true
+	}
+
+	g.generateBaseURL(info, "return nil, err")
+	g.generateQueryString(m)
+	p("// Build HTTP headers from client and context metadata.")
+	g.insertRequestHeaders(m, rest)
+	g.injectTelemetryContext(m, info)
+
+	g.appendCallOpts(m)
+	p("open := func(ctx context.Context, rng string) (*http.Response, error) {")
+	p("  var httpRsp *http.Response")
+	p("  // The body is read after the call returns, so the request is bound to ctx")
+	p("  // rather than to the context of the attempt.")
+	p("  e := gax.Invoke(ctx, func(_ context.Context, settings gax.CallSettings) error {")
+	p(`    if settings.Path != "" {`)
+	p("      baseUrl.Path = settings.Path")
+	p("    }")
+	p(`    httpReq, err := http.NewRequest("%s", baseUrl.String(), %s)`, verb, body)
+	p("    if err != nil {")
+	p("      return err")
+	p("    }")
+	p("    httpReq = httpReq.WithContext(ctx)")
+	p("    httpReq.Header = headers.Clone()")
+	p(`    if rng != "" {`)
+	p(`      httpReq.Header.Set("Range", rng)`)
+	p("    }")
+	p("")
+	p("    httpRsp, err = executeStreamingHTTPRequest(ctx, c.httpClient, httpReq, c.logger, %s, %q)", logBody, m.GetName())
+	g.returnAttempt("err")
+	p("  }, opts...)")
+	p("  return httpRsp, e")
+	p("}")
+	p("return newHttpBodyReader(ctx, offset, length, open)")
+	p("}")
+	p("")
+
+	g.imports[inSpec] = true
+	g.aux.httpBodyReader = true
+	return nil
+}
+
+// httpBodyReaderGRPCCall generates the gRPC implementation of the download
+// variant of m. The gRPC transport has no means of streaming the HttpBody, so
+// the response is received in full before it is read.
+func (g *generator) httpBodyReaderGRPCCall(servName string, m *descriptorpb.MethodDescriptorProto) error {
+	inType := g.descInfo.Type[m.GetInputType()]
+	inSpec, err := g.descInfo.ImportSpec(inType)
+	if err != nil {
+		return err
+	}
+
+	p := g.printf
+	lowcaseServName := g.grpcClientTypeName(servName)
+	p("func (c *%s) %s(ctx context.Context, req *%s.%s, offset, length int64, opts ...gax.CallOption) (*HttpBodyReader, error) {",
+		lowcaseServName, g.httpBodyReaderName(m), inSpec.Name, inType.GetName())
+	p("resp, err := c.%s(ctx, req, opts...)", g.methodName(m))
+	p("if err != nil {")
+	p("  return nil, err")
+	p("}")
+	p("return httpBodyReaderFromData(resp.GetContentType(), resp.GetData(), offset, length)")
+	p("}")
+	p("")
+
+	g.imports[inSpec] = true
+	g.aux.httpBodyReader = true
+	return nil
+}
+
+// httpBodyReaderWrapperMethod generates the client method of the download
+// variant of m.
+func (g *generator) httpBodyReaderWrapperMethod(m *descriptorpb.MethodDescriptorProto, servName string) error {
+	inType := g.descInfo.Type[m.GetInputType()]
+	inSpec, err := g.descInfo.ImportSpec(inType)
+	if err != nil {
+		return err
+	}
+	name := g.httpBodyReaderName(m)
+
+	p := g.printf
+	p("// %s is a variant of %s that returns the content of the", name, g.methodName(m))
+	p("// google.api.HttpBody response as it is received, rather than buffering it")
+	p("// in memory. It reads length bytes of the content starting at offset, or")
+	p("// up to the end of the content if length is negative. If offset is")
+	p("// negative, the last -offset bytes of the content are read.")
+	p("//")
+	p("// Interrupted downloads are resumed from where they stopped. The caller")
+	p("// must close the returned reader.")
+	if containsTransport(g.cfg.transports, grpc) {
+		p("//")
+		p("// With the gRPC transport, the content is received in full before it is")
+		p("// read.")
+	}
+	p("func (c *%sClient) %s(ctx context.Context, req *%s.%s, offset, length int64, opts ...gax.CallOption) (*HttpBodyReader, error) {",
+		servName, name, inSpec.Name, inType.GetName())
+	p("    return c.internalClient.%s(ctx, req, offset, length, opts...)", name)
+	p("}")
+	p("")
+	return nil
+}
+
+// genHTTPBodyReader emits the implementation of HttpBodyReader, if any
+// download variant was generated.
+func (g *generator) genHTTPBodyReader() error {
+	if !g.aux.httpBodyReader {
+		return nil
+	}
+	return g.genEmbeddedSource(httpBodyReaderSource)
+}
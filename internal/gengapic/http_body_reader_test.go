@@ -0,0 +1,251 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestHasHTTPBodyReader(t *testing.T) {
+	httpOpts := func(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+		o := &descriptorpb.MethodOptions{}
+		proto.SetExtension(o, annotations.E_Http, rule)
+		return o
+	}
+	getRule := &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=media/*}"},
+	}
+	uploadRule := &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/upload/v1/media"},
+		Body:    "*",
+	}
+
+	for _, tst := range []struct {
+		name       string
+		method     *descriptorpb.MethodDescriptorProto
+		transports []transport
+		disabled   bool
+		others     []string
+		want       bool
+		wantDiag   bool
+	}{
+		{
+			name:   "http body",
+			method: &descriptorpb.MethodDescriptorProto{OutputType: proto.String(httpBodyType), Options: httpOpts(getRule)},
+			want:   true,
+		},
+		{
+			name:     "feature disabled",
+			method:   &descriptorpb.MethodDescriptorProto{OutputType: proto.String(httpBodyType), Options: httpOpts(getRule)},
+			disabled: true,
+		},
+		{
+			name:       "grpc only",
+			method:     &descriptorpb.MethodDescriptorProto{OutputType: proto.String(httpBodyType), Options: httpOpts(getRule)},
+			transports: []transport{grpc},
+		},
+		{
+			name:   "other response",
+			method: &descriptorpb.MethodDescriptorProto{OutputType: proto.String(".my.pkg.Media"), Options: httpOpts(getRule)},
+		},
+		{
+			name: "server streaming",
+			method: &descriptorpb.MethodDescriptorProto{
+				OutputType:      proto.String(httpBodyType),
+				ServerStreaming: proto.Bool(true),
+				Options:         httpOpts(getRule),
+			},
+		},
+		{
+			name:   "no http annotation",
+			method: &descriptorpb.MethodDescriptorProto{OutputType: proto.String(httpBodyType)},
+		},
+		{
+			name:   "media upload",
+			method: &descriptorpb.MethodDescriptorProto{OutputType: proto.String(httpBodyType), Options: httpOpts(uploadRule)},
+		},
+		{
+			name:     "name taken",
+			method:   &descriptorpb.MethodDescriptorProto{OutputType: proto.String(httpBodyType), Options: httpOpts(getRule)},
+			others:   []string{"GetMediaReader"},
+			wantDiag: true,
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			g := &generator{
+				cfg: &generatorConfig{
+					transports: []transport{grpc, rest},
+					featureEnablement: map[featureID]struct{}{
+						HTTPBodyReaderFeature: {},
+						MediaUploadFeature:    {},
+					},
+				},
+			}
+			if tst.transports != nil {
+				g.cfg.transports = tst.transports
+			}
+			if tst.disabled {
+				delete(g.cfg.featureEnablement, HTTPBodyReaderFeature)
+			}
+			tst.method.Name = proto.String("GetMedia")
+			serv := &descriptorpb.ServiceDescriptorProto{Method: []*descriptorpb.MethodDescriptorProto{tst.method}}
+			for _, o := range tst.others {
+				serv.Method = append(serv.Method, &descriptorpb.MethodDescriptorProto{Name: proto.String(o), OutputType: proto.String(".my.pkg.Media")})
+			}
+			if got := g.hasHTTPBodyReader(serv, tst.method); got != tst.want {
+				t.Errorf("hasHTTPBodyReader() = %v, want %v", got, tst.want)
+			}
+			if got := len(g.diagnostics) > 0; got != tst.wantDiag {
+				t.Errorf("hasHTTPBodyReader() diagnosed %v, want diagnostic %v", g.diagnostics, tst.wantDiag)
+			}
+		})
+	}
+}
+
+func TestHTTPBodyReaderCalls(t *testing.T) {
+	inputType := &descriptorpb.DescriptorProto{
+		Name: proto.String("GetMediaRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("name"),
+				JsonName: proto.String("name"),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			},
+		},
+	}
+	httpBodyDesc := protodesc.ToDescriptorProto((&httpbody.HttpBody{}).ProtoReflect().Descriptor())
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("my.pkg"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("mypackage"),
+		},
+	}
+	httpBodyFile := protodesc.ToFileDescriptorProto(httpbody.File_google_api_httpbody_proto)
+	opts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(opts, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Get{Get: "/v1/{name=media/*}"},
+	})
+	m := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("GetMedia"),
+		InputType:  proto.String(".my.pkg.GetMediaRequest"),
+		OutputType: proto.String(httpBodyType),
+		Options:    opts,
+	}
+	serv := &descriptorpb.ServiceDescriptorProto{
+		Name:   proto.String("MediaService"),
+		Method: []*descriptorpb.MethodDescriptorProto{m},
+	}
+	file.Service = []*descriptorpb.ServiceDescriptorProto{serv}
+
+	g := &generator{
+		imports: map[pbinfo.ImportSpec]bool{},
+		aux:     &auxTypes{},
+		cfg: &generatorConfig{
+			transports:        []transport{grpc, rest},
+			featureEnablement: map[featureID]struct{}{HTTPBodyReaderFeature: {}},
+		},
+		descInfo: pbinfo.Info{
+			Type: map[string]pbinfo.ProtoType{
+				".my.pkg.GetMediaRequest": inputType,
+				httpBodyType:              httpBodyDesc,
+			},
+			ParentFile: map[protoreflect.ProtoMessage]*descriptorpb.FileDescriptorProto{
+				inputType:    file,
+				httpBodyDesc: httpBodyFile,
+				serv:         file,
+			},
+			ParentElement: map[pbinfo.ProtoType]pbinfo.ProtoType{
+				m: serv,
+			},
+		},
+	}
+
+	for _, tst := range []struct {
+		name string
+		gen  func() error
+	}{
+		{
+			name: "rest_GetMediaReader",
+			gen:  func() error { return g.httpBodyReaderRESTCall("Media", m) },
+		},
+		{
+			name: "method_GetMediaReader",
+			gen:  func() error { return g.httpBodyReaderGRPCCall("Media", m) },
+		},
+		{
+			name: "wrapper_GetMediaReader",
+			gen:  func() error { return g.httpBodyReaderWrapperMethod(m, "Media") },
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			g.reset()
+			g.aux.httpBodyReader = false
+			if err := tst.gen(); err != nil {
+				t.Fatal(err)
+			}
+			if !g.aux.httpBodyReader && tst.name != "wrapper_GetMediaReader" {
+				t.Error("HttpBodyReader was not requested from auxiliary.go")
+			}
+			txtdiff.Diff(t, g.pt.String(), filepath.Join("testdata", tst.name+".want"))
+		})
+	}
+}
+
+func TestGenHTTPBodyReader(t *testing.T) {
+	g := &generator{
+		aux:     &auxTypes{},
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg:     &generatorConfig{},
+	}
+	if err := g.genHTTPBodyReader(); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.pt.String(); got != "" {
+		t.Errorf("HttpBodyReader emitted although unused:\n%s", got)
+	}
+
+	g.aux.httpBodyReader = true
+	if err := g.genHTTPBodyReader(); err != nil {
+		t.Fatal(err)
+	}
+	src := "package foo\n\n" + g.pt.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "auxiliary.go", src, parser.AllErrors); err != nil {
+		t.Errorf("generated reader code does not parse: %v", err)
+	}
+	want := map[pbinfo.ImportSpec]bool{
+		{Path: "bytes"}:    true,
+		{Path: "context"}:  true,
+		{Path: "errors"}:   true,
+		{Path: "fmt"}:      true,
+		{Path: "io"}:       true,
+		{Path: "net/http"}: true,
+	}
+	if diff := cmp.Diff(g.imports, want); diff != "" {
+		t.Errorf("imports got(-),want(+):\n%s", diff)
+	}
+}
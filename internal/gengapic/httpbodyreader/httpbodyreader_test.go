@@ -0,0 +1,259 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpbodyreader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDownloadServer serves content with support for Range requests. The
+// first dropAfter bytes of each of the first drops responses are sent before
+// the connection is dropped.
+type fakeDownloadServer struct {
+	content   []byte
+	etag      string
+	dropAfter int
+	drops     int
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (s *fakeDownloadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.ranges = append(s.ranges, r.Header.Get("Range"))
+	drop := s.drops > 0
+	if drop {
+		s.drops--
+	}
+	etag := s.etag
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-Goog-Custom", "custom")
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if drop {
+		w = &droppingWriter{ResponseWriter: w, left: s.dropAfter}
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(s.content))
+}
+
+// droppingWriter aborts the response once left bytes have been written.
+type droppingWriter struct {
+	http.ResponseWriter
+	left int
+}
+
+func (w *droppingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.left {
+		w.ResponseWriter.Write(p[:w.left])
+		w.ResponseWriter.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	w.left -= len(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func opener(srv *httptest.Server) func(context.Context, string) (*http.Response, error) {
+	return func(ctx context.Context, rng string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if rng != "" {
+			req.Header.Set("Range", rng)
+		}
+		return srv.Client().Do(req)
+	}
+}
+
+func testContent(size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestHttpBodyReader(t *testing.T) {
+	content := testContent(1 << 20)
+	for _, tst := range []struct {
+		name           string
+		offset, length int64
+		dropAfter      int
+		drops          int
+		want           []byte
+		wantRanges     []string
+	}{
+		{
+			name:       "all",
+			offset:     0,
+			length:     -1,
+			want:       content,
+			wantRanges: []string{""},
+		},
+		{
+			name:       "range",
+			offset:     100,
+			length:     1000,
+			want:       content[100:1100],
+			wantRanges: []string{"bytes=100-1099"},
+		},
+		{
+			name:       "from offset",
+			offset:     1000,
+			length:     -1,
+			want:       content[1000:],
+			wantRanges: []string{"bytes=1000-"},
+		},
+		{
+			name:       "suffix",
+			offset:     -10,
+			length:     -1,
+			want:       content[len(content)-10:],
+			wantRanges: []string{"bytes=-10"},
+		},
+		{
+			name:       "resume",
+			offset:     0,
+			length:     -1,
+			dropAfter:  300000,
+			drops:      2,
+			want:       content,
+			wantRanges: []string{"", "bytes=300000-", "bytes=600000-"},
+		},
+		{
+			name:       "resume range",
+			offset:     10,
+			length:     500000,
+			dropAfter:  300000,
+			drops:      1,
+			want:       content[10:500010],
+			wantRanges: []string{"bytes=10-500009", "bytes=300010-500009"},
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			fake := &fakeDownloadServer{content: content, etag: `"v1"`, dropAfter: tst.dropAfter, drops: tst.drops}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			r, err := newHttpBodyReader(context.Background(), tst.offset, tst.length, opener(srv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			if r.ContentType != "application/octet-stream" {
+				t.Errorf("got ContentType %q", r.ContentType)
+			}
+			if got := r.Header.Get("X-Goog-Custom"); got != "custom" {
+				t.Errorf("got header %q", got)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tst.want) {
+				t.Errorf("read %d bytes, want %d", len(got), len(tst.want))
+			}
+			if strings.Join(fake.ranges, ",") != strings.Join(tst.wantRanges, ",") {
+				t.Errorf("got Range headers %q, want %q", fake.ranges, tst.wantRanges)
+			}
+		})
+	}
+}
+
+func TestHttpBodyReader_ResumeFailures(t *testing.T) {
+	content := testContent(1 << 20)
+
+	t.Run("gives up", func(t *testing.T) {
+		// Every request is dropped before any content is sent.
+		fake := &fakeDownloadServer{content: content, dropAfter: 0, drops: 1 + maxHTTPBodyResumes}
+		srv := httptest.NewServer(fake)
+		defer srv.Close()
+		r, err := newHttpBodyReader(context.Background(), 0, -1, opener(srv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(r); err == nil {
+			t.Error("expected an error")
+		}
+		if got, want := len(fake.ranges), 1+maxHTTPBodyResumes; got != want {
+			t.Errorf("made %d requests, want %d", got, want)
+		}
+	})
+
+	t.Run("content changed", func(t *testing.T) {
+		fake := &fakeDownloadServer{content: content, etag: `"v1"`, dropAfter: 1000, drops: 1}
+		srv := httptest.NewServer(fake)
+		defer srv.Close()
+		r, err := newHttpBodyReader(context.Background(), 0, -1, opener(srv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fake.mu.Lock()
+		fake.etag = `"v2"`
+		fake.mu.Unlock()
+		_, err = io.ReadAll(r)
+		if err == nil || !strings.Contains(err.Error(), "content changed") {
+			t.Errorf("got error %v, want content changed", err)
+		}
+	})
+}
+
+func TestHttpBodyReaderFromData(t *testing.T) {
+	data := []byte("0123456789")
+	for _, tst := range []struct {
+		offset, length int64
+		want           string
+		wantErr        bool
+	}{
+		{offset: 0, length: -1, want: "0123456789"},
+		{offset: 2, length: 3, want: "234"},
+		{offset: 8, length: 5, want: "89"},
+		{offset: 20, length: -1, want: ""},
+		{offset: -3, length: -1, want: "789"},
+		{offset: -30, length: -1, want: "0123456789"},
+		{offset: 0, length: 0, wantErr: true},
+		{offset: -3, length: 2, wantErr: true},
+	} {
+		r, err := httpBodyReaderFromData("text/plain", data, tst.offset, tst.length)
+		if (err != nil) != tst.wantErr {
+			t.Errorf("httpBodyReaderFromData(%d, %d) error = %v, wantErr %v", tst.offset, tst.length, err, tst.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tst.want {
+			t.Errorf("httpBodyReaderFromData(%d, %d) = %q, want %q", tst.offset, tst.length, got, tst.want)
+		}
+		if r.ContentType != "text/plain" {
+			t.Errorf("got ContentType %q", r.ContentType)
+		}
+	}
+}
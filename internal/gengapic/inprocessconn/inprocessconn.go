@@ -0,0 +1,482 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inprocessconn contains the connection pool used by in-process
+// clients. It dispatches the calls of the gRPC API client directly to a server
+// implementation living in the same process, through the handlers of its
+// grpc.ServiceDesc, without any network hop.
+//
+// Servers see the outgoing metadata of the client as incoming metadata, and
+// may set headers and trailers as usual. Messages are copied in both
+// directions, so that neither side observes changes made by the other.
+//
+// The declarations following the import block are copied verbatim into the
+// generated auxiliary.go of any client package with the in-process transport,
+// so they must depend only on the standard library, gRPC and protobuf, and
+// must not reference anything declared outside of this file.
+package inprocessconn
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// inProcessConnPool implements gtransport.ConnPool by dispatching the calls to
+// server implementations registered with it.
+type inProcessConnPool struct {
+	unary       map[string]inProcessUnaryMethod
+	streams     map[string]inProcessStreamMethod
+	interceptor grpc.UnaryServerInterceptor
+}
+
+var _ grpc.ClientConnInterface = (*inProcessConnPool)(nil)
+
+type inProcessUnaryMethod struct {
+	srv     any
+	handler grpc.MethodHandler
+}
+
+type inProcessStreamMethod struct {
+	srv  any
+	desc grpc.StreamDesc
+}
+
+// newInProcessConnPool returns a pool without any registered service. The
+// interceptors, if any, are chained around the handlers of unary methods, the
+// first one being the outermost.
+func newInProcessConnPool(interceptors ...grpc.UnaryServerInterceptor) *inProcessConnPool {
+	return &inProcessConnPool{
+		unary:       map[string]inProcessUnaryMethod{},
+		streams:     map[string]inProcessStreamMethod{},
+		interceptor: inProcessChainInterceptors(interceptors),
+	}
+}
+
+// register makes the methods of desc dispatch to srv, if srv implements the
+// service. It reports whether it does.
+func (p *inProcessConnPool) register(desc *grpc.ServiceDesc, srv any) bool {
+	if srv == nil {
+		return false
+	}
+	if ht := reflect.TypeOf(desc.HandlerType).Elem(); !reflect.TypeOf(srv).Implements(ht) {
+		return false
+	}
+	for _, m := range desc.Methods {
+		p.unary["/"+desc.ServiceName+"/"+m.MethodName] = inProcessUnaryMethod{srv: srv, handler: m.Handler}
+	}
+	for _, s := range desc.Streams {
+		p.streams["/"+desc.ServiceName+"/"+s.StreamName] = inProcessStreamMethod{srv: srv, desc: s}
+	}
+	return true
+}
+
+// Conn returns nil, as no gRPC connection underlies the pool.
+func (p *inProcessConnPool) Conn() *grpc.ClientConn {
+	return nil
+}
+
+// Num returns the number of connections of the pool, which is always one.
+func (p *inProcessConnPool) Num() int {
+	return 1
+}
+
+// Close does nothing, as the pool holds no resources.
+func (p *inProcessConnPool) Close() error {
+	return nil
+}
+
+// Invoke calls the unary method with the handler of its server.
+func (p *inProcessConnPool) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	m, ok := p.unary[method]
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	ts := &inProcessTransportStream{method: method}
+	sctx := grpc.NewContextWithServerTransportStream(inProcessIncomingContext(ctx), ts)
+	dec := func(in any) error {
+		return inProcessCopy(in, args)
+	}
+	resp, err := m.handler(m.srv, sctx, dec, p.interceptor)
+	header, trailer := ts.finish()
+	inProcessSetMetadata(opts, header, trailer)
+	if err != nil {
+		return inProcessStatusError(ctx, err)
+	}
+	return inProcessCopy(reply, resp)
+}
+
+// NewStream starts the streaming method in a goroutine running the handler of
+// its server.
+func (p *inProcessConnPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	m, ok := p.streams[method]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	sctx, cancel := context.WithCancel(inProcessIncomingContext(ctx))
+	s := &inProcessStream{
+		ctx:           ctx,
+		serverStreams: desc.ServerStreams,
+		opts:          opts,
+		requests:      make(chan proto.Message),
+		closeSend:     make(chan struct{}),
+		responses:     make(chan proto.Message),
+		done:          make(chan struct{}),
+		ts:            &inProcessTransportStream{method: method, sent: make(chan struct{})},
+	}
+	ss := &inProcessServerStream{
+		ctx:    grpc.NewContextWithServerTransportStream(sctx, s.ts),
+		stream: s,
+	}
+	go func() {
+		defer cancel()
+		err := m.desc.Handler(m.srv, ss)
+		s.header, s.trailer = s.ts.finish()
+		if err != nil {
+			s.err = inProcessStatusError(sctx, err)
+		}
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// inProcessStream is the client side of a streaming call.
+type inProcessStream struct {
+	ctx           context.Context
+	serverStreams bool
+	opts          []grpc.CallOption
+
+	requests      chan proto.Message
+	closeSend     chan struct{}
+	closeSendOnce sync.Once
+	responses     chan proto.Message
+
+	ts *inProcessTransportStream
+
+	// Set by the handler goroutine before closing done.
+	done            chan struct{}
+	header, trailer metadata.MD
+	err             error
+
+	// Set when the stream is over for the client.
+	finished bool
+}
+
+func (s *inProcessStream) Header() (metadata.MD, error) {
+	select {
+	case <-s.ts.sent:
+		return s.ts.sentHeader(), nil
+	case <-s.done:
+		return s.header, nil
+	case <-s.ctx.Done():
+		return nil, status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+func (s *inProcessStream) Trailer() metadata.MD {
+	select {
+	case <-s.done:
+		return s.trailer
+	default:
+		return nil
+	}
+}
+
+func (s *inProcessStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *inProcessStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "cannot send %T, not a proto.Message", m)
+	}
+	msg = proto.Clone(msg)
+	select {
+	case s.requests <- msg:
+		return nil
+	case <-s.closeSend:
+		return status.Error(codes.Internal, "SendMsg called after CloseSend")
+	case <-s.done:
+		// As with gRPC, the status is returned by RecvMsg.
+		return io.EOF
+	case <-s.ctx.Done():
+		return status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+func (s *inProcessStream) CloseSend() error {
+	s.closeSendOnce.Do(func() { close(s.closeSend) })
+	return nil
+}
+
+func (s *inProcessStream) RecvMsg(m any) error {
+	if err := s.recvMsg(m); err != nil {
+		return err
+	}
+	if s.serverStreams {
+		return nil
+	}
+
+	// Exactly one response is expected: the call is over once the handler
+	// returns, which must not send another response.
+	select {
+	case <-s.responses:
+		return s.end(status.Error(codes.Internal, "cardinality violation: expected <EOF> for non server-streaming RPCs, but received another message"))
+	case <-s.done:
+		if err := s.end(nil); err != io.EOF {
+			return err
+		}
+		return nil
+	case <-s.ctx.Done():
+		return s.end(status.FromContextError(s.ctx.Err()).Err())
+	}
+}
+
+func (s *inProcessStream) recvMsg(m any) error {
+	if s.finished {
+		return s.end(nil)
+	}
+	select {
+	case resp := <-s.responses:
+		return inProcessCopy(m, resp)
+	case <-s.done:
+		err := s.end(nil)
+		if err == io.EOF && !s.serverStreams {
+			return status.Error(codes.Internal, "cardinality violation: expected <{}>, but received <EOF> for non server-streaming RPCs")
+		}
+		return err
+	case <-s.ctx.Done():
+		return s.end(status.FromContextError(s.ctx.Err()).Err())
+	}
+}
+
+// end finishes the stream for the client, returning err or else the status of
+// the call, and io.EOF if it succeeded.
+func (s *inProcessStream) end(err error) error {
+	s.finished = true
+	if err != nil {
+		return err
+	}
+	select {
+	case <-s.done:
+		inProcessSetMetadata(s.opts, s.header, s.trailer)
+		if s.err != nil {
+			return s.err
+		}
+		return io.EOF
+	default:
+		return status.FromContextError(s.ctx.Err()).Err()
+	}
+}
+
+// inProcessServerStream is the server side of a streaming call.
+type inProcessServerStream struct {
+	ctx    context.Context
+	stream *inProcessStream
+}
+
+func (ss *inProcessServerStream) SetHeader(md metadata.MD) error {
+	return ss.stream.ts.SetHeader(md)
+}
+
+func (ss *inProcessServerStream) SendHeader(md metadata.MD) error {
+	return ss.stream.ts.SendHeader(md)
+}
+
+func (ss *inProcessServerStream) SetTrailer(md metadata.MD) {
+	ss.stream.ts.SetTrailer(md)
+}
+
+func (ss *inProcessServerStream) Context() context.Context {
+	return ss.ctx
+}
+
+func (ss *inProcessServerStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "cannot send %T, not a proto.Message", m)
+	}
+	ss.stream.ts.SendHeader(nil)
+	msg = proto.Clone(msg)
+	select {
+	case ss.stream.responses <- msg:
+		return nil
+	case <-ss.ctx.Done():
+		return status.FromContextError(ss.ctx.Err()).Err()
+	}
+}
+
+func (ss *inProcessServerStream) RecvMsg(m any) error {
+	select {
+	case req := <-ss.stream.requests:
+		return inProcessCopy(m, req)
+	case <-ss.stream.closeSend:
+		// Requests sent before CloseSend have all been received, as sending is
+		// synchronous.
+		return io.EOF
+	case <-ss.ctx.Done():
+		return status.FromContextError(ss.ctx.Err()).Err()
+	}
+}
+
+// inProcessTransportStream records the headers and trailers set by a handler,
+// for grpc.SetHeader, grpc.SendHeader and grpc.SetTrailer to work.
+type inProcessTransportStream struct {
+	method string
+
+	mu         sync.Mutex
+	header     metadata.MD
+	trailer    metadata.MD
+	headerSent bool
+	// sent, if set, is closed once the header is sent.
+	sent chan struct{}
+}
+
+func (ts *inProcessTransportStream) Method() string {
+	return ts.method
+}
+
+func (ts *inProcessTransportStream) SetHeader(md metadata.MD) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.headerSent {
+		return status.Error(codes.Internal, "transport: the stream is done or SendHeader was already called")
+	}
+	ts.header = metadata.Join(ts.header, md)
+	return nil
+}
+
+func (ts *inProcessTransportStream) SendHeader(md metadata.MD) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.headerSent {
+		return status.Error(codes.Internal, "transport: the stream is done or SendHeader was already called")
+	}
+	ts.header = metadata.Join(ts.header, md)
+	ts.headerSent = true
+	if ts.sent != nil {
+		close(ts.sent)
+	}
+	return nil
+}
+
+func (ts *inProcessTransportStream) SetTrailer(md metadata.MD) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.trailer = metadata.Join(ts.trailer, md)
+	return nil
+}
+
+func (ts *inProcessTransportStream) sentHeader() metadata.MD {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.header
+}
+
+// finish sends the header if it was not yet, and returns the header and
+// trailer of the call.
+func (ts *inProcessTransportStream) finish() (header, trailer metadata.MD) {
+	ts.SendHeader(nil)
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.header, ts.trailer
+}
+
+// inProcessIncomingContext returns ctx with its outgoing metadata as incoming
+// metadata, as a server would receive it.
+func inProcessIncomingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewIncomingContext(ctx, md.Copy())
+}
+
+// inProcessSetMetadata fills the grpc.Header and grpc.Trailer call options in
+// opts.
+func inProcessSetMetadata(opts []grpc.CallOption, header, trailer metadata.MD) {
+	for _, o := range opts {
+		switch o := o.(type) {
+		case grpc.HeaderCallOption:
+			*o.HeaderAddr = header.Copy()
+		case grpc.TrailerCallOption:
+			*o.TrailerAddr = trailer.Copy()
+		}
+	}
+}
+
+// inProcessStatusError returns the error a gRPC client would receive for err,
+// returned by a handler called with ctx.
+func inProcessStatusError(ctx context.Context, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return status.FromContextError(ctxErr).Err()
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
+
+// inProcessCopy sets dst, a proto.Message, to a deep copy of src.
+func inProcessCopy(dst, src any) error {
+	d, ok := dst.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "cannot copy into %T, not a proto.Message", dst)
+	}
+	s, ok := src.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "cannot copy %T, not a proto.Message", src)
+	}
+	if d.ProtoReflect().Descriptor() != s.ProtoReflect().Descriptor() {
+		return status.Errorf(codes.Internal, "cannot copy %T into %T", src, dst)
+	}
+	proto.Reset(d)
+	proto.Merge(d, s)
+	return nil
+}
+
+// inProcessChainInterceptors returns an interceptor calling interceptors in
+// order, or nil if there is none.
+func inProcessChainInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	switch len(interceptors) {
+	case 0:
+		return nil
+	case 1:
+		return interceptors[0]
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(interceptors) - 1; i > 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+		return interceptors[0](ctx, req, info, next)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inprocessconn
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// echoServer is the interface of the service of echoServiceDesc, as
+// protoc-gen-go-grpc would generate it.
+type echoServer interface {
+	Echo(context.Context, *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+	Split(echoSplitServer) error
+}
+
+type echoSplitServer interface {
+	Send(*wrapperspb.StringValue) error
+	Recv() (*wrapperspb.StringValue, error)
+	grpc.ServerStream
+}
+
+type echoSplitServerImpl struct {
+	grpc.ServerStream
+}
+
+func (s echoSplitServerImpl) Send(m *wrapperspb.StringValue) error {
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s echoSplitServerImpl) Recv() (*wrapperspb.StringValue, error) {
+	m := &wrapperspb.StringValue{}
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var echoServiceDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*echoServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Echo",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := &wrapperspb.StringValue{}
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(echoServer).Echo(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Echo"}
+				handler := func(ctx context.Context, req any) (any, error) {
+					return srv.(echoServer).Echo(ctx, req.(*wrapperspb.StringValue))
+				}
+				return interceptor(ctx, in, info, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "Split",
+			Handler: func(srv any, stream grpc.ServerStream) error {
+				return srv.(echoServer).Split(echoSplitServerImpl{stream})
+			},
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+// echoImpl responds to Echo with the request followed by "!", and to every
+// message of Split with one message per word of its value. Both fail with
+// ABORTED on the value "fail".
+type echoImpl struct{}
+
+func (echoImpl) Echo(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs("x-echo-routing", strings.Join(md.Get("x-goog-request-params"), ",")))
+	grpc.SetTrailer(ctx, metadata.Pairs("x-trailer", "trailer"))
+	switch req.GetValue() {
+	case "fail":
+		return nil, status.Error(codes.Aborted, "failed here")
+	case "plain":
+		return nil, errors.New("plain error")
+	case "slow":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	// Responses must not alias requests.
+	req.Value += "!"
+	return req, nil
+}
+
+func (echoImpl) Split(stream echoSplitServer) error {
+	stream.SetTrailer(metadata.Pairs("x-trailer", "trailer"))
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if req.GetValue() == "fail" {
+			return status.Error(codes.Aborted, "failed here")
+		}
+		for _, w := range strings.Fields(req.GetValue()) {
+			if err := stream.Send(wrapperspb.String(w)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newEchoPool(t *testing.T, interceptors ...grpc.UnaryServerInterceptor) *inProcessConnPool {
+	p := newInProcessConnPool(interceptors...)
+	if !p.register(&echoServiceDesc, echoImpl{}) {
+		t.Fatal("echoImpl not registered")
+	}
+	return p
+}
+
+func TestRegister(t *testing.T) {
+	p := newInProcessConnPool()
+	if p.register(&echoServiceDesc, struct{}{}) {
+		t.Error("registered a server not implementing the service")
+	}
+	if p.register(&echoServiceDesc, nil) {
+		t.Error("registered a nil server")
+	}
+	err := p.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String("hi"), &wrapperspb.StringValue{})
+	if got := status.Code(err); got != codes.Unimplemented {
+		t.Errorf("Invoke of unregistered method: got code %v, want %v", got, codes.Unimplemented)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			calls = append(calls, name+" "+info.FullMethod)
+			return handler(ctx, req)
+		}
+	}
+	p := newEchoPool(t, interceptor("first"), interceptor("second"))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-goog-request-params", "name=foo")
+	req := wrapperspb.String("hello")
+	resp := &wrapperspb.StringValue{Value: "stale"}
+	var header, trailer metadata.MD
+	if err := p.Invoke(ctx, "/test.Echo/Echo", req, resp, grpc.Header(&header), grpc.Trailer(&trailer)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.GetValue(), "hello!"; got != want {
+		t.Errorf("response: got %q, want %q", got, want)
+	}
+	if got, want := req.GetValue(), "hello"; got != want {
+		t.Errorf("request modified by the server: got %q, want %q", got, want)
+	}
+	if got, want := header.Get("x-echo-routing"), []string{"name=foo"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("header x-echo-routing: got %q, want %q", got, want)
+	}
+	if got, want := trailer.Get("x-trailer"), []string{"trailer"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("trailer x-trailer: got %q, want %q", got, want)
+	}
+	if got, want := strings.Join(calls, ";"), "first /test.Echo/Echo;second /test.Echo/Echo"; got != want {
+		t.Errorf("interceptor calls: got %q, want %q", got, want)
+	}
+}
+
+func TestInvoke_Errors(t *testing.T) {
+	p := newEchoPool(t)
+
+	for _, tst := range []struct {
+		value   string
+		timeout time.Duration
+		code    codes.Code
+		msg     string
+	}{
+		{value: "fail", code: codes.Aborted, msg: "failed here"},
+		{value: "plain", code: codes.Unknown, msg: "plain error"},
+		{value: "slow", timeout: 10 * time.Millisecond, code: codes.DeadlineExceeded},
+	} {
+		ctx := context.Background()
+		if tst.timeout != 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tst.timeout)
+			defer cancel()
+		}
+		err := p.Invoke(ctx, "/test.Echo/Echo", wrapperspb.String(tst.value), &wrapperspb.StringValue{})
+		s, _ := status.FromError(err)
+		if s.Code() != tst.code {
+			t.Errorf("%s: got code %v, want %v", tst.value, s.Code(), tst.code)
+		}
+		if tst.msg != "" && s.Message() != tst.msg {
+			t.Errorf("%s: got message %q, want %q", tst.value, s.Message(), tst.msg)
+		}
+	}
+}
+
+func TestNewStream(t *testing.T) {
+	p := newEchoPool(t)
+	desc := &echoServiceDesc.Streams[0]
+
+	for _, tst := range []struct {
+		name string
+		reqs []string
+		want []string
+		code codes.Code
+	}{
+		{name: "bidi", reqs: []string{"a b", "c"}, want: []string{"a", "b", "c"}, code: codes.OK},
+		{name: "error", reqs: []string{"a", "fail"}, want: []string{"a"}, code: codes.Aborted},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			var trailer metadata.MD
+			s, err := p.NewStream(context.Background(), desc, "/test.Echo/Split", grpc.Trailer(&trailer))
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				for _, r := range tst.reqs {
+					if err := s.SendMsg(wrapperspb.String(r)); err != nil {
+						break
+					}
+				}
+				s.CloseSend()
+			}()
+
+			var got []string
+			for {
+				resp := &wrapperspb.StringValue{}
+				err := s.RecvMsg(resp)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if c := status.Code(err); c != tst.code {
+						t.Errorf("got code %v, want %v", c, tst.code)
+					}
+					break
+				}
+				got = append(got, resp.GetValue())
+			}
+			if strings.Join(got, " ") != strings.Join(tst.want, " ") {
+				t.Errorf("responses: got %q, want %q", got, tst.want)
+			}
+			if tst.code == codes.OK && len(got) != len(tst.want) {
+				t.Errorf("stream ended early")
+			}
+			if v := trailer.Get("x-trailer"); len(v) != 1 || v[0] != "trailer" {
+				t.Errorf("trailer x-trailer: got %q, want %q", v, "trailer")
+			}
+		})
+	}
+}
@@ -39,7 +39,7 @@ func (g *generator) lroCall(servName string, m *descriptorpb.MethodDescriptorPro
 	lroType := lroTypeName(m)
 	p := g.printf
 
-	lowcaseServName := lowerFirst(servName + "GRPCClient")
+	lowcaseServName := g.grpcClientTypeName(servName)
 
 	p("func (c *%s) %s(ctx context.Context, req *%s.%s, opts ...gax.CallOption) (*%s, error) {",
 		lowcaseServName, g.methodName(m), inSpec.Name, inType.GetName(), lroType)
@@ -114,8 +114,8 @@ func (g *generator) genOperationBuilder(servName string, m *descriptorpb.MethodD
 			p("// The name must be that of a previously created %s, possibly from a different process.", ow.name)
 
 			switch t {
-			case grpc:
-				receiver := lowcaseGRPCClientName(servName)
+			case grpc, connect, inprocess:
+				receiver := lowcaseStubClientName(t, servName)
 				p("func (c *%s) %s(name string) *%[3]s {", receiver, builderName, ow.name)
 				p("  return &%s{", ow.name)
 				p("    lro: longrunning.InternalNewOperationWithMetadata(*c.LROClient, &longrunningpb.Operation{Name: name}, %q),", fmt.Sprintf("*%s.%s", g.cfg.pkgName, ow.name))
@@ -14,15 +14,352 @@
 
 package gengapic
 
-import "google.golang.org/protobuf/types/descriptorpb"
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// mediaUploadPathPrefix is the path prefix of HTTP bindings that accept media
+// uploads, e.g. "/upload/storage/v1/b/{bucket}/o".
+const mediaUploadPathPrefix = "/upload/"
+
+// mediaUploadSource is the implementation of the upload protocols. Everything
+// following its import block is emitted into the auxiliary.go of packages
+// with media upload RPCs.
+//
+//go:embed mediaupload/mediaupload.go
+var mediaUploadSource string
+
+// uploadType describes the helper type returned by a media upload RPC.
+type uploadType struct {
+	// name is the Go type name of the helper e.g. InsertObjectUpload.
+	name string
+
+	// respTyp is the Go type of the RPC response e.g. *storagepb.Object, or
+	// empty if the RPC returns google.protobuf.Empty.
+	respTyp string
+
+	// respImport is the import of the RPC response type, if any.
+	respImport pbinfo.ImportSpec
+}
 
 // isMediaUpload evaluates if a given RPC method relies on media upload.
+//
+// Longrunning and streaming RPCs are never treated as media uploads.
 func (g *generator) isMediaUpload(m *descriptorpb.MethodDescriptorProto) bool {
 	if !g.featureEnabled(MediaUploadFeature) {
 		// Disallow detection if the feature isn't enabled.
 		return false
 	}
 
-	// TODO: implement detection and config flow.
-	return false
+	if g.isLRO(m) || m.GetClientStreaming() || m.GetServerStreaming() {
+		return false
+	}
+
+	return g.mediaUploadInfo(m) != nil
+}
+
+// mediaUploadInfo returns the HTTP binding through which media is uploaded to
+// the given RPC, or nil if there is none. The binding may be the RPC's
+// google.api.http annotation, one of its additional_bindings, or an http rule
+// in the service config.
+func (g *generator) mediaUploadInfo(m *descriptorpb.MethodDescriptorProto) *httpInfo {
+	var rules []*annotations.HttpRule
+	if rule, ok := proto.GetExtension(m.GetOptions(), annotations.E_Http).(*annotations.HttpRule); ok && rule != nil {
+		rules = append(rules, rule)
+		rules = append(rules, rule.GetAdditionalBindings()...)
+	}
+	if _, ok := g.descInfo.ParentElement[m]; ok {
+		fqn := g.fqn(m)
+		for _, rule := range g.cfg.APIServiceConfig.GetHttp().GetRules() {
+			if rule.GetSelector() == fqn {
+				rules = append(rules, rule)
+				rules = append(rules, rule.GetAdditionalBindings()...)
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		if info := httpRuleInfo(rule); strings.HasPrefix(info.url, mediaUploadPathPrefix) {
+			return info
+		}
+	}
+	return nil
+}
+
+func uploadTypeName(m *descriptorpb.MethodDescriptorProto) string {
+	return m.GetName() + "Upload"
+}
+
+// uploadTypeOf returns the upload helper type of the given media upload RPC,
+// adding it to the collection of auxiliary types to generate.
+func (g *generator) uploadTypeOf(m *descriptorpb.MethodDescriptorProto) (*uploadType, error) {
+	ut := &uploadType{name: uploadTypeName(m)}
+	if m.GetOutputType() != emptyType {
+		outType := g.descInfo.Type[m.GetOutputType()]
+		outSpec, err := g.descInfo.ImportSpec(outType)
+		if err != nil {
+			return nil, err
+		}
+		ut.respTyp = fmt.Sprintf("*%s.%s", outSpec.Name, outType.GetName())
+		ut.respImport = outSpec
+	}
+
+	if existing, ok := g.aux.uploads[ut.name]; ok {
+		if existing.respTyp != ut.respTyp {
+			return nil, fmt.Errorf("duplicate upload helper types %q have mismatched response types: %s v. %s", ut.name, existing.respTyp, ut.respTyp)
+		}
+		return existing, nil
+	}
+	g.aux.uploads[ut.name] = ut
+
+	return ut, nil
+}
+
+// uploadReturns returns the result list of the call function of an upload
+// helper, and the statement used to return the given error from it.
+func (ut *uploadType) uploadReturns(err string) (results, ret string) {
+	if ut.respTyp == "" {
+		return "error", fmt.Sprintf("return %s", err)
+	}
+	return fmt.Sprintf("(%s, error)", ut.respTyp), fmt.Sprintf("return nil, %s", err)
+}
+
+// mediaUploadRESTCall generates the REST implementation of a media upload
+// RPC, which returns an upload helper that performs the RPC once configured.
+func (g *generator) mediaUploadRESTCall(servName string, m *descriptorpb.MethodDescriptorProto) error {
+	info := g.mediaUploadInfo(m)
+	if info == nil {
+		return fmt.Errorf("method has no media upload http info: %s", m.GetName())
+	}
+
+	inType := g.descInfo.Type[m.GetInputType()]
+	inSpec, err := g.descInfo.ImportSpec(inType)
+	if err != nil {
+		return err
+	}
+	ut, err := g.uploadTypeOf(m)
+	if err != nil {
+		return err
+	}
+	results, retErr := ut.uploadReturns("err")
+
+	p := g.printf
+	lowcaseServName := lowcaseRestClientName(servName)
+	p("func (c *%s) %s(ctx context.Context, req *%s.%s, opts ...gax.CallOption) *%s {",
+		lowcaseServName, g.methodName(m), inSpec.Name, inType.GetName(), ut.name)
+	g.appendCallOpts(m)
+	p("u := &%s{ctx: ctx}", ut.name)
+	p("u.call = func(ctx context.Context) %s {", results)
+	g.initializeAutoPopulatedFields(servName, m)
+
+	metadata := "nil"
+	if info.body != "" {
+		verb := strings.ToUpper(info.verb)
+		if verb == http.MethodGet || verb == http.MethodDelete {
+			return fmt.Errorf("invalid use of body parameter for a get/delete method %q", m.GetName())
+		}
+		g.protoJSONMarshaler()
+		requestObject := "req"
+		if info.body != "*" {
+			requestObject = "body"
+			p("body := req%s", fieldGetter(info.body))
+		}
+		p("jsonReq, err := m.Marshal(%s)", requestObject)
+		p("if err != nil {")
+		p("  %s", retErr)
+		p("}")
+		p("")
+		metadata = "jsonReq"
+		g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/encoding/protojson"}] = true
+	}
+
+	g.generateBaseURL(info, retErr)
+	g.generateQueryString(m)
+	p("// Build HTTP headers from client and context metadata.")
+	g.insertRequestHeaders(m, rest)
+	g.injectTelemetryContext(m, info)
+
+	assign := "buf, err :="
+	if ut.respTyp == "" {
+		assign = "_, err ="
+	}
+	p("%s u.do(ctx, &uploadRequest{", assign)
+	p("  client:        c.httpClient,")
+	p("  method:        %q,", strings.ToUpper(info.verb))
+	p("  url:           baseUrl,")
+	p("  header:        headers,")
+	p("  metadata:      %s,", metadata)
+	p("  checkResponse: googleapi.CheckResponseWithBody,")
+	p("  invoke: func(ctx context.Context, f func(context.Context) error) error {")
+	p("    return gax.Invoke(ctx, func(ctx context.Context, settings gax.CallSettings) error {")
+	g.returnAttempt("f(ctx)")
+	p("    }, opts...)")
+	p("  },")
+	p("})")
+	if ut.respTyp == "" {
+		p("return err")
+	} else {
+		p("if err != nil {")
+		p("  return nil, err")
+		p("}")
+		p("")
+		p("unm := protojson.UnmarshalOptions{AllowPartial: true, DiscardUnknown: true}")
+		p("resp := &%s{}", strings.TrimPrefix(ut.respTyp, "*"))
+		p("if err := unm.Unmarshal(buf, resp); err != nil {")
+		p("  return nil, err")
+		p("}")
+		p("return resp, nil")
+		g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/encoding/protojson"}] = true
+		g.imports[ut.respImport] = true
+	}
+	p("}")
+	p("return u")
+	p("}")
+	p("")
+
+	g.imports[inSpec] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/googleapi"}] = true
+	return nil
+}
+
+// mediaUploadGRPCCall generates the gRPC implementation of a media upload
+// RPC. Media uploads are an HTTP protocol, so the returned upload helper
+// always fails.
+func (g *generator) mediaUploadGRPCCall(servName string, m *descriptorpb.MethodDescriptorProto) error {
+	inType := g.descInfo.Type[m.GetInputType()]
+	inSpec, err := g.descInfo.ImportSpec(inType)
+	if err != nil {
+		return err
+	}
+	ut, err := g.uploadTypeOf(m)
+	if err != nil {
+		return err
+	}
+	clientKind := "gRPC"
+	switch g.stubTransport {
+	case connect:
+		clientKind = "Connect"
+	case inprocess:
+		clientKind = "in-process"
+	}
+	results, retErr := ut.uploadReturns(fmt.Sprintf(`errors.New("%s media upload is not supported for %s clients")`, m.GetName(), clientKind))
+
+	p := g.printf
+	lowcaseServName := g.grpcClientTypeName(servName)
+	p("func (c *%s) %s(ctx context.Context, req *%s.%s, opts ...gax.CallOption) *%s {",
+		lowcaseServName, g.methodName(m), inSpec.Name, inType.GetName(), ut.name)
+	p("u := &%s{ctx: ctx}", ut.name)
+	p("u.call = func(ctx context.Context) %s {", results)
+	p("  %s", retErr)
+	p("}")
+	p("return u")
+	p("}")
+	p("")
+
+	g.imports[inSpec] = true
+	g.imports[pbinfo.ImportSpec{Path: "errors"}] = true
+	return nil
+}
+
+// genMediaUploads generates the upload helper types collected by the
+// generator, followed by the shared upload protocol implementation.
+func (g *generator) genMediaUploads() error {
+	if len(g.aux.uploads) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(g.aux.uploads))
+	for n := range g.aux.uploads {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		g.uploadHelper(g.aux.uploads[n])
+	}
+
+	return g.genEmbeddedSource(mediaUploadSource)
+}
+
+// uploadHelper generates the upload helper type for a media upload RPC.
+func (g *generator) uploadHelper(ut *uploadType) {
+	p := g.printf
+	results, _ := ut.uploadReturns("")
+
+	p("// %s manages the media upload of a single RPC call. Configure the", ut.name)
+	p("// upload with its setter methods, then call Do to perform it.")
+	p("type %s struct {", ut.name)
+	p("  mediaUpload")
+	p("  ctx  context.Context")
+	p("  call func(context.Context) %s", results)
+	p("}")
+	p("")
+
+	p("// Media sets the content to upload and its MIME type. If contentType is")
+	p("// empty, it is detected from the first 512 bytes of r.")
+	p("func (u *%s) Media(r io.Reader, contentType string) *%[1]s {", ut.name)
+	p("  u.media = r")
+	p("  u.contentType = contentType")
+	p("  return u")
+	p("}")
+	p("")
+
+	p("// ChunkSize selects a resumable upload, sending the media in chunks of the")
+	p("// given size in bytes, rounded up to a multiple of 256 KiB. By default, media")
+	p("// of up to 16 MiB is uploaded in a single request, and larger media in")
+	p("// resumable chunks of 16 MiB.")
+	p("func (u *%s) ChunkSize(size int) *%[1]s {", ut.name)
+	p("  u.chunkSize = size")
+	p("  return u")
+	p("}")
+	p("")
+
+	p("// ProgressUpdater sets a function that is called with the number of bytes")
+	p("// uploaded so far and the total size of the media, or zero while the total")
+	p("// size is not yet known.")
+	p("func (u *%s) ProgressUpdater(f func(current, total int64)) *%[1]s {", ut.name)
+	p("  u.progress = f")
+	p("  return u")
+	p("}")
+	p("")
+
+	p("// Resume continues the resumable upload session with the given URI, as")
+	p("// reported by SessionURI. The media must be provided from its beginning,")
+	p("// the bytes already persisted by the service are skipped.")
+	p("func (u *%s) Resume(sessionURI string) *%[1]s {", ut.name)
+	p("  u.sessionURI = sessionURI")
+	p("  return u")
+	p("}")
+	p("")
+
+	p("// SessionURI returns the URI of the resumable upload session once one has")
+	p("// been started by Do. It can be used to Resume an interrupted upload.")
+	p("func (u *%s) SessionURI() string {", ut.name)
+	p("  return u.sessionURI")
+	p("}")
+	p("")
+
+	if ut.respTyp == "" {
+		p("// Do uploads the media.")
+	} else {
+		p("// Do uploads the media and returns the RPC response.")
+	}
+	p("func (u *%s) Do() %s {", ut.name, results)
+	p("  return u.call(u.ctx)")
+	p("}")
+	p("")
+
+	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "io"}] = true
+	if ut.respTyp != "" {
+		g.imports[ut.respImport] = true
+	}
 }
@@ -0,0 +1,265 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	longrunning "cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/api/serviceconfig"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestIsMediaUpload(t *testing.T) {
+	httpOpts := func(rule *annotations.HttpRule) *descriptorpb.MethodOptions {
+		o := &descriptorpb.MethodOptions{}
+		proto.SetExtension(o, annotations.E_Http, rule)
+		return o
+	}
+	uploadRule := &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/upload/v1/{parent=projects/*}/things"},
+		Body:    "thing",
+	}
+	plainRule := &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/v1/{parent=projects/*}/things"},
+		Body:    "thing",
+	}
+	lroOpts := httpOpts(uploadRule)
+	proto.SetExtension(lroOpts, longrunning.E_OperationInfo, &longrunning.OperationInfo{
+		ResponseType: "Thing",
+		MetadataType: "Thing",
+	})
+
+	serv := &descriptorpb.ServiceDescriptorProto{Name: proto.String("ThingService")}
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("my.pkg"),
+		Service: []*descriptorpb.ServiceDescriptorProto{serv},
+	}
+
+	for _, tst := range []struct {
+		name     string
+		method   *descriptorpb.MethodDescriptorProto
+		rules    []*annotations.HttpRule
+		disabled bool
+		wantURL  string
+	}{
+		{
+			name:    "annotation",
+			method:  &descriptorpb.MethodDescriptorProto{Options: httpOpts(uploadRule)},
+			wantURL: "/upload/v1/{parent=projects/*}/things",
+		},
+		{
+			name:     "feature disabled",
+			method:   &descriptorpb.MethodDescriptorProto{Options: httpOpts(uploadRule)},
+			disabled: true,
+		},
+		{
+			name: "additional binding",
+			method: &descriptorpb.MethodDescriptorProto{Options: httpOpts(&annotations.HttpRule{
+				Pattern:            &annotations.HttpRule_Post{Post: "/v1/things"},
+				AdditionalBindings: []*annotations.HttpRule{uploadRule},
+			})},
+			wantURL: "/upload/v1/{parent=projects/*}/things",
+		},
+		{
+			name:   "service config",
+			method: &descriptorpb.MethodDescriptorProto{Options: httpOpts(plainRule)},
+			rules: []*annotations.HttpRule{
+				{
+					Selector: "my.pkg.ThingService.CreateThing",
+					Pattern:  &annotations.HttpRule_Put{Put: "/upload/v1/things/{name=*}"},
+				},
+			},
+			wantURL: "/upload/v1/things/{name=*}",
+		},
+		{
+			name:   "service config other method",
+			method: &descriptorpb.MethodDescriptorProto{Options: httpOpts(plainRule)},
+			rules: []*annotations.HttpRule{
+				{
+					Selector: "my.pkg.ThingService.OtherThing",
+					Pattern:  &annotations.HttpRule_Put{Put: "/upload/v1/things/{name=*}"},
+				},
+			},
+		},
+		{
+			name:   "not an upload",
+			method: &descriptorpb.MethodDescriptorProto{Options: httpOpts(plainRule)},
+		},
+		{
+			name:   "no http annotation",
+			method: &descriptorpb.MethodDescriptorProto{},
+		},
+		{
+			name: "longrunning",
+			method: &descriptorpb.MethodDescriptorProto{
+				OutputType: proto.String(operationType),
+				Options:    lroOpts,
+			},
+		},
+		{
+			name: "streaming",
+			method: &descriptorpb.MethodDescriptorProto{
+				ClientStreaming: proto.Bool(true),
+				Options:         httpOpts(uploadRule),
+			},
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			tst.method.Name = proto.String("CreateThing")
+			g := &generator{
+				cfg: &generatorConfig{
+					featureEnablement: map[featureID]struct{}{MediaUploadFeature: {}},
+					APIServiceConfig: &serviceconfig.Service{
+						Http: &annotations.Http{Rules: tst.rules},
+					},
+				},
+				descInfo: pbinfo.Info{
+					ParentElement: map[pbinfo.ProtoType]pbinfo.ProtoType{tst.method: serv},
+					ParentFile:    map[protoreflect.ProtoMessage]*descriptorpb.FileDescriptorProto{serv: file},
+				},
+			}
+			if tst.disabled {
+				g.cfg.featureEnablement = nil
+			}
+
+			if got, want := g.isMediaUpload(tst.method), tst.wantURL != ""; got != want {
+				t.Errorf("isMediaUpload() = %v, want %v", got, want)
+			}
+			if tst.wantURL == "" {
+				return
+			}
+			if got := g.mediaUploadInfo(tst.method).url; got != tst.wantURL {
+				t.Errorf("mediaUploadInfo().url = %q, want %q", got, tst.wantURL)
+			}
+		})
+	}
+}
+
+func TestMediaUploadGRPCCall(t *testing.T) {
+	inputType := &descriptorpb.DescriptorProto{Name: proto.String("InputType")}
+	outputType := &descriptorpb.DescriptorProto{Name: proto.String("OutputType")}
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("my.pkg"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("mypackage"),
+		},
+	}
+	opts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(opts, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Post{Post: "/upload/v1/things"},
+		Body:    "*",
+	})
+	m := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("UploadThings"),
+		InputType:  proto.String(".my.pkg.InputType"),
+		OutputType: proto.String(".my.pkg.OutputType"),
+		Options:    opts,
+	}
+
+	g := &generator{
+		imports: map[pbinfo.ImportSpec]bool{},
+		aux:     &auxTypes{uploads: map[string]*uploadType{}},
+		cfg: &generatorConfig{
+			featureEnablement: map[featureID]struct{}{MediaUploadFeature: {}},
+		},
+		descInfo: pbinfo.Info{
+			Type: map[string]pbinfo.ProtoType{
+				".my.pkg.InputType":  inputType,
+				".my.pkg.OutputType": outputType,
+			},
+			ParentFile: map[protoreflect.ProtoMessage]*descriptorpb.FileDescriptorProto{
+				inputType:  file,
+				outputType: file,
+			},
+		},
+	}
+
+	if err := g.genGRPCMethod("Foo", &descriptorpb.ServiceDescriptorProto{}, m); err != nil {
+		t.Fatal(err)
+	}
+
+	wantImports := map[pbinfo.ImportSpec]bool{
+		{Path: "errors"}:                         true,
+		{Name: "mypackagepb", Path: "mypackage"}: true,
+	}
+	if diff := cmp.Diff(g.imports, wantImports); diff != "" {
+		t.Errorf("imports got(-),want(+):\n%s", diff)
+	}
+	want := &uploadType{
+		name:       "UploadThingsUpload",
+		respTyp:    "*mypackagepb.OutputType",
+		respImport: pbinfo.ImportSpec{Name: "mypackagepb", Path: "mypackage"},
+	}
+	if diff := cmp.Diff(g.aux.uploads["UploadThingsUpload"], want, cmp.AllowUnexported(uploadType{})); diff != "" {
+		t.Errorf("upload types got(-),want(+):\n%s", diff)
+	}
+	txtdiff.Diff(t, g.pt.String(), filepath.Join("testdata", "method_UploadThings.want"))
+}
+
+func TestGenMediaUploads(t *testing.T) {
+	g := &generator{
+		aux: &auxTypes{
+			uploads: map[string]*uploadType{
+				"UploadFooUpload": {
+					name:       "UploadFooUpload",
+					respTyp:    "*examplepb.Foo",
+					respImport: pbinfo.ImportSpec{Name: "examplepb", Path: "cloud.google.com/go/example/apiv1/examplepb"},
+				},
+				"DeleteAndUploadUpload": {
+					name: "DeleteAndUploadUpload",
+				},
+			},
+		},
+		imports: make(map[pbinfo.ImportSpec]bool),
+		cfg:     &generatorConfig{},
+	}
+
+	if err := g.genMediaUploads(); err != nil {
+		t.Fatal(err)
+	}
+	got := g.pt.String()
+
+	// The upload helper types are followed by the shared implementation.
+	impl := strings.Index(got, "const (")
+	if impl < 0 {
+		t.Fatalf("upload implementation was not emitted:\n%s", got)
+	}
+	txtdiff.Diff(t, got[:impl], filepath.Join("testdata", "gen_media_uploads.want"))
+
+	src := "package foo\n\n" + got
+	if _, err := parser.ParseFile(token.NewFileSet(), "auxiliary.go", src, parser.AllErrors); err != nil {
+		t.Errorf("generated upload code does not parse: %v", err)
+	}
+
+	for _, path := range []string{"bufio", "context", "io", "mime/multipart", "net/http", "net/textproto", "net/url"} {
+		if !g.imports[pbinfo.ImportSpec{Path: path}] {
+			t.Errorf("missing import %q", path)
+		}
+	}
+	if !g.imports[pbinfo.ImportSpec{Name: "examplepb", Path: "cloud.google.com/go/example/apiv1/examplepb"}] {
+		t.Error("missing import of the response type")
+	}
+}
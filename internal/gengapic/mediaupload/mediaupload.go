@@ -0,0 +1,437 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mediaupload contains the media upload implementation shared by the
+// upload helpers of generated REST clients.
+//
+// The declarations following the import block are copied verbatim into the
+// generated auxiliary.go of any client package that has a media upload RPC,
+// so they must depend only on the standard library and must not reference
+// anything declared outside of this file.
+package mediaupload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minUploadChunkSize is the granularity that resumable upload chunks must
+	// be a multiple of, with the exception of the final chunk.
+	minUploadChunkSize = 256 * 1024
+
+	// defaultUploadChunkSize is the chunk size used when resuming an upload
+	// without an explicitly configured chunk size.
+	defaultUploadChunkSize = 16 * 1024 * 1024
+
+	// resumableUploadThreshold is the size above which media is uploaded in
+	// resumable chunks, even without a configured chunk size, so that no more
+	// than a chunk of it is held in memory.
+	resumableUploadThreshold = defaultUploadChunkSize
+
+	// maxStalledChunks is the number of consecutive chunks of which the server
+	// persists no bytes before a resumable upload is abandoned.
+	maxStalledChunks = 5
+
+	// statusResumeIncomplete is returned by the server for every resumable
+	// upload request that did not complete the upload.
+	statusResumeIncomplete = http.StatusPermanentRedirect
+)
+
+// mediaUpload holds the caller supplied configuration of a media upload. It
+// is embedded in every generated upload helper type.
+type mediaUpload struct {
+	media       io.Reader
+	contentType string
+	chunkSize   int
+	progress    func(current, total int64)
+	sessionURI  string
+}
+
+// uploadRequest describes the RPC that the media is uploaded with.
+type uploadRequest struct {
+	client *http.Client
+
+	// method and url are the HTTP verb and the fully resolved upload URL,
+	// including the query string, of the RPC.
+	method string
+	url    *url.URL
+	header http.Header
+
+	// metadata is the JSON encoded RPC request body, or nil if the RPC does
+	// not send a request body.
+	metadata []byte
+
+	// checkResponse converts an unsuccessful HTTP response into an error.
+	checkResponse func(*http.Response, []byte) error
+
+	// invoke calls f, retrying it according to the RPC's call options.
+	invoke func(ctx context.Context, f func(context.Context) error) error
+}
+
+// do uploads the media and returns the body of the final HTTP response.
+//
+// A resumable upload is used if a chunk size or session URI is configured, or
+// if the media is larger than resumableUploadThreshold. Otherwise the media is
+// sent in a single request, multipart if the RPC has request metadata to send
+// alongside it.
+func (u *mediaUpload) do(ctx context.Context, r *uploadRequest) ([]byte, error) {
+	if u.media == nil {
+		return nil, errors.New("media upload: no media provided")
+	}
+	if u.contentType == "" {
+		u.media, u.contentType = detectContentType(u.media)
+	}
+	if u.chunkSize > 0 || u.sessionURI != "" {
+		return u.doResumable(ctx, r)
+	}
+
+	// Media sent in a single request is held in memory to be sent again by
+	// retries, so only up to the threshold of it is read.
+	data, err := io.ReadAll(io.LimitReader(u.media, resumableUploadThreshold+1))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(data) > resumableUploadThreshold:
+		u.media = io.MultiReader(bytes.NewReader(data), u.media)
+		return u.doResumable(ctx, r)
+	case r.metadata != nil:
+		return u.doMultipart(ctx, r, data)
+	default:
+		return u.doSimple(ctx, r, data)
+	}
+}
+
+func (u *mediaUpload) doSimple(ctx context.Context, r *uploadRequest, data []byte) ([]byte, error) {
+	var buf []byte
+	err := r.invoke(ctx, func(ctx context.Context) error {
+		req, err := r.newRequest(ctx, r.method, r.uploadURL("media"), bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", u.contentType)
+		_, buf, err = r.send(req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.report(int64(len(data)), int64(len(data)))
+	return buf, nil
+}
+
+// doMultipart sends the metadata and media in a multipart request, whose body
+// is streamed by a pipe rather than assembled in memory.
+func (u *mediaUpload) doMultipart(ctx context.Context, r *uploadRequest, data []byte) ([]byte, error) {
+	var buf []byte
+	err := r.invoke(ctx, func(ctx context.Context) error {
+		pr, pw := io.Pipe()
+		mw := multipart.NewWriter(pw)
+		go func() {
+			pw.CloseWithError(writeMultipart(mw, r.metadata, u.contentType, data))
+		}()
+		req, err := r.newRequest(ctx, r.method, r.uploadURL("multipart"), pr)
+		if err != nil {
+			pr.Close()
+			return err
+		}
+		req.Header.Set("Content-Type", "multipart/related; boundary="+mw.Boundary())
+		_, buf, err = r.send(req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.report(int64(len(data)), int64(len(data)))
+	return buf, nil
+}
+
+// writeMultipart writes the metadata and media parts of a multipart upload
+// to mw, and closes it.
+func writeMultipart(mw *multipart.Writer, metadata []byte, contentType string, media []byte) error {
+	metaPart, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json; charset=UTF-8"}})
+	if err != nil {
+		return err
+	}
+	if _, err := metaPart.Write(metadata); err != nil {
+		return err
+	}
+	mediaPart, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {contentType}})
+	if err != nil {
+		return err
+	}
+	if _, err := mediaPart.Write(media); err != nil {
+		return err
+	}
+	return mw.Close()
+}
+
+func (u *mediaUpload) doResumable(ctx context.Context, r *uploadRequest) ([]byte, error) {
+	var offset int64
+	if u.sessionURI == "" {
+		if err := u.startSession(ctx, r); err != nil {
+			return nil, err
+		}
+	} else {
+		committed, buf, done, err := u.querySession(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			return buf, nil
+		}
+		// The media is read from its beginning, skip what the server has
+		// already persisted.
+		if _, err := io.CopyN(io.Discard, u.media, committed); err != nil {
+			return nil, fmt.Errorf("media upload: skipping %d bytes persisted by the server: %w", committed, err)
+		}
+		offset = committed
+	}
+
+	size := u.uploadChunkSize()
+	src := bufio.NewReader(u.media)
+	chunk := make([]byte, size)
+	// pending is the number of bytes at the start of chunk that were sent but
+	// not persisted by the server, to be sent again.
+	pending := 0
+	stalled := 0
+	for {
+		n, err := io.ReadFull(src, chunk[pending:])
+		n += pending
+		final := false
+		switch {
+		case err == io.EOF || err == io.ErrUnexpectedEOF:
+			final = true
+		case err != nil:
+			return nil, err
+		default:
+			if _, err := src.Peek(1); err == io.EOF {
+				final = true
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		var total int64
+		contentRange := fmt.Sprintf("bytes %d-%d/*", offset, offset+int64(n)-1)
+		if final {
+			total = offset + int64(n)
+			contentRange = fmt.Sprintf("bytes %d-%d/%d", offset, total-1, total)
+			if n == 0 {
+				contentRange = fmt.Sprintf("bytes */%d", total)
+			}
+		}
+
+		var resp *http.Response
+		var buf []byte
+		err = r.invoke(ctx, func(ctx context.Context) error {
+			req, err := r.newRequest(ctx, http.MethodPut, u.sessionURI, bytes.NewReader(chunk[:n]))
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Content-Range", contentRange)
+			req.Header.Set("Content-Type", u.contentType)
+			resp, buf, err = r.send(req)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != statusResumeIncomplete {
+			u.report(offset+int64(n), offset+int64(n))
+			return buf, nil
+		}
+
+		committed, err := committedBytes(resp)
+		if err != nil {
+			return nil, err
+		}
+		if committed < offset || committed > offset+int64(n) {
+			return nil, fmt.Errorf("media upload: server persisted %d bytes, want between %d and %d", committed, offset, offset+int64(n))
+		}
+		u.report(committed, total)
+		if committed == offset+int64(n) && final {
+			return nil, errors.New("media upload: server did not finalize the upload")
+		}
+		if committed == offset {
+			stalled++
+			if stalled == maxStalledChunks {
+				return nil, fmt.Errorf("media upload: server persisted no bytes of %d consecutive chunks at offset %d", stalled, offset)
+			}
+		} else {
+			stalled = 0
+		}
+		// Resend whatever part of the chunk the server did not persist.
+		pending = copy(chunk, chunk[committed-offset:n])
+		offset = committed
+	}
+}
+
+// startSession initiates a resumable upload session.
+func (u *mediaUpload) startSession(ctx context.Context, r *uploadRequest) error {
+	return r.invoke(ctx, func(ctx context.Context) error {
+		req, err := r.newRequest(ctx, r.method, r.uploadURL("resumable"), bytes.NewReader(r.metadata))
+		if err != nil {
+			return err
+		}
+		if r.metadata != nil {
+			req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+		} else {
+			req.Header.Del("Content-Type")
+		}
+		req.Header.Set("X-Upload-Content-Type", u.contentType)
+		resp, _, err := r.send(req)
+		if err != nil {
+			return err
+		}
+		loc := resp.Header.Get("Location")
+		if loc == "" {
+			return errors.New("media upload: server did not return a resumable session URI")
+		}
+		u.sessionURI = loc
+		return nil
+	})
+}
+
+// querySession asks the server how much of the media it has persisted. If the
+// upload already completed, done is true and buf holds the final response.
+func (u *mediaUpload) querySession(ctx context.Context, r *uploadRequest) (committed int64, buf []byte, done bool, err error) {
+	var resp *http.Response
+	err = r.invoke(ctx, func(ctx context.Context) error {
+		req, err := r.newRequest(ctx, http.MethodPut, u.sessionURI, http.NoBody)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Range", "bytes */*")
+		resp, buf, err = r.send(req)
+		return err
+	})
+	if err != nil {
+		return 0, nil, false, err
+	}
+	if resp.StatusCode != statusResumeIncomplete {
+		return 0, buf, true, nil
+	}
+	committed, err = committedBytes(resp)
+	return committed, nil, false, err
+}
+
+// uploadChunkSize returns the configured chunk size rounded up to a multiple
+// of minUploadChunkSize.
+func (u *mediaUpload) uploadChunkSize() int {
+	size := u.chunkSize
+	if size <= 0 {
+		size = defaultUploadChunkSize
+	}
+	if rem := size % minUploadChunkSize; rem != 0 {
+		size += minUploadChunkSize - rem
+	}
+	return size
+}
+
+func (u *mediaUpload) report(current, total int64) {
+	if u.progress != nil {
+		u.progress(current, total)
+	}
+}
+
+// uploadURL returns the RPC URL with the given upload protocol selected.
+func (r *uploadRequest) uploadURL(uploadType string) string {
+	uploadURL := *r.url
+	params := uploadURL.Query()
+	params.Set("uploadType", uploadType)
+	uploadURL.RawQuery = params.Encode()
+	return uploadURL.String()
+}
+
+func (r *uploadRequest) newRequest(ctx context.Context, method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	return req, nil
+}
+
+// send executes req, treating an incomplete resumable upload as success.
+func (r *uploadRequest) send(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == statusResumeIncomplete {
+		return resp, buf, nil
+	}
+	if err := r.checkResponse(resp, buf); err != nil {
+		return nil, nil, err
+	}
+	return resp, buf, nil
+}
+
+// committedBytes parses the number of bytes persisted by the server from the
+// Range header of an incomplete resumable upload response.
+func committedBytes(resp *http.Response) (int64, error) {
+	rng := resp.Header.Get("Range")
+	if rng == "" {
+		return 0, nil
+	}
+	last, ok := strings.CutPrefix(rng, "bytes=0-")
+	if !ok {
+		return 0, fmt.Errorf("media upload: unexpected Range header %q", rng)
+	}
+	n, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("media upload: unexpected Range header %q", rng)
+	}
+	return n + 1, nil
+}
+
+// detectContentType sniffs the MIME type of the media, returning a reader
+// that still yields the media in its entirety.
+func detectContentType(media io.Reader) (io.Reader, string) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(media, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return io.MultiReader(bytes.NewReader(buf[:n]), errReader{err}), "application/octet-stream"
+	}
+	return io.MultiReader(bytes.NewReader(buf[:n]), media), http.DetectContentType(buf[:n])
+}
+
+// errReader returns err from every call to Read.
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
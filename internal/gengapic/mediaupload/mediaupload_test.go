@@ -0,0 +1,427 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mediaupload
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeUploadServer is a minimal stand-in for the resumable upload protocol.
+type fakeUploadServer struct {
+	mu sync.Mutex
+
+	// data is the media persisted by the current session.
+	data []byte
+	// finalized is set once the client has sent the final chunk.
+	finalized bool
+	// failChunk, when non-zero, is the 1-based index of the chunk request
+	// that fails with a 503 before any of its bytes are persisted.
+	failChunk int
+	// partial, when set, causes every chunk to only persist half of its bytes.
+	partial bool
+	// stalled, when set, causes no chunk to persist any of its bytes.
+	stalled bool
+
+	chunks   int
+	requests []*http.Request
+}
+
+var contentRangeRegex = regexp.MustCompile(`^bytes (\d+)-(\d+)/(\d+|\*)$`)
+
+func (s *fakeUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+
+	if r.URL.Path != "/session" {
+		switch r.URL.Query().Get("uploadType") {
+		case "resumable":
+			w.Header().Set("Location", "http://"+r.Host+"/session")
+			w.WriteHeader(http.StatusOK)
+		case "media", "multipart":
+			body, _ := io.ReadAll(r.Body)
+			s.data = body
+			s.finalized = true
+			fmt.Fprint(w, `{"name":"done"}`)
+		default:
+			http.Error(w, "unknown uploadType", http.StatusBadRequest)
+		}
+		return
+	}
+
+	cr := r.Header.Get("Content-Range")
+	if cr == "bytes */*" {
+		if s.finalized {
+			fmt.Fprint(w, `{"name":"done"}`)
+			return
+		}
+		s.incomplete(w)
+		return
+	}
+	if cr == fmt.Sprintf("bytes */%d", len(s.data)) {
+		s.finalized = true
+		fmt.Fprint(w, `{"name":"done"}`)
+		return
+	}
+
+	s.chunks++
+	if s.chunks == s.failChunk {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	m := contentRangeRegex.FindStringSubmatch(cr)
+	if m == nil {
+		http.Error(w, "bad Content-Range "+cr, http.StatusBadRequest)
+		return
+	}
+	start, _ := strconv.Atoi(m[1])
+	body, _ := io.ReadAll(r.Body)
+	if start > len(s.data) {
+		http.Error(w, "non-contiguous chunk", http.StatusBadRequest)
+		return
+	}
+	keep := len(body)
+	if s.partial && keep > 1 {
+		keep /= 2
+	}
+	if s.stalled {
+		keep = 0
+	}
+	s.data = append(s.data[:start], body[:keep]...)
+	if m[3] != "*" && keep == len(body) {
+		s.finalized = true
+		fmt.Fprint(w, `{"name":"done"}`)
+		return
+	}
+	s.incomplete(w)
+}
+
+func (s *fakeUploadServer) incomplete(w http.ResponseWriter) {
+	if len(s.data) > 0 {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(s.data)-1))
+	}
+	w.WriteHeader(statusResumeIncomplete)
+}
+
+func newUploadRequest(t *testing.T, srv *httptest.Server, metadata []byte) *uploadRequest {
+	t.Helper()
+	u, err := url.Parse(srv.URL + "/upload/v1/things")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &uploadRequest{
+		client:   srv.Client(),
+		method:   http.MethodPost,
+		url:      u,
+		header:   http.Header{"Content-Type": {"application/json"}},
+		metadata: metadata,
+		checkResponse: func(resp *http.Response, _ []byte) error {
+			if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+				return nil
+			}
+			return fmt.Errorf("status %d", resp.StatusCode)
+		},
+		invoke: func(ctx context.Context, f func(context.Context) error) error {
+			return f(ctx)
+		},
+	}
+}
+
+func testMedia(size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func TestSimpleUpload(t *testing.T) {
+	fake := &fakeUploadServer{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	media := testMedia(1000)
+	var gotCurrent, gotTotal int64
+	u := &mediaUpload{
+		media:       bytes.NewReader(media),
+		contentType: "application/octet-stream",
+		progress:    func(current, total int64) { gotCurrent, gotTotal = current, total },
+	}
+	buf, err := u.do(context.Background(), newUploadRequest(t, srv, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != `{"name":"done"}` {
+		t.Errorf("got response %q", buf)
+	}
+	if !bytes.Equal(fake.data, media) {
+		t.Errorf("server received %d bytes, want %d", len(fake.data), len(media))
+	}
+	if got := fake.requests[0].Header.Get("Content-Type"); got != "application/octet-stream" {
+		t.Errorf("got Content-Type %q", got)
+	}
+	if gotCurrent != 1000 || gotTotal != 1000 {
+		t.Errorf("got progress (%d, %d), want (1000, 1000)", gotCurrent, gotTotal)
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	fake := &fakeUploadServer{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	media := []byte("hello, world")
+	metadata := []byte(`{"name":"things/1"}`)
+	u := &mediaUpload{media: bytes.NewReader(media)}
+	if _, err := u.do(context.Background(), newUploadRequest(t, srv, metadata)); err != nil {
+		t.Fatal(err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(fake.requests[0].Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType != "multipart/related" {
+		t.Fatalf("got media type %q, want multipart/related", mediaType)
+	}
+	mr := multipart.NewReader(bytes.NewReader(fake.data), params["boundary"])
+	for i, want := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"application/json; charset=UTF-8", metadata},
+		// The content type was sniffed from the media.
+		{"text/plain; charset=utf-8", media},
+	} {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("part %d: got Content-Type %q, want %q", i, got, want.contentType)
+		}
+		if got, _ := io.ReadAll(part); !bytes.Equal(got, want.body) {
+			t.Errorf("part %d: got %q, want %q", i, got, want.body)
+		}
+	}
+}
+
+func TestLargeUploadIsResumable(t *testing.T) {
+	for _, tst := range []struct {
+		name     string
+		metadata []byte
+	}{
+		{name: "simple"},
+		{name: "multipart", metadata: []byte(`{"name":"things/1"}`)},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			fake := &fakeUploadServer{}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			// Without a chunk size, media above the threshold is still
+			// uploaded in chunks of the default size.
+			media := testMedia(resumableUploadThreshold + 100)
+			u := &mediaUpload{media: bytes.NewReader(media), contentType: "application/octet-stream"}
+			if _, err := u.do(context.Background(), newUploadRequest(t, srv, tst.metadata)); err != nil {
+				t.Fatal(err)
+			}
+			if got := fake.requests[0].URL.Query().Get("uploadType"); got != "resumable" {
+				t.Errorf("got uploadType %q, want resumable", got)
+			}
+			if fake.chunks != 2 {
+				t.Errorf("got %d chunks, want 2", fake.chunks)
+			}
+			if !fake.finalized || !bytes.Equal(fake.data, media) {
+				t.Errorf("server persisted %d bytes (finalized: %v), want %d", len(fake.data), fake.finalized, len(media))
+			}
+		})
+	}
+}
+
+func TestResumableUpload(t *testing.T) {
+	for _, tst := range []struct {
+		name    string
+		size    int
+		partial bool
+	}{
+		{name: "multiple chunks", size: 2*minUploadChunkSize + 100},
+		{name: "exact chunks", size: 2 * minUploadChunkSize},
+		{name: "single chunk", size: 10},
+		{name: "empty", size: 0},
+		{name: "partially persisted chunks", size: 2*minUploadChunkSize + 100, partial: true},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			fake := &fakeUploadServer{partial: tst.partial}
+			srv := httptest.NewServer(fake)
+			defer srv.Close()
+
+			media := testMedia(tst.size)
+			var progress [][2]int64
+			u := &mediaUpload{
+				media:       bytes.NewReader(media),
+				contentType: "application/octet-stream",
+				chunkSize:   1, // Rounded up to minUploadChunkSize.
+				progress:    func(current, total int64) { progress = append(progress, [2]int64{current, total}) },
+			}
+			buf, err := u.do(context.Background(), newUploadRequest(t, srv, []byte(`{}`)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != `{"name":"done"}` {
+				t.Errorf("got response %q", buf)
+			}
+			if !fake.finalized || !bytes.Equal(fake.data, media) {
+				t.Errorf("server persisted %d bytes (finalized: %v), want %d", len(fake.data), fake.finalized, len(media))
+			}
+			if got := fake.requests[0].Header.Get("X-Upload-Content-Type"); got != "application/octet-stream" {
+				t.Errorf("got X-Upload-Content-Type %q", got)
+			}
+			if u.sessionURI != srv.URL+"/session" {
+				t.Errorf("got session URI %q", u.sessionURI)
+			}
+			if len(progress) == 0 {
+				t.Fatal("progress was never reported")
+			}
+			if last := progress[len(progress)-1]; last != [2]int64{int64(tst.size), int64(tst.size)} {
+				t.Errorf("got final progress %v, want [%d %d]", last, tst.size, tst.size)
+			}
+			for i := 1; i < len(progress); i++ {
+				if progress[i][0] < progress[i-1][0] {
+					t.Errorf("progress went backwards: %v", progress)
+				}
+			}
+		})
+	}
+}
+
+func TestResumableUpload_Resume(t *testing.T) {
+	fake := &fakeUploadServer{failChunk: 2}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	media := testMedia(3*minUploadChunkSize + 7)
+	u := &mediaUpload{
+		media:       bytes.NewReader(media),
+		contentType: "application/octet-stream",
+		chunkSize:   minUploadChunkSize,
+	}
+	if _, err := u.do(context.Background(), newUploadRequest(t, srv, nil)); err == nil {
+		t.Fatal("expected the interrupted upload to fail")
+	}
+	if len(fake.data) != minUploadChunkSize {
+		t.Fatalf("server persisted %d bytes before the failure, want %d", len(fake.data), minUploadChunkSize)
+	}
+
+	// Resume in a "new process" from the session URI alone, supplying the
+	// media from its beginning.
+	resumed := &mediaUpload{
+		media:       bytes.NewReader(media),
+		contentType: "application/octet-stream",
+		sessionURI:  u.sessionURI,
+	}
+	before := len(fake.requests)
+	buf, err := resumed.do(context.Background(), newUploadRequest(t, srv, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != `{"name":"done"}` {
+		t.Errorf("got response %q", buf)
+	}
+	if !fake.finalized || !bytes.Equal(fake.data, media) {
+		t.Errorf("server persisted %d bytes (finalized: %v), want %d", len(fake.data), fake.finalized, len(media))
+	}
+	for _, r := range fake.requests[before:] {
+		if r.URL.Path != "/session" {
+			t.Errorf("resumed upload started a new session: %s %s", r.Method, r.URL)
+		}
+	}
+
+	// Resuming a completed upload returns the final response without
+	// sending any media.
+	done := &mediaUpload{media: bytes.NewReader(media), contentType: "application/octet-stream", sessionURI: u.sessionURI}
+	before = len(fake.requests)
+	buf, err = done.do(context.Background(), newUploadRequest(t, srv, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != `{"name":"done"}` {
+		t.Errorf("got response %q", buf)
+	}
+	if got := len(fake.requests) - before; got != 1 {
+		t.Errorf("resuming a completed upload sent %d requests, want 1", got)
+	}
+}
+
+func TestResumableUpload_Stalled(t *testing.T) {
+	fake := &fakeUploadServer{stalled: true}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	u := &mediaUpload{
+		media:       bytes.NewReader(testMedia(minUploadChunkSize + 7)),
+		contentType: "application/octet-stream",
+		chunkSize:   minUploadChunkSize,
+	}
+	if _, err := u.do(context.Background(), newUploadRequest(t, srv, nil)); err == nil {
+		t.Fatal("expected the stalled upload to fail")
+	}
+	if fake.chunks != maxStalledChunks {
+		t.Errorf("sent %d chunks, want %d", fake.chunks, maxStalledChunks)
+	}
+	for _, r := range fake.requests[1:] {
+		if got, want := r.Header.Get("Content-Range"), fmt.Sprintf("bytes 0-%d/*", minUploadChunkSize-1); got != want {
+			t.Errorf("got Content-Range %q, want %q", got, want)
+		}
+	}
+}
+
+func TestCommittedBytes(t *testing.T) {
+	for _, tst := range []struct {
+		rng     string
+		want    int64
+		wantErr bool
+	}{
+		{rng: "", want: 0},
+		{rng: "bytes=0-0", want: 1},
+		{rng: "bytes=0-262143", want: 262144},
+		{rng: "bytes=10-20", wantErr: true},
+		{rng: "bytes=0-x", wantErr: true},
+	} {
+		resp := &http.Response{Header: http.Header{}}
+		if tst.rng != "" {
+			resp.Header.Set("Range", tst.rng)
+		}
+		got, err := committedBytes(resp)
+		if (err != nil) != tst.wantErr {
+			t.Errorf("committedBytes(%q) error = %v, wantErr %v", tst.rng, err, tst.wantErr)
+		}
+		if got != tst.want {
+			t.Errorf("committedBytes(%q) = %d, want %d", tst.rng, got, tst.want)
+		}
+	}
+}
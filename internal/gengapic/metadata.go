@@ -74,10 +74,10 @@ func (g *generator) addMetadataServiceForTransport(service, transport, lib strin
 	}
 }
 
-// Adds a metadata service transport client method entry for the given RPC.
-// Will exit early if addMetadataServiceEntry or addMetadaServiceForTransport
+// Adds a metadata service transport client method entry for the given RPC,
+// listing the client methods generated for it. Will exit early if addMetadataServiceEntry or addMetadaServiceForTransport
 // are not called prior to this.
-func (g *generator) addMetadataMethod(service, transport, rpc, method string) {
+func (g *generator) addMetadataMethod(service, transport, rpc string, methods ...string) {
 	if g.metadata.Services == nil {
 		return
 	}
@@ -93,6 +93,6 @@ func (g *generator) addMetadataMethod(service, transport, rpc, method string) {
 		c.Rpcs = make(map[string]*metadata.GapicMetadata_MethodList)
 	}
 	c.GetRpcs()[rpc] = &metadata.GapicMetadata_MethodList{
-		Methods: []string{method},
+		Methods: methods,
 	}
 }
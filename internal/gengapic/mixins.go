@@ -17,6 +17,7 @@ package gengapic
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 
 	iam "cloud.google.com/go/iam/apiv1/iampb"
@@ -66,6 +67,20 @@ func (g *generator) collectMixins() {
 	}
 }
 
+// diagnoseMixins records the APIs of the Service config that collectMixins
+// ignores, as they are neither supported mixins nor services of the request.
+func (g *generator) diagnoseMixins() {
+	for _, api := range g.cfg.APIServiceConfig.GetApis() {
+		if _, ok := mixinFiles[api.GetName()]; ok {
+			continue
+		}
+		if _, ok := g.descInfo.Serv["."+api.GetName()]; ok {
+			continue
+		}
+		g.diagnose(unknownMixin, api.GetName(), "API of the service config is neither a supported mixin nor a known service, it is ignored")
+	}
+}
+
 // collectMixinMethods collects the method descriptors for the given mixin API
 // that should be generated for a client. In order for a method to be included
 // for generation, it must have a google.api.http defined in the Service config
@@ -118,8 +133,14 @@ func (g *generator) collectMixinMethods(api string) []*descriptorpb.MethodDescri
 // getMixinFiles returns a set of file descriptors for the APIs configured to be
 // mixed in.
 func (g *generator) getMixinFiles() []*descriptorpb.FileDescriptorProto {
-	files := []*descriptorpb.FileDescriptorProto{}
+	// Sort the mixed in APIs for the files to be in the same order every time.
+	var keys []string
 	for key := range g.mixins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	files := []*descriptorpb.FileDescriptorProto{}
+	for _, key := range keys {
 		files = append(files, mixinFiles[key]...)
 	}
 	return files
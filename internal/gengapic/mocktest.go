@@ -0,0 +1,657 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// genMockTestFile generates tests of the gRPC client of serv against a fake
+// server implementing the service in memory. Every method of the client is
+// called end to end, checking that requests, responses, errors and routing
+// headers go through.
+func (g *generator) genMockTestFile(serv *descriptorpb.ServiceDescriptorProto) error {
+	g.clientProtoPkg = g.descInfo.ParentFile[serv].GetPackage()
+	override := g.getServiceNameOverride(serv)
+	clientName := pbinfo.ReduceServNameWithOverride(serv.GetName(), g.cfg.pkgName, override)
+
+	servSpec, err := g.descInfo.ImportSpec(serv)
+	if err != nil {
+		return err
+	}
+	g.imports[servSpec] = true
+
+	var methods []*descriptorpb.MethodDescriptorProto
+	for _, m := range g.getMethods(serv) {
+		// Internal methods are not exported, and media uploads are only
+		// supported by the REST client.
+		if g.isMethodInternal(m) || g.isMediaUpload(m) {
+			continue
+		}
+		methods = append(methods, m)
+	}
+
+	if err := g.mockServer(serv, servSpec, methods); err != nil {
+		return err
+	}
+	g.mockClientFactory(serv, servSpec, clientName)
+
+	for _, m := range methods {
+		if err := g.mockTests(serv, m); err != nil {
+			return fmt.Errorf("error generating mock tests of %q: %v", m.GetName(), err)
+		}
+	}
+
+	g.imports[pbinfo.ImportSpec{Path: "context"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "testing"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/codes"}] = true
+	g.imports[pbinfo.ImportSpec{Name: "gstatus", Path: "google.golang.org/grpc/status"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/proto"}] = true
+	return nil
+}
+
+func mockServerName(serv *descriptorpb.ServiceDescriptorProto) string {
+	return fmt.Sprintf("mock%sServer", serv.GetName())
+}
+
+// mockServer generates the fake server, recording the requests and metadata
+// it receives and responding with preset responses or error.
+func (g *generator) mockServer(serv *descriptorpb.ServiceDescriptorProto, servSpec pbinfo.ImportSpec, methods []*descriptorpb.MethodDescriptorProto) error {
+	p := g.printf
+	mockName := mockServerName(serv)
+
+	p("type %s struct {", mockName)
+	p("  // Embed for forward compatibility.")
+	p("  // Tests will keep working if more methods are added")
+	p("  // in the future.")
+	p("  %s.%sServer", servSpec.Name, serv.GetName())
+	p("")
+	p("  reqs []proto.Message")
+	p("")
+	p("  // md is the metadata of the last call.")
+	p("  md metadata.MD")
+	p("")
+	p("  // If set, all calls return this error.")
+	p("  err error")
+	p("")
+	p("  // responses to return if err == nil")
+	p("  resps []proto.Message")
+	p("}")
+	p("")
+
+	p("// checkMetadata records the metadata of the call, which must carry the")
+	p("// x-goog-api-client header.")
+	p("func (s *%s) checkMetadata(ctx context.Context) error {", mockName)
+	p("  md, _ := metadata.FromIncomingContext(ctx)")
+	p(`  if xg := md["x-goog-api-client"]; len(xg) == 0 || !strings.Contains(xg[0], "gl-go/") {`)
+	p(`    return fmt.Errorf("x-goog-api-client = %%v, expected gl-go key", xg)`)
+	p("  }")
+	p("  s.md = md")
+	p("  return nil")
+	p("}")
+	p("")
+
+	p("// checkRequestParams checks that the x-goog-request-params header of the")
+	p("// last call contains the given parameters.")
+	p("func (s *%s) checkRequestParams(t *testing.T, want ...string) {", mockName)
+	p("  t.Helper()")
+	p("  got := map[string]bool{}")
+	p(`  for _, v := range s.md.Get("x-goog-request-params") {`)
+	p(`    for _, param := range strings.Split(v, "&") {`)
+	p("      got[param] = true")
+	p("    }")
+	p("  }")
+	p("  for _, w := range want {")
+	p("    if !got[w] {")
+	p(`      t.Errorf("x-goog-request-params = %%q, want it to contain %%q", s.md.Get("x-goog-request-params"), w)`)
+	p("    }")
+	p("  }")
+	p("}")
+	p("")
+
+	for _, m := range methods {
+		inType := g.descInfo.Type[m.GetInputType()]
+		inSpec, err := g.descInfo.ImportSpec(inType)
+		if err != nil {
+			return err
+		}
+		outType := g.descInfo.Type[m.GetOutputType()]
+		outSpec, err := g.descInfo.ImportSpec(outType)
+		if err != nil {
+			return err
+		}
+		g.imports[inSpec] = true
+		g.imports[outSpec] = true
+		reqType := fmt.Sprintf("*%s.%s", inSpec.Name, inType.GetName())
+		respType := fmt.Sprintf("*%s.%s", outSpec.Name, outType.GetName())
+		streamType := fmt.Sprintf("%s.%s_%sServer", servSpec.Name, serv.GetName(), m.GetName())
+
+		g.startRPC(m)
+		switch {
+		case m.GetClientStreaming():
+			p("func (s *%s) %s(stream %s) error {", mockName, m.GetName(), streamType)
+			p("  if err := s.checkMetadata(stream.Context()); err != nil {")
+			p("    return err")
+			p("  }")
+			p("  for {")
+			p("    req, err := stream.Recv()")
+			p("    if err == io.EOF {")
+			p("      break")
+			p("    } else if err != nil {")
+			p("      return err")
+			p("    }")
+			p("    s.reqs = append(s.reqs, req)")
+			p("  }")
+			p("  if s.err != nil {")
+			p("    return s.err")
+			p("  }")
+			if m.GetServerStreaming() {
+				p("  for _, v := range s.resps {")
+				p("    if err := stream.Send(v.(%s)); err != nil {", respType)
+				p("      return err")
+				p("    }")
+				p("  }")
+				p("  return nil")
+			} else {
+				p("  return stream.SendAndClose(s.resps[0].(%s))", respType)
+			}
+			g.imports[pbinfo.ImportSpec{Path: "io"}] = true
+		case m.GetServerStreaming():
+			p("func (s *%s) %s(req %s, stream %s) error {", mockName, m.GetName(), reqType, streamType)
+			p("  if err := s.checkMetadata(stream.Context()); err != nil {")
+			p("    return err")
+			p("  }")
+			p("  s.reqs = append(s.reqs, req)")
+			p("  if s.err != nil {")
+			p("    return s.err")
+			p("  }")
+			p("  for _, v := range s.resps {")
+			p("    if err := stream.Send(v.(%s)); err != nil {", respType)
+			p("      return err")
+			p("    }")
+			p("  }")
+			p("  return nil")
+		default:
+			p("func (s *%s) %s(ctx context.Context, req %s) (%s, error) {", mockName, m.GetName(), reqType, respType)
+			p("  if err := s.checkMetadata(ctx); err != nil {")
+			p("    return nil, err")
+			p("  }")
+			p("  s.reqs = append(s.reqs, req)")
+			p("  if s.err != nil {")
+			p("    return nil, s.err")
+			p("  }")
+			p("  return s.resps[0].(%s), nil", respType)
+		}
+		p("}")
+		p("")
+	}
+
+	g.imports[pbinfo.ImportSpec{Path: "fmt"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "strings"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/metadata"}] = true
+	return nil
+}
+
+// mockClientFactory generates the function starting the fake server and
+// returning a client calling it.
+func (g *generator) mockClientFactory(serv *descriptorpb.ServiceDescriptorProto, servSpec pbinfo.ImportSpec, clientName string) {
+	p := g.printf
+
+	p("// newMock%[1]sClient starts a fake server implementing the %[1]s service,", serv.GetName())
+	p("// and returns it with a client calling it. Both are stopped at the end of the test.")
+	p("func newMock%sClient(t *testing.T) (*%s, *%sClient) {", serv.GetName(), mockServerName(serv), clientName)
+	p("  t.Helper()")
+	p("  mock := &%s{}", mockServerName(serv))
+	p("  serv := grpc.NewServer()")
+	p("  %s.Register%sServer(serv, mock)", servSpec.Name, serv.GetName())
+	p("")
+	p(`  lis, err := net.Listen("tcp", "localhost:0")`)
+	p("  if err != nil {")
+	p("    t.Fatal(err)")
+	p("  }")
+	p("  go serv.Serve(lis)")
+	p("  t.Cleanup(serv.Stop)")
+	p("")
+	p("  c, err := New%sClient(context.Background(),", clientName)
+	p("    option.WithEndpoint(lis.Addr().String()),")
+	p("    option.WithoutAuthentication(),")
+	p("    option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))")
+	p("  if err != nil {")
+	p("    t.Fatal(err)")
+	p("  }")
+	p("  t.Cleanup(func() { c.Close() })")
+	p("  return mock, c")
+	p("}")
+	p("")
+
+	g.imports[pbinfo.ImportSpec{Path: "net"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/option"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/credentials/insecure"}] = true
+}
+
+// mockTests generates the tests of method m, with a successful call and a
+// failing one.
+func (g *generator) mockTests(serv *descriptorpb.ServiceDescriptorProto, m *descriptorpb.MethodDescriptorProto) error {
+	g.startRPC(m)
+	p := g.printf
+
+	inType := g.descInfo.Type[m.GetInputType()].(*descriptorpb.DescriptorProto)
+	inSpec, err := g.descInfo.ImportSpec(inType)
+	if err != nil {
+		return err
+	}
+	outType := g.descInfo.Type[m.GetOutputType()]
+	outSpec, err := g.descInfo.ImportSpec(outType)
+	if err != nil {
+		return err
+	}
+
+	// Only the calls with a request carry routing headers.
+	var fields []*requestField
+	var wantParams []string
+	if !m.GetClientStreaming() {
+		fields, wantParams = g.mockRequestParams(m, inType)
+	}
+
+	pf, _, err := g.getPagingFields(m)
+	if err != nil {
+		return err
+	}
+	isLRO := g.isLRO(m)
+	var ow operationWrapper
+	if isLRO {
+		if err := g.maybeAddOperationWrapper(m); err != nil {
+			return err
+		}
+		ow = g.aux.methodToWrapper[m]
+	}
+
+	testName := fmt.Sprintf("Test%s%s", serv.GetName(), m.GetName())
+	call := fmt.Sprintf("c.%s", m.GetName())
+
+	// Successful call.
+	p("func %s(t *testing.T) {", testName)
+	p("  mock, c := newMock%sClient(t)", serv.GetName())
+	var respVar string
+	switch {
+	case isLRO:
+		respVar = "expectedResponse"
+		if ow.responseName == emptyValue {
+			respVar = ""
+			p("  expectedResponse := &emptypb.Empty{}")
+			g.imports[pbinfo.ImportSpec{Name: "emptypb", Path: "google.golang.org/protobuf/types/known/emptypb"}] = true
+		} else {
+			respSpec, err := g.descInfo.ImportSpec(ow.response)
+			if err != nil {
+				return err
+			}
+			g.imports[respSpec] = true
+			p("  expectedResponse := &%s.%s{}", respSpec.Name, g.nestedName(ow.response))
+		}
+		p("  respAny, err := anypb.New(expectedResponse)")
+		p("  if err != nil {")
+		p("    t.Fatal(err)")
+		p("  }")
+		p("  mock.resps = append(mock.resps[:0], &%s.%s{", outSpec.Name, outType.GetName())
+		p(`    Name: "longrunning-test",`)
+		p("    Done: true,")
+		p("    Result: &%s.Operation_Response{Response: respAny},", outSpec.Name)
+		p("  })")
+		g.imports[pbinfo.ImportSpec{Name: "anypb", Path: "google.golang.org/protobuf/types/known/anypb"}] = true
+	case m.GetOutputType() == emptyType:
+		p("  mock.resps = append(mock.resps[:0], &%s.%s{})", outSpec.Name, outType.GetName())
+	case pf != nil:
+		respVar = "expectedResponse"
+		p("  expectedResponse := &%s.%s{", outSpec.Name, outType.GetName())
+		if !g.isMapField(pf) {
+			it, err := g.iterTypeOf(pf)
+			if err != nil {
+				return err
+			}
+			if pf.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+				p("    %s: []%s{{}},", snakeToCamel(pf.GetName()), it.elemTypeName)
+			} else {
+				p("    %s: make([]%s, 1),", snakeToCamel(pf.GetName()), it.elemTypeName)
+			}
+		}
+		p("  }")
+		p("  mock.resps = append(mock.resps[:0], expectedResponse)")
+	default:
+		respVar = "expectedResponse"
+		p("  expectedResponse := &%s.%s{}", outSpec.Name, outType.GetName())
+		p("  mock.resps = append(mock.resps[:0], expectedResponse)")
+	}
+	p("")
+	g.mockRequest(inSpec, inType, fields)
+	p("")
+
+	switch {
+	case m.GetClientStreaming():
+		p("  stream, err := %s(context.Background())", call)
+		p("  if err != nil {")
+		p("    t.Fatal(err)")
+		p("  }")
+		p("  if err := stream.Send(request); err != nil {")
+		p("    t.Fatal(err)")
+		p("  }")
+		if m.GetServerStreaming() {
+			p("  if err := stream.CloseSend(); err != nil {")
+			p("    t.Fatal(err)")
+			p("  }")
+			p("  resp, err := stream.Recv()")
+		} else {
+			p("  resp, err := stream.CloseAndRecv()")
+		}
+	case m.GetServerStreaming():
+		p("  stream, err := %s(context.Background(), request)", call)
+		p("  if err != nil {")
+		p("    t.Fatal(err)")
+		p("  }")
+		p("  resp, err := stream.Recv()")
+	case isLRO:
+		p("  respLRO, err := %s(context.Background(), request)", call)
+		p("  if err != nil {")
+		p("    t.Fatal(err)")
+		p("  }")
+		if respVar == "" {
+			p("  err = respLRO.Wait(context.Background())")
+		} else {
+			p("  resp, err := respLRO.Wait(context.Background())")
+		}
+	case m.GetOutputType() == emptyType:
+		p("  err := %s(context.Background(), request)", call)
+	case pf != nil:
+		p("  it := %s(context.Background(), request)", call)
+		if g.isMapField(pf) {
+			p("  if _, err := it.Next(); err != iterator.Done {")
+			p("    t.Errorf(\"got %%v, want iterator.Done\", err)")
+			p("  }")
+		} else {
+			p("  resp, err := it.Next()")
+		}
+		g.imports[pbinfo.ImportSpec{Path: "google.golang.org/api/iterator"}] = true
+	default:
+		p("  resp, err := %s(context.Background(), request)", call)
+	}
+	if pf == nil || !g.isMapField(pf) {
+		p("  if err != nil {")
+		p("    t.Fatal(err)")
+		p("  }")
+	}
+	p("")
+
+	// Paging calls alter the page token and size of the request.
+	if pf == nil {
+		p("  if want, got := request, mock.reqs[0]; !proto.Equal(want, got) {")
+		p(`    t.Errorf("wrong request %%q, want %%q", got, want)`)
+		p("  }")
+	}
+	switch {
+	case pf != nil && g.isMapField(pf):
+	case pf != nil:
+		elem := fmt.Sprintf("expectedResponse.Get%s()[0]", snakeToCamel(pf.GetName()))
+		if pf.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+			p("  if want, got := %s, resp; !proto.Equal(want, got) {", elem)
+		} else {
+			p("  if want, got := %s, resp; !reflect.DeepEqual(want, got) {", elem)
+			g.imports[pbinfo.ImportSpec{Path: "reflect"}] = true
+		}
+		p(`    t.Errorf("wrong response %%v, want %%v", got, want)`)
+		p("  }")
+		p("  if _, err := it.Next(); err != iterator.Done {")
+		p("    t.Errorf(\"got %%v, want iterator.Done\", err)")
+		p("  }")
+	case respVar != "":
+		p("  if want, got := %s, resp; !proto.Equal(want, got) {", respVar)
+		p(`    t.Errorf("wrong response %%q, want %%q", got, want)`)
+		p("  }")
+	}
+	if len(wantParams) > 0 {
+		var quoted []string
+		for _, w := range wantParams {
+			quoted = append(quoted, fmt.Sprintf("%q", w))
+		}
+		p("  mock.checkRequestParams(t, %s)", strings.Join(quoted, ", "))
+	}
+	p("}")
+	p("")
+
+	// Failing call.
+	p("func %sError(t *testing.T) {", testName)
+	p("  mock, c := newMock%sClient(t)", serv.GetName())
+	p("  errCode := codes.PermissionDenied")
+	p(`  mock.err = gstatus.Error(errCode, "test error")`)
+	p("")
+	g.mockRequest(inSpec, inType, fields)
+	p("")
+	switch {
+	case m.GetClientStreaming():
+		p("  stream, err := %s(context.Background())", call)
+		p("  if err == nil {")
+		p("    err = stream.Send(request)")
+		p("  }")
+		p("  if err == nil {")
+		if m.GetServerStreaming() {
+			p("    if err = stream.CloseSend(); err == nil {")
+			p("      _, err = stream.Recv()")
+			p("    }")
+		} else {
+			p("    _, err = stream.CloseAndRecv()")
+		}
+		p("  }")
+	case m.GetServerStreaming():
+		p("  stream, err := %s(context.Background(), request)", call)
+		p("  if err == nil {")
+		p("    _, err = stream.Recv()")
+		p("  }")
+	case isLRO:
+		p("  _, err := %s(context.Background(), request)", call)
+	case m.GetOutputType() == emptyType:
+		p("  err := %s(context.Background(), request)", call)
+	case pf != nil:
+		p("  _, err := %s(context.Background(), request).Next()", call)
+	default:
+		p("  _, err := %s(context.Background(), request)", call)
+	}
+	p("  if st, ok := gstatus.FromError(err); !ok {")
+	p(`    t.Errorf("got error %%v, expected grpc error", err)`)
+	p("  } else if c := st.Code(); c != errCode {")
+	p(`    t.Errorf("got error code %%q, want %%q", c, errCode)`)
+	p("  }")
+	p("}")
+	p("")
+	return nil
+}
+
+// mockRequest prints the request of a mock test, with the given fields set.
+func (g *generator) mockRequest(inSpec pbinfo.ImportSpec, inType *descriptorpb.DescriptorProto, fields []*requestField) {
+	p := g.printf
+	if len(fields) == 0 {
+		p("  request := &%s.%s{}", inSpec.Name, inType.GetName())
+		return
+	}
+	p("  request := &%s.%s{", inSpec.Name, inType.GetName())
+	g.requestFields(fields)
+	p("  }")
+}
+
+// mockRequestParams returns the fields to set in the request of m for it to
+// carry routing headers, and the parameters of the x-goog-request-params
+// header the client must send for it. Only string fields are set.
+func (g *generator) mockRequestParams(m *descriptorpb.MethodDescriptorProto, inType *descriptorpb.DescriptorProto) ([]*requestField, []string) {
+	var fields []*requestField
+	values := map[string]string{}
+	setField := func(path, tmpl string) {
+		if _, ok := values[path]; ok {
+			return
+		}
+		v := sampleTemplateValue(tmpl)
+		if !g.setMockField(&fields, inType, strings.Split(path, "."), v) {
+			return
+		}
+		values[path] = v
+	}
+
+	var want []string
+	if dynamicRequestHeadersExist(m) {
+		routing := proto.GetExtension(m.GetOptions(), annotations.E_Routing).(*annotations.RoutingRule)
+		for _, param := range routing.GetRoutingParameters() {
+			setField(param.GetField(), param.GetPathTemplate())
+		}
+
+		// Evaluate the headers the same way the generated code does, later
+		// parameters overriding earlier ones of the same name.
+		headerValues := map[string]string{}
+		var headerNames []string
+		for _, h := range parseDynamicRequestHeaders(m) {
+			v, ok := values[h[1]]
+			if !ok {
+				continue
+			}
+			reg := regexp.MustCompile(h[0])
+			if !reg.MatchString(v) {
+				continue
+			}
+			captured := url.QueryEscape(reg.FindStringSubmatch(v)[1])
+			if captured == "" {
+				continue
+			}
+			if _, ok := headerValues[h[2]]; !ok {
+				headerNames = append(headerNames, h[2])
+			}
+			headerValues[h[2]] = captured
+		}
+		for _, name := range headerNames {
+			want = append(want, fmt.Sprintf("%s=%s", name, headerValues[name]))
+		}
+		return fields, want
+	}
+
+	for _, h := range parseImplicitRequestHeaders(m) {
+		field := h[1]
+		if _, ok := values[field]; ok {
+			continue
+		}
+		setField(field, g.httpFieldTemplate(m, field))
+		if v, ok := values[field]; ok {
+			want = append(want, fmt.Sprintf("%s=%s", url.QueryEscape(field), url.QueryEscape(v)))
+		}
+	}
+	return fields, want
+}
+
+// setMockField adds the string field at path, in message msg, to fields with
+// value v. It reports whether the field could be set, which it cannot if it
+// is not a string, or if it or its parents are repeated or part of a oneof.
+func (g *generator) setMockField(fields *[]*requestField, msg *descriptorpb.DescriptorProto, path []string, v string) bool {
+	var f *descriptorpb.FieldDescriptorProto
+	for _, mf := range msg.GetField() {
+		if mf.GetName() == path[0] {
+			f = mf
+			break
+		}
+	}
+	if f == nil || f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return false
+	}
+	if f.OneofIndex != nil && !f.GetProto3Optional() {
+		return false
+	}
+	name := snakeToCamel(f.GetName())
+
+	if len(path) == 1 {
+		if f.GetType() != descriptorpb.FieldDescriptorProto_TYPE_STRING {
+			return false
+		}
+		value := fmt.Sprintf("%q", v)
+		if g.descInfo.HasExplicitPresence(f) {
+			value = fmt.Sprintf("proto.String(%q)", v)
+		}
+		*fields = append(*fields, &requestField{name: name, value: value})
+		return true
+	}
+
+	if f.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+		return false
+	}
+	sub, ok := g.descInfo.Type[f.GetTypeName()].(*descriptorpb.DescriptorProto)
+	if !ok {
+		return false
+	}
+	var parent *requestField
+	for _, mf := range *fields {
+		if mf.name == name {
+			parent = mf
+			break
+		}
+	}
+	if parent == nil {
+		subSpec, err := g.descInfo.ImportSpec(sub)
+		if err != nil {
+			return false
+		}
+		parent = &requestField{name: name, typ: fmt.Sprintf("%s.%s", subSpec.Name, g.nestedName(sub))}
+		if !g.setMockField(&parent.fields, sub, path[1:], v) {
+			return false
+		}
+		g.imports[subSpec] = true
+		*fields = append(*fields, parent)
+		return true
+	}
+	return g.setMockField(&parent.fields, sub, path[1:], v)
+}
+
+// httpFieldTemplate returns the template of the variable field in the
+// google.api.http annotation of m, or the empty string if it has none.
+func (g *generator) httpFieldTemplate(m *descriptorpb.MethodDescriptorProto, field string) string {
+	rule := proto.GetExtension(m.GetOptions(), annotations.E_Http).(*annotations.HttpRule)
+	rules := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+	varRegexp := regexp.MustCompile(`{` + regexp.QuoteMeta(field) + `(?:=([^}]*))?}`)
+	for _, r := range rules {
+		if match := varRegexp.FindStringSubmatch(httpRuleInfo(r).url); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// sampleTemplateValue returns a value matching the path template tmpl, in
+// which variables are replaced by their own template. An empty template
+// matches any value.
+func sampleTemplateValue(tmpl string) string {
+	t, err := parsePathTemplate(tmpl)
+	if tmpl == "" || err != nil {
+		return "sample"
+	}
+	return t.sample()
+}
+
+// isMapField reports whether f is a map field.
+func (g *generator) isMapField(f *descriptorpb.FieldDescriptorProto) bool {
+	if f.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE {
+		return false
+	}
+	msg, ok := g.descInfo.Type[f.GetTypeName()].(*descriptorpb.DescriptorProto)
+	return ok && msg.GetOptions().GetMapEntry()
+}
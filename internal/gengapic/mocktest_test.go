@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	longrunning "cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGenMockTestFile(t *testing.T) {
+	stringField := func(name string) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:  proto.String(name),
+			Type:  typep(descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			Label: labelp(descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+		}
+	}
+	httpOpts := func(get string) *descriptorpb.MethodOptions {
+		opts := &descriptorpb.MethodOptions{}
+		proto.SetExtension(opts, annotations.E_Http, &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Get{Get: get},
+		})
+		return opts
+	}
+
+	parent := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Parent"),
+		Field: []*descriptorpb.FieldDescriptorProto{stringField("name")},
+	}
+	inputType := &descriptorpb.DescriptorProto{
+		Name: proto.String("InputType"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			stringField("name"),
+			stringField("app_profile_id"),
+			{
+				Name:     proto.String("parent"),
+				Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_MESSAGE),
+				TypeName: proto.String(".my.pkg.Parent"),
+				Label:    labelp(descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+			},
+		},
+	}
+	outputType := &descriptorpb.DescriptorProto{Name: proto.String("OutputType")}
+	pageInputType := &descriptorpb.DescriptorProto{
+		Name: proto.String("PageInputType"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:  proto.String("page_size"),
+				Type:  typep(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				Label: labelp(descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+			},
+			stringField("page_token"),
+		},
+	}
+	pageOutputType := &descriptorpb.DescriptorProto{
+		Name: proto.String("PageOutputType"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			stringField("next_page_token"),
+			{
+				Name:     proto.String("items"),
+				Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_MESSAGE),
+				TypeName: proto.String(".my.pkg.OutputType"),
+				Label:    labelp(descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+			},
+		},
+	}
+
+	implicitOpts := httpOpts("/v1/{name=projects/*/foos/*}/{parent.name=parents/*}")
+	dynamicOpts := httpOpts("/v1/{name=projects/*/foos/*}")
+	proto.SetExtension(dynamicOpts, annotations.E_Routing, &annotations.RoutingRule{
+		RoutingParameters: []*annotations.RoutingParameter{
+			{Field: "name", PathTemplate: "{project=projects/*}/**"},
+			{Field: "app_profile_id"},
+			{Field: "app_profile_id", PathTemplate: "{routing_id=**}"},
+		},
+	})
+	respLROOpts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(respLROOpts, longrunning.E_OperationInfo, &longrunning.OperationInfo{
+		ResponseType: "my.pkg.OutputType",
+		MetadataType: "my.pkg.OutputType",
+	})
+	emptyLROOpts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(emptyLROOpts, longrunning.E_OperationInfo, &longrunning.OperationInfo{
+		ResponseType: emptyValue,
+		MetadataType: "my.pkg.OutputType",
+	})
+
+	serv := &descriptorpb.ServiceDescriptorProto{
+		Name: proto.String("Foo"),
+		Method: []*descriptorpb.MethodDescriptorProto{
+			{
+				Name:       proto.String("GetOneThing"),
+				InputType:  proto.String(".my.pkg.InputType"),
+				OutputType: proto.String(".my.pkg.OutputType"),
+				Options:    implicitOpts,
+			},
+			{
+				Name:       proto.String("GetRoutedThing"),
+				InputType:  proto.String(".my.pkg.InputType"),
+				OutputType: proto.String(".my.pkg.OutputType"),
+				Options:    dynamicOpts,
+			},
+			{
+				Name:       proto.String("GetEmptyThing"),
+				InputType:  proto.String(".my.pkg.InputType"),
+				OutputType: proto.String(emptyType),
+			},
+			{
+				Name:       proto.String("GetManyThings"),
+				InputType:  proto.String(".my.pkg.PageInputType"),
+				OutputType: proto.String(".my.pkg.PageOutputType"),
+			},
+			{
+				Name:       proto.String("GetBigThing"),
+				InputType:  proto.String(".my.pkg.InputType"),
+				OutputType: proto.String(operationType),
+				Options:    respLROOpts,
+			},
+			{
+				Name:       proto.String("EmptyLRO"),
+				InputType:  proto.String(".my.pkg.InputType"),
+				OutputType: proto.String(operationType),
+				Options:    emptyLROOpts,
+			},
+			{
+				Name:            proto.String("ServerThings"),
+				InputType:       proto.String(".my.pkg.InputType"),
+				OutputType:      proto.String(".my.pkg.OutputType"),
+				ServerStreaming: proto.Bool(true),
+			},
+			{
+				Name:            proto.String("ClientThings"),
+				InputType:       proto.String(".my.pkg.InputType"),
+				OutputType:      proto.String(".my.pkg.OutputType"),
+				ClientStreaming: proto.Bool(true),
+			},
+			{
+				Name:            proto.String("BidiThings"),
+				InputType:       proto.String(".my.pkg.InputType"),
+				OutputType:      proto.String(".my.pkg.OutputType"),
+				ServerStreaming: proto.Bool(true),
+				ClientStreaming: proto.Bool(true),
+			},
+		},
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Package: proto.String("my.pkg"),
+		Syntax:  proto.String("proto3"),
+		Options: &descriptorpb.FileOptions{
+			GoPackage: proto.String("github.com/googleapis/mypackage;mypackagepb"),
+		},
+		MessageType: []*descriptorpb.DescriptorProto{parent, inputType, outputType, pageInputType, pageOutputType},
+		Service:     []*descriptorpb.ServiceDescriptorProto{serv},
+	}
+
+	g := &generator{
+		imports: map[pbinfo.ImportSpec]bool{},
+		aux: &auxTypes{
+			iters:           map[string]*iterType{},
+			methodToWrapper: map[*descriptorpb.MethodDescriptorProto]operationWrapper{},
+			opWrappers:      map[string]operationWrapper{},
+		},
+		mixins: mixins{
+			"google.longrunning.Operations": operationsMethods(),
+		},
+		cfg: &generatorConfig{
+			pkgName:       "foo",
+			transports:    []transport{grpc},
+			generateTests: true,
+		},
+	}
+	commonTypes(g)
+	g.descInfo = pbinfo.Of(append(g.getMixinFiles(), file, g.descInfo.ParentFile[g.descInfo.Type[emptyType]]))
+
+	if err := g.genMockTestFile(serv); err != nil {
+		t.Fatal(err)
+	}
+	got := g.pt.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "foo_client_mock_test.go", "package foo\n\n"+got, parser.AllErrors); err != nil {
+		t.Errorf("generated tests do not parse: %v", err)
+	}
+	txtdiff.Diff(t, got, filepath.Join("testdata", "mock_test_Foo.want"))
+
+	for _, imp := range []pbinfo.ImportSpec{
+		{Name: "mypackagepb", Path: "github.com/googleapis/mypackage"},
+		{Name: "longrunningpb", Path: "cloud.google.com/go/longrunning/autogen/longrunningpb"},
+		{Name: "emptypb", Path: "google.golang.org/protobuf/types/known/emptypb"},
+		{Name: "anypb", Path: "google.golang.org/protobuf/types/known/anypb"},
+		{Name: "gstatus", Path: "google.golang.org/grpc/status"},
+		{Path: "google.golang.org/grpc/credentials/insecure"},
+		{Path: "google.golang.org/api/iterator"},
+		{Path: "io"},
+	} {
+		if !g.imports[imp] {
+			t.Errorf("missing import %v", imp)
+		}
+	}
+}
+
+func TestSampleTemplateValue(t *testing.T) {
+	for _, tst := range []struct {
+		tmpl, want string
+	}{
+		{"", "sample"},
+		{"projects/*/foos/*", "projects/sample/foos/sample"},
+		{"{project=projects/*}/**", "projects/sample/sample/sample"},
+		{"{routing_id=**}", "sample/sample"},
+		{"{name}", "sample"},
+	} {
+		if got := sampleTemplateValue(tst.tmpl); got != tst.want {
+			t.Errorf("sampleTemplateValue(%q) = %q, want %q", tst.tmpl, got, tst.want)
+		}
+	}
+}
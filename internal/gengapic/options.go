@@ -19,13 +19,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ghodss/yaml"
 	conf "github.com/googleapis/gapic-generator-go/internal/grpc_service_config"
+	"github.com/googleapis/gapic-generator-go/internal/license"
 	"google.golang.org/genproto/googleapis/api/serviceconfig"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 // Define a type to represent supported network transports.
@@ -35,6 +41,8 @@ type transport int
 const (
 	grpc transport = iota
 	rest
+	connect
+	inprocess
 )
 
 // static error for the most critical argument
@@ -46,35 +54,322 @@ var DeprecatedArgs map[string]error = map[string]error{
 	"gapic-service-config": errors.New("removed, use api-service-config instead"),
 }
 
-// SupportedBooleanArgs expose boolean plugin arguments (presence enables option).
-var SupportedBooleanArgs map[string]func() configOption = map[string]func() configOption{
-	"metadata":           generateGAPICMetadata,
-	"diregapic":          generateAsDIREGAPIC,
-	"rest-numeric-enums": enableRESTNumericEnums,
-	"omit-snippets":      enableOmitSnippets,
+// Transport is a transport of the generated clients.
+type Transport string
+
+// The transports of the generated clients.
+const (
+	GRPC    Transport = "grpc"
+	REST    Transport = "rest"
+	Connect Transport = "connect"
+	// InProcess clients call a server implementation directly. It must be
+	// combined with another transport.
+	InProcess Transport = "inprocess"
+)
+
+// Options configures the generation. The plugin tag of a field is the key of
+// the plugin argument setting it, described in the README. The boolean fields
+// are set by the presence of their argument, e.g. metadata, the others by a
+// value, e.g. module=cloud.google.com/go, or a prefix, e.g. F_ for F_<feature>.
+type Options struct {
+	// PackagePath is the Go import path of the generated package, e.g.
+	// cloud.google.com/go/foo/apiv1, and PackageName its name, e.g. foo.
+	// They are required, and set together as path;name by the plugin
+	// argument.
+	PackagePath string `plugin:"go-gapic-package"`
+	PackageName string
+	// Module is the prefix of PackagePath stripped from the names of the
+	// generated files.
+	Module string `plugin:"module"`
+	// Transports are the transports of the generated clients, gRPC only if
+	// empty, delimited by + in the plugin argument.
+	Transports []Transport `plugin:"transport"`
+	// APIServiceConfig is the path to the API service config.
+	APIServiceConfig string `plugin:"api-service-config"`
+	// GRPCServiceConfig is the path to the gRPC service config.
+	GRPCServiceConfig string `plugin:"grpc-service-config"`
+	// ReleaseLevel is the release level of the generated package, e.g. beta.
+	ReleaseLevel string `plugin:"release-level"`
+	// Metadata generates gapic_metadata.json.
+	Metadata bool `plugin:"metadata"`
+	// DIREGAPIC generates clients of protos compiled from a discovery
+	// document.
+	DIREGAPIC bool `plugin:"diregapic"`
+	// RESTNumericEnums makes the REST clients send enums as numbers.
+	RESTNumericEnums bool `plugin:"rest-numeric-enums"`
+	// OmitSnippets omits the snippets.
+	OmitSnippets bool `plugin:"omit-snippets"`
+	// GenerateTests generates tests of the gRPC clients against fake servers.
+	GenerateTests bool `plugin:"generate-tests"`
+	// ClientInterface generates an exported interface of each client.
+	ClientInterface bool `plugin:"client-interface"`
+	// Reproducible makes the output depend only on the input.
+	Reproducible bool `plugin:"reproducible"`
+	// CopyrightYear is the year of the copyright notices, the current year if
+	// 0.
+	CopyrightYear int `plugin:"copyright-year"`
+	// CopyrightHolder is the holder of the copyright notices, Google LLC if
+	// empty.
+	CopyrightHolder string `plugin:"copyright-holder"`
+	// License is the SPDX identifier of the license in the license headers,
+	// Apache-2.0 if empty, or none.
+	License string `plugin:"license"`
+	// LicenseFile is the path to a text file replacing the license headers.
+	LicenseFile string `plugin:"license-file"`
+	// Diagnostics writes generator_diagnostics.json.
+	Diagnostics bool `plugin:"diagnostics"`
+	// DiagnosticsStderr writes the diagnostics to stderr.
+	DiagnosticsStderr bool `plugin:"diagnostics-stderr"`
+	// Strict are the classes of diagnostics failing the generation, or all,
+	// delimited by + in the plugin argument.
+	Strict []string `plugin:"strict"`
+	// APISurface writes api_surface.json.
+	APISurface bool `plugin:"api-surface"`
+	// PackageOverrides maps the names of proto files to the Go packages of
+	// their types, as import/path;name, e.g. with the plugin argument
+	// Mgoogle/foo/v1/foo.proto=cloud.google.com/go/foo/apiv1/foopb;foopb.
+	PackageOverrides map[string]string `plugin:"M,prefix"`
+	// Features are the experimental features enabled.
+	Features []string `plugin:"F_,prefix"`
+	// Imports are the files imported by the files to generate, directly or
+	// not, when generating without a CodeGeneratorRequest.
+	Imports []*descriptorpb.FileDescriptorProto
+}
+
+// OptionError is the error of an invalid option, or of options in conflict.
+type OptionError struct {
+	// Option is the name of the field of Options, e.g. Transports.
+	Option string
+	Err    error
+}
+
+func (e *OptionError) Error() string {
+	if f, ok := reflect.TypeOf(Options{}).FieldByName(e.Option); ok {
+		if key, _, _ := strings.Cut(f.Tag.Get("plugin"), ","); key != "" {
+			return fmt.Sprintf("invalid option %s (plugin arg %s): %v", e.Option, key, e.Err)
+		}
+	}
+	return fmt.Sprintf("invalid option %s: %v", e.Option, e.Err)
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// pluginArg is a plugin argument setting a field of Options.
+type pluginArg struct {
+	key    string
+	field  reflect.StructField
+	prefix bool
+}
+
+// boolean reports whether the argument is a boolean one, whose presence sets
+// its field.
+func (a pluginArg) boolean() bool {
+	return a.field.Type.Kind() == reflect.Bool
+}
+
+// pluginArgs are the plugin arguments of the tagged fields of Options, keyed
+// by the key of the argument.
+var pluginArgs = func() map[string]pluginArg {
+	args := map[string]pluginArg{}
+	t := reflect.TypeOf(Options{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("plugin")
+		if !ok {
+			continue
+		}
+		key, opt, _ := strings.Cut(tag, ",")
+		args[key] = pluginArg{key: key, field: f, prefix: opt == "prefix"}
+	}
+	return args
+}()
+
+// lookupPluginArg returns the argument of the given plugin argument as given,
+// and its value, or the part following the prefix for prefix arguments.
+func lookupPluginArg(s string) (pluginArg, string, bool) {
+	if a, ok := pluginArgs[s]; ok && a.boolean() {
+		return a, "", true
+	}
+	if key, val, ok := strings.Cut(s, "="); ok {
+		if a, ok := pluginArgs[key]; ok && !a.boolean() && !a.prefix {
+			return a, val, true
+		}
+	}
+	for _, a := range pluginArgs {
+		if a.prefix && strings.HasPrefix(s, a.key) {
+			return a, s[len(a.key):], true
+		}
+	}
+	return pluginArg{}, "", false
 }
 
-// SupportedValueArgs are arguments that are supplied in the form <key>=<value>.
-// The map is keyed by the argument key.
-var SupportedValueArgs map[string]func(string) configOption = map[string]func(string) configOption{
-	"go-gapic-package":    withGoGAPICPackage,
-	"api-service-config":  withAPIServiceConfigPath,
-	"grpc-service-config": withGRPCServiceConfigPath,
-	"module":              withModulePrefix,
-	"release-level":       withReleaseLevel,
-	"transport":           withTransports,
+// set sets the field of the argument in o to the given value.
+func (a pluginArg) set(o *Options, val string) error {
+	if a.key == "go-gapic-package" {
+		path, name, ok := strings.Cut(val, ";")
+		if !ok {
+			return errInvalidPackageParam
+		}
+		o.PackagePath, o.PackageName = path, name
+		return nil
+	}
+
+	v := reflect.ValueOf(o).Elem().FieldByIndex(a.field.Index)
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Int:
+		n, err := strconv.Atoi(val)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("want a positive number, got %q", val)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		elems := strings.Split(val, "+")
+		if a.prefix {
+			elems = []string{val}
+		} else {
+			v.Set(reflect.Zero(v.Type()))
+		}
+		for _, e := range elems {
+			v.Set(reflect.Append(v, reflect.ValueOf(e).Convert(v.Type().Elem())))
+		}
+	case reflect.Map:
+		k, e, ok := strings.Cut(val, "=")
+		if !ok || e == "" {
+			return fmt.Errorf("want %s<key>=<value>, got %q", a.key, a.key+val)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(e))
+	default:
+		return fmt.Errorf("unsupported type %s of plugin arg %s", v.Type(), a.key)
+	}
+	return nil
 }
 
-// SupportedPrefixArgs are a special case of the value arg that use a string prefix.
-// The primary use is for dynamic package overrides, which can be used to override the package
-// for a particular input in the example form:
+// ParseOptions parses the "parameter" field of a CodeGeneratorRequest, a
+// comma separated list of plugin arguments handled in the order they appear,
+// into Options. The arguments unknown to the generator are ignored.
 //
-//	Mgoogle/storage/v2/storage.proto=cloud.google.com/go/storage/internal/apiv2/stubs
+// The arguments may also be loaded from a YAML or JSON file with
+// config-file=<path>, see withConfigFile. Arguments given in the parameter
+// take precedence over those of the file.
 //
-// Recommendation: avoid adding more of these as they complicate processing.
-var SupportedPrefixArgs map[string]func(string) configOption = map[string]func(string) configOption{
-	"M":  withPackageOverride,
-	"F_": withFeature,
+// The errors about invalid arguments are *OptionError.
+func ParseOptions(parameter string) (Options, error) {
+	var o Options
+
+	// params are comma seperated, and may be preceded by those of a config file.
+	params, err := withConfigFile(parameter)
+	if err != nil {
+		return Options{}, err
+	}
+	for _, s := range params {
+		// Normalize to ensure we're not dealing with spacing issues.
+		s = strings.TrimSpace(s)
+
+		// Ignore empty args.
+		if s == "" {
+			continue
+		}
+
+		// Handle deprecated arguments first.
+		if err, ok := DeprecatedArgs[s]; ok {
+			return Options{}, err
+		}
+		a, val, ok := lookupPluginArg(s)
+		if !ok {
+			continue
+		}
+		if err := a.set(&o, val); err != nil {
+			return Options{}, &OptionError{Option: a.field.Name, Err: err}
+		}
+	}
+	return o, nil
+}
+
+// Config is the configuration of the generation built from Options.
+type Config struct {
+	cfg *generatorConfig
+}
+
+// NewConfig builds and validates the configuration of the given options. The
+// errors about invalid options are *OptionError.
+func NewConfig(o Options) (*Config, error) {
+	type namedOption struct {
+		name string
+		opt  configOption
+	}
+	var opts []namedOption
+	add := func(name string, opt configOption) {
+		opts = append(opts, namedOption{name: name, opt: opt})
+	}
+	value := func(name, val string, opt func(string) configOption) {
+		if val != "" {
+			add(name, opt(val))
+		}
+	}
+	flag := func(name string, set bool, opt func() configOption) {
+		if set {
+			add(name, opt())
+		}
+	}
+
+	add("PackagePath", withGoPackage(o.PackagePath, o.PackageName))
+	value("Module", o.Module, withModulePrefix)
+	if len(o.Transports) > 0 {
+		add("Transports", withTransports(o.Transports))
+	}
+	value("APIServiceConfig", o.APIServiceConfig, withAPIServiceConfigPath)
+	value("GRPCServiceConfig", o.GRPCServiceConfig, withGRPCServiceConfigPath)
+	value("ReleaseLevel", o.ReleaseLevel, withReleaseLevel)
+	flag("Metadata", o.Metadata, generateGAPICMetadata)
+	flag("DIREGAPIC", o.DIREGAPIC, generateAsDIREGAPIC)
+	flag("RESTNumericEnums", o.RESTNumericEnums, enableRESTNumericEnums)
+	flag("OmitSnippets", o.OmitSnippets, enableOmitSnippets)
+	flag("GenerateTests", o.GenerateTests, enableGenerateTests)
+	flag("ClientInterface", o.ClientInterface, enableClientInterface)
+	flag("Reproducible", o.Reproducible, enableReproducible)
+	if o.CopyrightYear != 0 {
+		add("CopyrightYear", withCopyrightYear(o.CopyrightYear))
+	}
+	value("CopyrightHolder", o.CopyrightHolder, withCopyrightHolder)
+	value("License", o.License, withLicense)
+	value("LicenseFile", o.LicenseFile, withLicenseFile)
+	flag("Diagnostics", o.Diagnostics, enableDiagnostics)
+	flag("DiagnosticsStderr", o.DiagnosticsStderr, enableDiagnosticsStderr)
+	if len(o.Strict) > 0 {
+		add("Strict", withStrict(o.Strict))
+	}
+	flag("APISurface", o.APISurface, enableAPISurface)
+	files := make([]string, 0, len(o.PackageOverrides))
+	for f := range o.PackageOverrides {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		add("PackageOverrides", withPackageOverride(f, o.PackageOverrides[f]))
+	}
+	for _, f := range o.Features {
+		add("Features", withFeature(f))
+	}
+
+	cfg := &generatorConfig{}
+	for _, o := range opts {
+		if err := o.opt(cfg); err != nil {
+			return nil, &OptionError{Option: o.name, Err: err}
+		}
+	}
+	if err := validateAndNormalizeOptions(cfg); err != nil {
+		return nil, err
+	}
+	return &Config{cfg: cfg}, nil
 }
 
 // Configuration needed to drive the operation of the plugin.
@@ -103,6 +398,38 @@ type generatorConfig struct {
 	// TODO: rename this in a subsequent refactor
 	omitSnippets bool
 
+	// should tests of the gRPC clients against fake servers be generated
+	generateTests bool
+
+	// should an exported interface of each client be generated
+	clientInterface bool
+
+	// should the output be identical across runs on the same input
+	reproducible bool
+
+	// year of the copyright notice in the license headers, the current year if 0
+	copyrightYear int
+
+	// holder of the copyright notice in the license headers, Google LLC if empty
+	copyrightHolder string
+
+	// SPDX identifier of the license in the license headers, Apache-2.0 if
+	// empty, or none to omit the copyright notice
+	license string
+
+	// commented text of the license file replacing the license headers
+	licenseText string
+
+	// should the diagnostics be written to a JSON file, and to stderr
+	diagnostics       bool
+	diagnosticsStderr bool
+
+	// classes of diagnostics that fail the generation
+	strict map[diagnosticClass]bool
+
+	// should a manifest of the generated public API be written
+	apiSurface bool
+
 	// Parsed Service Configuration.
 	APIServiceConfig *serviceconfig.Service
 
@@ -132,75 +459,25 @@ type generatorConfig struct {
 // Config options that return errors should not modify the configuration.
 type configOption func(*generatorConfig) error
 
-// configFromRequest consumes the "parameter" field from the CodeGenerationRequest to produce a configuration.
-// This should be a comma seperated list of plugin arguments, and each one is handled in the order it appears.
+// configFromRequest consumes the "parameter" field from the CodeGenerationRequest to produce a configuration,
+// parsed by ParseOptions and built by NewConfig.
 //
-// All plugin arguments understood by this plugin should be registered in one of the well known option collections, which
-// are used to process the input parameters:
-// DeprecatedArgs
-// SupportedBooleanArgs
-// SupportedValueArgs
-// SupportedPrefixArgs
+// All plugin arguments understood by this plugin are set by a field of Options, tagged with the key of the
+// argument, or are among the DeprecatedArgs.
 func configFromRequest(generationParameter *string) (*generatorConfig, error) {
 	if generationParameter == nil {
 		return nil, errors.New("generationParameter is nil, cannot configure")
 	}
 
-	cfg := &generatorConfig{}
-
-	// params are comma seperated.  Split and process each individually.
-	for _, s := range strings.Split(*generationParameter, ",") {
-		// Normalize to ensure we're not dealing with spacing issues.
-		pluginArg := strings.TrimSpace(s)
-
-		// Ignore empty args.
-		if pluginArg == "" {
-			continue
-		}
-
-		// Handle deprecated arguments first.
-		if err, ok := DeprecatedArgs[pluginArg]; ok {
-			return nil, err
-		}
-		// Handle the boolean keyword args, e.g. non key=value style arguments.
-		if o, ok := SupportedBooleanArgs[pluginArg]; ok {
-			if err := o()(cfg); err != nil {
-				// It's unlikely that any boolean option will surface errors, but we do it here
-				// lest we are surprised in the future.
-				return nil, fmt.Errorf("plugin arg %q invalid: %w", pluginArg, err)
-			}
-			continue
-		}
-
-		// Handle value args by parsing key=value parts.
-		e := strings.IndexByte(pluginArg, '=')
-		if e > 0 {
-			key, val := pluginArg[:e], pluginArg[e+1:]
-			if valueOpt, ok := SupportedValueArgs[key]; ok {
-				if err := valueOpt(val)(cfg); err != nil {
-					return nil, fmt.Errorf("plugin arg %q invalid: %w", pluginArg, err)
-				}
-				continue
-			}
-		}
-
-		// Now, handle prefix args by scanning registered prefixes.
-		for prefix, prefixOpt := range SupportedPrefixArgs {
-			if strings.HasPrefix(pluginArg, prefix) {
-				prefixLen := len(prefix)
-				if err := prefixOpt(pluginArg[prefixLen:])(cfg); err != nil {
-					return nil, fmt.Errorf("plugin arg %q invalid: %w", pluginArg, err)
-				}
-				continue
-			}
-		}
-
+	o, err := ParseOptions(*generationParameter)
+	if err != nil {
+		return nil, err
 	}
-	// Now that all options are processed, normalized and validate.
-	if err := validateAndNormalizeOptions(cfg); err != nil {
+	c, err := NewConfig(o)
+	if err != nil {
 		return nil, err
 	}
-	return cfg, nil
+	return c.cfg, nil
 }
 
 // This function provides an opportunity to validate that a configuration adheres to expectations
@@ -217,17 +494,41 @@ func validateAndNormalizeOptions(cfg *generatorConfig) error {
 
 	// REST enums are not supported by DIREGAPIC.
 	if cfg.generateAsDIREGAPIC && cfg.restNumericEnum {
-		return errors.New("incompatible features: diregapic and rest numeric enums")
+		return &OptionError{Option: "RESTNumericEnums", Err: errors.New("incompatible features: diregapic and rest numeric enums")}
 	}
 
 	// Certain configuration details must be present.
 	if cfg.pkgPath == "" || cfg.pkgName == "" || cfg.outDir == "" {
-		return errInvalidPackageParam
+		return &OptionError{Option: "PackagePath", Err: errInvalidPackageParam}
+	}
+
+	// Reproducible output cannot depend on the current year, nor on the runtime
+	// ordering of maps in the generated code.
+	if cfg.reproducible {
+		if cfg.copyrightYear == 0 {
+			year, err := sourceDateYear()
+			if err != nil {
+				return &OptionError{Option: "Reproducible", Err: err}
+			}
+			cfg.copyrightYear = year
+		}
+		if cfg.featureEnablement == nil {
+			cfg.featureEnablement = make(map[featureID]struct{})
+		}
+		cfg.featureEnablement[OrderedRoutingHeadersFeature] = struct{}{}
+	}
+
+	// A license file replaces the license headers entirely.
+	if cfg.licenseText != "" && (cfg.license != "" || cfg.copyrightHolder != "") {
+		return &OptionError{Option: "LicenseFile", Err: errors.New("incompatible options: license-file and license or copyright-holder")}
+	}
+	if cfg.license == license.None && cfg.copyrightHolder != "" {
+		return &OptionError{Option: "License", Err: errors.New("incompatible options: license=none and copyright-holder")}
 	}
 
 	if cfg.modulePrefix != "" {
 		if !strings.HasPrefix(cfg.outDir, cfg.modulePrefix) {
-			return fmt.Errorf("go-gapic-package %q does not match prefix %q", cfg.outDir, cfg.modulePrefix)
+			return &OptionError{Option: "Module", Err: fmt.Errorf("go-gapic-package %q does not match prefix %q", cfg.outDir, cfg.modulePrefix)}
 		}
 		cfg.outDir = strings.TrimPrefix(cfg.outDir, cfg.modulePrefix+"/")
 	}
@@ -235,22 +536,12 @@ func validateAndNormalizeOptions(cfg *generatorConfig) error {
 	return nil
 }
 
-// withGoGapicPackage parses the value argument based on the typical
-// pattern:
-// <packagepath>;<packagename>
-// Example:
-// cloud.google.com/go/foo/v1/foopb;foopb
-func withGoGAPICPackage(s string) configOption {
-	p := strings.IndexByte(s, ';')
-
-	if p < 0 {
-		return func(cfg *generatorConfig) error {
-			return errInvalidPackageParam
-		}
-	}
+// withGoPackage specifies the import path and the name of the generated
+// package.
+func withGoPackage(path, name string) configOption {
 	return func(cfg *generatorConfig) error {
-		cfg.pkgPath = s[0:p]
-		cfg.pkgName = s[p+1:]
+		cfg.pkgPath = path
+		cfg.pkgName = name
 		cfg.outDir = filepath.FromSlash(cfg.pkgPath)
 		return nil
 	}
@@ -292,6 +583,148 @@ func enableOmitSnippets() configOption {
 	}
 }
 
+func enableGenerateTests() configOption {
+	return func(cfg *generatorConfig) error {
+		cfg.generateTests = true
+		return nil
+	}
+}
+
+func enableClientInterface() configOption {
+	return func(cfg *generatorConfig) error {
+		cfg.clientInterface = true
+		return nil
+	}
+}
+
+// enableReproducible makes the output depend only on the input, by fixing the
+// year of the copyright notices and the order of the routing headers.
+func enableReproducible() configOption {
+	return func(cfg *generatorConfig) error {
+		cfg.reproducible = true
+		return nil
+	}
+}
+
+// withCopyrightYear specifies the year of the copyright notices.
+func withCopyrightYear(year int) configOption {
+	return func(cfg *generatorConfig) error {
+		if year <= 0 {
+			return fmt.Errorf("invalid copyright year %d", year)
+		}
+		cfg.copyrightYear = year
+		return nil
+	}
+}
+
+// Specifies the holder of the copyright notices.
+func withCopyrightHolder(s string) configOption {
+	return func(cfg *generatorConfig) error {
+		if strings.TrimSpace(s) == "" {
+			return errors.New("provided copyright holder was empty")
+		}
+		cfg.copyrightHolder = s
+		return nil
+	}
+}
+
+// withLicense specifies the license of the license headers by SPDX identifier,
+// one of license.Presets, or omits the headers with "none".
+func withLicense(s string) configOption {
+	return func(cfg *generatorConfig) error {
+		if s != license.None && !slices.Contains(license.Presets, s) {
+			return fmt.Errorf("unsupported license %q, want one of %s or %s", s, strings.Join(license.Presets, ", "), license.None)
+		}
+		cfg.license = s
+		return nil
+	}
+}
+
+// withLicenseFile replaces the license headers with the text of the given
+// file.
+func withLicenseFile(s string) configOption {
+	return func(cfg *generatorConfig) error {
+		if s == "" {
+			return errors.New("provided license file path was empty")
+		}
+		b, err := os.ReadFile(s)
+		if err != nil {
+			return fmt.Errorf("error reading license file (%q): %v", s, err)
+		}
+		if strings.TrimSpace(string(b)) == "" {
+			return fmt.Errorf("license file %q is empty", s)
+		}
+		cfg.licenseText = license.Comment(string(b))
+		return nil
+	}
+}
+
+// withStrict makes errors of the diagnostics of the given classes, or of all
+// classes with "all".
+func withStrict(classes []string) configOption {
+	return func(cfg *generatorConfig) error {
+		strict := map[diagnosticClass]bool{}
+		for _, c := range classes {
+			if c == "all" {
+				for dc := range diagnosticClasses {
+					strict[dc] = true
+				}
+				continue
+			}
+			if _, ok := diagnosticClasses[diagnosticClass(c)]; !ok {
+				var known []string
+				for dc := range diagnosticClasses {
+					known = append(known, string(dc))
+				}
+				sort.Strings(known)
+				return fmt.Errorf("unknown diagnostic class %q, want all or one of %s", c, strings.Join(known, ", "))
+			}
+			strict[diagnosticClass(c)] = true
+		}
+		cfg.strict = strict
+		return nil
+	}
+}
+
+// enableDiagnostics writes the diagnostics to a JSON file in the output directory.
+func enableDiagnostics() configOption {
+	return func(cfg *generatorConfig) error {
+		cfg.diagnostics = true
+		return nil
+	}
+}
+
+// enableDiagnosticsStderr writes the diagnostics as text to stderr.
+func enableDiagnosticsStderr() configOption {
+	return func(cfg *generatorConfig) error {
+		cfg.diagnosticsStderr = true
+		return nil
+	}
+}
+
+// enableAPISurface writes a manifest of the generated public API to a JSON
+// file in the output directory.
+func enableAPISurface() configOption {
+	return func(cfg *generatorConfig) error {
+		cfg.apiSurface = true
+		return nil
+	}
+}
+
+// sourceDateYear returns the year of the SOURCE_DATE_EPOCH environment
+// variable, as defined by https://reproducible-builds.org/specs/source-date-epoch/.
+func sourceDateYear() (int, error) {
+	epoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH")
+	if !ok {
+		return 0, errors.New("reproducible requires copyright-year or the SOURCE_DATE_EPOCH environment variable")
+	}
+	sec, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil || sec < 0 {
+		return 0, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q", epoch)
+	}
+	return time.Unix(sec, 0).UTC().Year(), nil
+}
+
 // Specifies the path to the API service config file.
 // Option parses the path and does basic validation.
 func withAPIServiceConfigPath(s string) configOption {
@@ -365,21 +798,9 @@ func withReleaseLevel(s string) configOption {
 	}
 }
 
-// withPackageOverride handles a package naming override.
-// The `M` prefix is not passed as part of the string, only the remaining substring.
-func withPackageOverride(s string) configOption {
-	e := strings.IndexByte(s, '=')
-	if e < 0 {
-		return func(cfg *generatorConfig) error {
-			return fmt.Errorf("invalid package override format, should be <file>=<renamed file>: %q", s)
-		}
-	}
-	key, val := s[:e], s[e+1:]
-	if val == "" {
-		return func(cfg *generatorConfig) error {
-			return fmt.Errorf("invalid plugin option value, missing value in key=value: %q", s)
-		}
-	}
+// withPackageOverride overrides the Go package of the types of the given
+// proto file, given as import/path;name.
+func withPackageOverride(key, val string) configOption {
 	return func(cfg *generatorConfig) error {
 		if cfg.pkgOverrides == nil {
 			cfg.pkgOverrides = make(map[string]string)
@@ -414,6 +835,10 @@ func (t transport) String() string {
 		return "grpc"
 	case rest:
 		return "rest"
+	case connect:
+		return "connect"
+	case inprocess:
+		return "inprocess"
 	default:
 		// Add new transport variants as need be.
 		return fmt.Sprintf("%d", int(t))
@@ -421,14 +846,18 @@ func (t transport) String() string {
 }
 
 // Specifies the requested network transports to generate.
-func withTransports(s string) configOption {
+func withTransports(names []Transport) configOption {
 	transports := map[transport]bool{}
-	for _, t := range strings.Split(s, "+") {
+	for _, t := range names {
 		switch t {
-		case "grpc":
+		case GRPC:
 			transports[grpc] = true
-		case "rest":
+		case REST:
 			transports[rest] = true
+		case Connect:
+			transports[connect] = true
+		case InProcess:
+			transports[inprocess] = true
 		default:
 			return func(cfg *generatorConfig) error {
 				return fmt.Errorf("invalid transport option: %q", t)
@@ -437,6 +866,9 @@ func withTransports(s string) configOption {
 
 	}
 	return func(cfg *generatorConfig) error {
+		if len(transports) == 1 && transports[inprocess] {
+			return errors.New("transport inprocess must be combined with a network transport, e.g. grpc+inprocess")
+		}
 		for t := range transports {
 			cfg.transports = append(cfg.transports, t)
 		}
@@ -15,6 +15,9 @@
 package gengapic
 
 import (
+	"errors"
+	"os"
+	"reflect"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -37,6 +40,26 @@ func TestParseOptions(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			param: "transport=connect+grpc,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports: []transport{grpc, connect},
+				pkgPath:    "path",
+				pkgName:    "pkg",
+				outDir:     "path",
+			},
+			expectErr: false,
+		},
+		{
+			param: "transport=inprocess+grpc,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports: []transport{grpc, inprocess},
+				pkgPath:    "path",
+				pkgName:    "pkg",
+				outDir:     "path",
+			},
+			expectErr: false,
+		},
 		{
 			param: "transport=rest+grpc,go-gapic-package=path;pkg",
 			expectedCfg: &generatorConfig{
@@ -109,10 +132,114 @@ func TestParseOptions(t *testing.T) {
 				omitSnippets: true,
 			},
 		},
+		{
+			param: "generate-tests,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports:    []transport{grpc},
+				pkgPath:       "path",
+				pkgName:       "pkg",
+				outDir:        "path",
+				generateTests: true,
+			},
+		},
+		{
+			param: "client-interface,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports:      []transport{grpc},
+				pkgPath:         "path",
+				pkgName:         "pkg",
+				outDir:          "path",
+				clientInterface: true,
+			},
+		},
+		{
+			param: "reproducible,copyright-year=2019,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports:    []transport{grpc},
+				pkgPath:       "path",
+				pkgName:       "pkg",
+				outDir:        "path",
+				reproducible:  true,
+				copyrightYear: 2019,
+				featureEnablement: map[featureID]struct{}{
+					OrderedRoutingHeadersFeature: struct{}{},
+				},
+			},
+		},
+		{
+			param: "copyright-year=2019,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports:    []transport{grpc},
+				pkgPath:       "path",
+				pkgName:       "pkg",
+				outDir:        "path",
+				copyrightYear: 2019,
+			},
+		},
+		{
+			param: "diagnostics,diagnostics-stderr,strict=capped-max-attempts+skipped-snippet,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports:        []transport{grpc},
+				pkgPath:           "path",
+				pkgName:           "pkg",
+				outDir:            "path",
+				diagnostics:       true,
+				diagnosticsStderr: true,
+				strict: map[diagnosticClass]bool{
+					cappedMaxAttempts: true,
+					skippedSnippet:    true,
+				},
+			},
+		},
+		{
+			param: "api-surface,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports: []transport{grpc},
+				pkgPath:    "path",
+				pkgName:    "pkg",
+				outDir:     "path",
+				apiSurface: true,
+			},
+		},
+		{
+			param:     "strict=unused-imports,go-gapic-package=path;pkg",
+			expectErr: true,
+		},
+		{
+			param:     "copyright-year=last,go-gapic-package=path;pkg",
+			expectErr: true,
+		},
+		{
+			param: "license=MIT,copyright-holder=Acme Corp.,go-gapic-package=path;pkg",
+			expectedCfg: &generatorConfig{
+				transports:      []transport{grpc},
+				pkgPath:         "path",
+				pkgName:         "pkg",
+				outDir:          "path",
+				license:         "MIT",
+				copyrightHolder: "Acme Corp.",
+			},
+		},
+		{
+			param:     "license=WTFPL,go-gapic-package=path;pkg",
+			expectErr: true,
+		},
+		{
+			param:     "license=none,copyright-holder=Acme Corp.,go-gapic-package=path;pkg",
+			expectErr: true,
+		},
+		{
+			param:     "license-file=testdata/no_such_license,go-gapic-package=path;pkg",
+			expectErr: true,
+		},
 		{
 			param:     "transport=tcp,go-gapic-package=path;pkg",
 			expectErr: true,
 		},
+		{
+			param:     "transport=inprocess,go-gapic-package=path;pkg",
+			expectErr: true,
+		},
 		{
 			param:     "go-gapic-package=pkg;",
 			expectErr: true,
@@ -193,6 +320,150 @@ func TestParseOptions(t *testing.T) {
 	}
 }
 
+func TestNewConfig(t *testing.T) {
+	got, err := NewConfig(Options{
+		PackagePath:      "cloud.google.com/go/foo/apiv1",
+		PackageName:      "foo",
+		Module:           "cloud.google.com/go",
+		Transports:       []Transport{REST, GRPC},
+		Metadata:         true,
+		CopyrightYear:    2019,
+		CopyrightHolder:  "Acme, Inc.",
+		Strict:           []string{"unknown-mixin"},
+		PackageOverrides: map[string]string{"a=b.proto": "a;a"},
+		Features:         []string{"ordered_routing_headers"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &generatorConfig{
+		pkgPath:               "cloud.google.com/go/foo/apiv1",
+		pkgName:               "foo",
+		outDir:                "foo/apiv1",
+		modulePrefix:          "cloud.google.com/go",
+		transports:            []transport{grpc, rest},
+		generateGAPICMetadata: true,
+		copyrightYear:         2019,
+		copyrightHolder:       "Acme, Inc.",
+		strict:                map[diagnosticClass]bool{unknownMixin: true},
+		pkgOverrides:          map[string]string{"a=b.proto": "a;a"},
+		featureEnablement:     map[featureID]struct{}{OrderedRoutingHeadersFeature: {}},
+	}
+	if diff := cmp.Diff(got.cfg, want, cmp.AllowUnexported(generatorConfig{}, conf.Config{})); diff != "" {
+		t.Errorf("got(-), want(+):\n%s", diff)
+	}
+
+	for _, tst := range []struct {
+		opts   Options
+		option string
+	}{
+		{opts: Options{PackagePath: "path", PackageName: "pkg", Transports: []Transport{"tcp"}}, option: "Transports"},
+		{opts: Options{PackagePath: "path", PackageName: "pkg", CopyrightYear: -1}, option: "CopyrightYear"},
+		{opts: Options{PackagePath: "path", PackageName: "pkg", Features: []string{"nope"}}, option: "Features"},
+		{opts: Options{PackageName: "pkg"}, option: "PackagePath"},
+		{opts: Options{PackagePath: "path", PackageName: "pkg", Module: "other"}, option: "Module"},
+		{opts: Options{PackagePath: "path", PackageName: "pkg", DIREGAPIC: true, RESTNumericEnums: true}, option: "RESTNumericEnums"},
+	} {
+		_, err := NewConfig(tst.opts)
+		var optErr *OptionError
+		if !errors.As(err, &optErr) || optErr.Option != tst.option {
+			t.Errorf("NewConfig(%+v) = %v, want *OptionError of %s", tst.opts, err, tst.option)
+		}
+	}
+}
+
+func TestParseOptionsFields(t *testing.T) {
+	got, err := ParseOptions("go-gapic-package=cloud.google.com/go/foo/apiv1;foo,module=cloud.google.com/go,transport=grpc+rest,metadata,copyright-year=2019,strict=unknown-mixin,Ma.proto=a;a,F_ordered_routing_headers,unknown-arg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Options{
+		PackagePath:      "cloud.google.com/go/foo/apiv1",
+		PackageName:      "foo",
+		Module:           "cloud.google.com/go",
+		Transports:       []Transport{GRPC, REST},
+		Metadata:         true,
+		CopyrightYear:    2019,
+		Strict:           []string{"unknown-mixin"},
+		PackageOverrides: map[string]string{"a.proto": "a;a"},
+		Features:         []string{"ordered_routing_headers"},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("got(-), want(+):\n%s", diff)
+	}
+
+	for _, tst := range []struct {
+		param  string
+		option string
+	}{
+		{param: "go-gapic-package=bogus", option: "PackagePath"},
+		{param: "copyright-year=last", option: "CopyrightYear"},
+		{param: "Ma.proto", option: "PackageOverrides"},
+		{param: "Ma.proto=", option: "PackageOverrides"},
+	} {
+		_, err := ParseOptions(tst.param)
+		var optErr *OptionError
+		if !errors.As(err, &optErr) || optErr.Option != tst.option {
+			t.Errorf("ParseOptions(%q) = %v, want *OptionError of %s", tst.param, err, tst.option)
+		}
+	}
+
+	// Every option but the name of the package, set along with its path, and
+	// the imports has a plugin argument.
+	typ := reflect.TypeOf(Options{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if _, ok := f.Tag.Lookup("plugin"); !ok && f.Name != "PackageName" && f.Name != "Imports" {
+			t.Errorf("Options.%s has no plugin tag", f.Name)
+		}
+	}
+}
+
+func TestReproducibleSourceDateEpoch(t *testing.T) {
+	for _, tst := range []struct {
+		epoch     string
+		unset     bool
+		wantYear  int
+		expectErr bool
+	}{
+		{epoch: "1546300800", wantYear: 2019},
+		{epoch: "1577836799", wantYear: 2019},
+		{epoch: "yesterday", expectErr: true},
+		{epoch: "-1", expectErr: true},
+		{unset: true, expectErr: true},
+	} {
+		t.Setenv("SOURCE_DATE_EPOCH", tst.epoch)
+		if tst.unset {
+			os.Unsetenv("SOURCE_DATE_EPOCH")
+		}
+		param := "reproducible,go-gapic-package=path;pkg"
+		cfg, err := configFromRequest(&param)
+		if tst.expectErr {
+			if err == nil {
+				t.Errorf("SOURCE_DATE_EPOCH=%q (unset: %v): expected error", tst.epoch, tst.unset)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("SOURCE_DATE_EPOCH=%q: got unexpected error: %v", tst.epoch, err)
+		}
+		if cfg.copyrightYear != tst.wantYear {
+			t.Errorf("SOURCE_DATE_EPOCH=%q: got year %d, want %d", tst.epoch, cfg.copyrightYear, tst.wantYear)
+		}
+	}
+
+	// An explicit year takes precedence over SOURCE_DATE_EPOCH.
+	t.Setenv("SOURCE_DATE_EPOCH", "1546300800")
+	param := "reproducible,copyright-year=2021,go-gapic-package=path;pkg"
+	cfg, err := configFromRequest(&param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.copyrightYear != 2021 {
+		t.Errorf("got year %d, want 2021", cfg.copyrightYear)
+	}
+}
+
 func TestValidateAndNormalizeOptions(t *testing.T) {
 	for _, tc := range []struct {
 		description string
@@ -310,20 +310,22 @@ func (g *generator) internalFetchSetup(outType *descriptorpb.DescriptorProto, ou
 	p("    req.PageToken = %s", tok)
 	p("  }")
 	p("  if pageSize > math.MaxInt32 {")
-	internalPageSizeSetter(p, pageSize, "math.MaxInt32")
+	g.internalPageSizeSetter(pageSize, "math.MaxInt32")
 	p("  } else if pageSize != 0 {")
-	internalPageSizeSetter(p, pageSize, "pageSize")
+	g.internalPageSizeSetter(pageSize, "pageSize")
 	p("  }")
 }
 
 // internalPageSizeSetter is a helper for injecting the value setting expression.
 // The incoming setVal is based on an incoming set int32-based value variable,
 // typically either labelled as 'pageSize' or 'math.MaxInt32'.
-func internalPageSizeSetter(p func(s string, a ...interface{}), pageSize *descriptorpb.FieldDescriptorProto, setVal string) {
+func (g *generator) internalPageSizeSetter(pageSize *descriptorpb.FieldDescriptorProto, setVal string) {
+	p := g.printf
+	optional := g.descInfo.HasExplicitPresence(pageSize)
 	cName := snakeToCamel(pageSize.GetName())
 	switch pageSize.GetType() {
 	case descriptorpb.FieldDescriptorProto_TYPE_INT32:
-		if pageSize.GetProto3Optional() {
+		if optional {
 			p("req.%s = proto.Int32(int32(%s))", cName, setVal)
 		} else {
 			if setVal != "math.MaxInt32" {
@@ -332,7 +334,7 @@ func internalPageSizeSetter(p func(s string, a ...interface{}), pageSize *descri
 			p("req.%s = %s", cName, setVal)
 		}
 	case descriptorpb.FieldDescriptorProto_TYPE_UINT32:
-		if pageSize.GetProto3Optional() {
+		if optional {
 			p("req.%s = proto.Uint32(uint32(%s))", cName, setVal)
 		} else {
 			p("req.%s = uint32(%s)", cName, setVal)
@@ -355,7 +357,7 @@ func (g *generator) pagingCall(servName string, m *descriptorpb.MethodDescriptor
 	outType := g.descInfo.Type[m.GetOutputType()].(*descriptorpb.DescriptorProto)
 
 	// We DON'T want to export the transport layers.
-	lowcaseServName := lowerFirst(servName + "GRPCClient")
+	lowcaseServName := g.grpcClientTypeName(servName)
 
 	inSpec, err := g.descInfo.ImportSpec(inType)
 	if err != nil {
@@ -368,7 +370,7 @@ func (g *generator) pagingCall(servName string, m *descriptorpb.MethodDescriptor
 	}
 
 	tok := "pageToken"
-	if isOptional(inType, "page_token") {
+	if g.isOptional(inType, "page_token") {
 		tok = fmt.Sprintf("proto.String(%s)", tok)
 	}
 
@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pathTemplate is a parsed path template, as used by resource name patterns
+// and routing annotations, e.g. projects/{project}/topics/{topic} or
+// {database=projects/*/databases/*}/documents/**.
+type pathTemplate []templateSegment
+
+// templateSegment is a segment of a path template: a literal, a * or **
+// wildcard, or a variable.
+type templateSegment struct {
+	// literal is the text of a literal segment, or the wildcard.
+	literal string
+	// variable is the name of a variable segment, whose own template is sub.
+	// A variable without a template, e.g. {project}, matches a single
+	// segment.
+	variable string
+	sub      pathTemplate
+}
+
+func (s templateSegment) isWildcard() bool {
+	return s.literal == "*" || s.literal == "**"
+}
+
+var templateVarName = regexp.MustCompile(`^[a-z_][_.a-z0-9]*$`)
+
+// parsePathTemplate parses the given path template. Verbs and segments mixing
+// literals and variables are not supported.
+func parsePathTemplate(tmpl string) (pathTemplate, error) {
+	if tmpl == "" {
+		return nil, fmt.Errorf("empty path template")
+	}
+	t, err := parseSegments(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template %q: %v", tmpl, err)
+	}
+	return t, nil
+}
+
+func parseSegments(tmpl string) (pathTemplate, error) {
+	var t pathTemplate
+	for tmpl != "" {
+		seg := tmpl
+		if strings.HasPrefix(tmpl, "{") {
+			end := strings.IndexByte(tmpl, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed variable")
+			}
+			seg = tmpl[:end+1]
+			if strings.Contains(seg[1:], "{") {
+				return nil, fmt.Errorf("nested variable in %s", seg)
+			}
+			if rest := tmpl[end+1:]; rest != "" && rest[0] != '/' {
+				return nil, fmt.Errorf("variable %s is followed by %q in the same segment", seg, rest)
+			}
+		} else if i := strings.IndexByte(tmpl, '/'); i >= 0 {
+			seg = tmpl[:i]
+		}
+		tmpl = strings.TrimPrefix(tmpl[len(seg):], "/")
+
+		switch {
+		case seg == "":
+			return nil, fmt.Errorf("empty segment")
+		case strings.HasPrefix(seg, "{"):
+			name, sub, hasSub := strings.Cut(seg[1:len(seg)-1], "=")
+			if !templateVarName.MatchString(name) {
+				return nil, fmt.Errorf("invalid variable name %q", name)
+			}
+			s := templateSegment{variable: name}
+			if hasSub {
+				var err error
+				if s.sub, err = parseSegments(sub); err != nil {
+					return nil, err
+				}
+				if len(s.sub) == 0 {
+					return nil, fmt.Errorf("empty template of variable %s", name)
+				}
+			}
+			t = append(t, s)
+		case strings.ContainsAny(seg, "{}:"):
+			return nil, fmt.Errorf("unsupported segment %q", seg)
+		default:
+			t = append(t, templateSegment{literal: seg})
+		}
+	}
+	return t, nil
+}
+
+// variables returns the names of the variables of t.
+func (t pathTemplate) variables() []string {
+	var vars []string
+	for _, s := range t {
+		if s.variable != "" {
+			vars = append(vars, s.variable)
+		}
+	}
+	return vars
+}
+
+// singleSegmentVariables reports whether every variable of t matches a
+// single segment, as {project} or {project=*} do.
+func (t pathTemplate) singleSegmentVariables() bool {
+	for _, s := range t {
+		if s.variable != "" && len(s.sub) > 0 && (len(s.sub) > 1 || s.sub[0].literal != "*") {
+			return false
+		}
+	}
+	return true
+}
+
+func (t pathTemplate) String() string {
+	var segs []string
+	for _, s := range t {
+		switch {
+		case s.variable == "":
+			segs = append(segs, s.literal)
+		case len(s.sub) == 0:
+			segs = append(segs, "{"+s.variable+"}")
+		default:
+			segs = append(segs, "{"+s.variable+"="+s.sub.String()+"}")
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// sample returns a value matching t, in which wildcards and variables
+// without a template match "sample".
+func (t pathTemplate) sample() string {
+	var segs []string
+	for _, s := range t {
+		switch {
+		case len(s.sub) > 0:
+			segs = append(segs, s.sub.sample())
+		case s.variable != "" || s.literal == "*":
+			segs = append(segs, "sample")
+		case s.literal == "**":
+			segs = append(segs, "sample/sample")
+		default:
+			segs = append(segs, s.literal)
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// routingRegexp returns the regular expression matching the values of a
+// routing parameter of template t, with a named capture per variable.
+func (t pathTemplate) routingRegexp() string {
+	// Without a variable template spanning segments, variables capture the
+	// whole rest of the value, and wildcards outside variables are dropped.
+	whole := true
+	for _, s := range t {
+		if len(s.sub) > 0 && (len(t) > 1 || len(s.sub) > 1) {
+			whole = false
+		}
+	}
+	if whole {
+		var segs []string
+		for _, s := range t {
+			switch {
+			case s.variable != "":
+				segs = append(segs, "(?P<"+s.variable+">.*)")
+			case s.isWildcard():
+				segs = append(segs, "")
+			default:
+				segs = append(segs, s.literal)
+			}
+		}
+		return strings.Join(segs, "/")
+	}
+	return t.segmentsRegexp()
+}
+
+func (t pathTemplate) segmentsRegexp() string {
+	var b strings.Builder
+	for i, s := range t {
+		if s.literal == "**" && i > 0 {
+			// A trailing ** also matches no segment at all.
+			b.WriteString("(?:/.*)?")
+			continue
+		}
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		switch {
+		case s.variable != "" && len(s.sub) > 0:
+			b.WriteString("(?P<" + s.variable + ">" + s.sub.segmentsRegexp() + ")")
+		case s.variable != "":
+			b.WriteString("(?P<" + s.variable + ">[^/]+)")
+		case s.literal == "**":
+			b.WriteString(".*")
+		case s.literal == "*":
+			b.WriteString("[^/]+")
+		default:
+			b.WriteString(s.literal)
+		}
+	}
+	return b.String()
+}
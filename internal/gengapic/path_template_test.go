@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParsePathTemplate(t *testing.T) {
+	for _, tst := range []struct {
+		tmpl string
+		want pathTemplate
+	}{
+		{
+			tmpl: "projects/{project}/topics/{topic=*}",
+			want: pathTemplate{
+				{literal: "projects"},
+				{variable: "project"},
+				{literal: "topics"},
+				{variable: "topic", sub: pathTemplate{{literal: "*"}}},
+			},
+		},
+		{
+			tmpl: "{database=projects/*/databases/*}/documents/**",
+			want: pathTemplate{
+				{variable: "database", sub: pathTemplate{{literal: "projects"}, {literal: "*"}, {literal: "databases"}, {literal: "*"}}},
+				{literal: "documents"},
+				{literal: "**"},
+			},
+		},
+	} {
+		got, err := parsePathTemplate(tst.tmpl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, tst.want, cmp.AllowUnexported(templateSegment{})); diff != "" {
+			t.Errorf("parsePathTemplate(%q) got(-),want(+):\n%s", tst.tmpl, diff)
+		}
+		if got.String() != tst.tmpl {
+			t.Errorf("parsePathTemplate(%q).String() = %q", tst.tmpl, got.String())
+		}
+	}
+}
+
+func TestParsePathTemplateErrors(t *testing.T) {
+	for _, tst := range []struct {
+		tmpl, want string
+	}{
+		{"", "empty path template"},
+		{"projects//topics", "empty segment"},
+		{"projects/{project", "unclosed variable"},
+		{"{name=projects/{project}}", "nested variable"},
+		{"{name=}", "empty template of variable name"},
+		{"{Project}", `invalid variable name "Project"`},
+		{"{project}~{topic}", "is followed by"},
+		{"projects/p{project}", "unsupported segment"},
+		{"things/*:cancel", "unsupported segment"},
+	} {
+		_, err := parsePathTemplate(tst.tmpl)
+		if err == nil || !strings.Contains(err.Error(), tst.want) {
+			t.Errorf("parsePathTemplate(%q) = %v, want error containing %q", tst.tmpl, err, tst.want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// requiredCheck is the check of a request field that must be set.
+type requiredCheck struct {
+	// path is the path of the field in the request, e.g. thing.display_name.
+	path string
+	// unset is the condition on req holding when the field is not set,
+	// including the conditions on the messages enclosing it, if any.
+	unset string
+}
+
+// requiredChecks returns the checks of the fields a request of m must set,
+// if the validation of required fields is enabled: the fields annotated with
+// google.api.field_behavior = REQUIRED, and the string fields bound to the
+// variables of the HTTP path of m. The required fields of a message field are
+// checked when that message is set.
+//
+// Numeric and bool fields without explicit presence cannot be told unset from
+// their zero value, so they are not checked.
+func (g *generator) requiredChecks(m *descriptorpb.MethodDescriptorProto) []requiredCheck {
+	if !g.featureEnabled(RequiredFieldValidationFeature) {
+		return nil
+	}
+
+	var checks []requiredCheck
+	seen := map[string]bool{}
+	add := func(path, unset string, guards []string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		checks = append(checks, requiredCheck{path: path, unset: strings.Join(append(guards, unset), " && ")})
+	}
+
+	// visiting holds the messages being walked, to stop at recursive ones.
+	visiting := map[string]bool{}
+	var walk func(typeName, prefix string, guards []string)
+	walk = func(typeName, prefix string, guards []string) {
+		msg, ok := g.descInfo.Type[typeName].(*descriptorpb.DescriptorProto)
+		if !ok || visiting[typeName] {
+			return
+		}
+		visiting[typeName] = true
+		defer delete(visiting, typeName)
+
+		for _, f := range msg.GetField() {
+			path := prefix + f.GetName()
+			if isRequired(f) {
+				if unset := g.unsetCondition(f, path); unset != "" {
+					add(path, unset, guards)
+				}
+			}
+			if f.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE && f.GetLabel() != descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+				set := fmt.Sprintf("req%s != nil", fieldGetter(path))
+				walk(f.GetTypeName(), path+".", append(guards[:len(guards):len(guards)], set))
+			}
+		}
+	}
+	walk(m.GetInputType(), "", nil)
+
+	if info := getHTTPInfo(m); info != nil {
+		for _, v := range httpPatternVarRegex.FindAllStringSubmatch(info.url, -1) {
+			f := g.lookupField(m.GetInputType(), v[1])
+			if f == nil || f.GetType() != descriptorpb.FieldDescriptorProto_TYPE_STRING || f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+				continue
+			}
+			add(v[1], fmt.Sprintf("req%s == \"\"", fieldGetter(v[1])), nil)
+		}
+	}
+	return checks
+}
+
+// unsetCondition returns the condition on req holding when field f, at the
+// given path, is not set, or "" if that cannot be told from its value.
+func (g *generator) unsetCondition(f *descriptorpb.FieldDescriptorProto, path string) string {
+	switch {
+	case f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED:
+		return fmt.Sprintf("len(req%s) == 0", fieldGetter(path))
+	case g.hasPointerScalar(f):
+		return fmt.Sprintf("req%s == nil", directAccess(path))
+	}
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return fmt.Sprintf("req%s == nil", fieldGetter(path))
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		return fmt.Sprintf("req%s == \"\"", fieldGetter(path))
+	case descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		return fmt.Sprintf("len(req%s) == 0", fieldGetter(path))
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		return fmt.Sprintf("req%s == 0", fieldGetter(path))
+	}
+	return ""
+}
+
+// validateRequiredFields generates the validation of the given checks of req,
+// returning the error of the missing fields with the statements ret.
+func (g *generator) validateRequiredFields(checks []requiredCheck, ret ...string) {
+	if len(checks) == 0 {
+		return
+	}
+	p := g.printf
+	g.aux.requiredFields = true
+
+	p("var missing []string")
+	for _, c := range checks {
+		p("if %s {", c.unset)
+		p("  missing = append(missing, %q)", c.path)
+		p("}")
+	}
+	p("if err := missingFieldsError(missing); err != nil {")
+	for _, r := range ret {
+		p("  %s", r)
+	}
+	p("}")
+}
+
+// genRequiredFieldsHelper generates the helper reporting the required fields
+// missing from a request, if any request is validated.
+func (g *generator) genRequiredFieldsHelper() {
+	if !g.aux.requiredFields {
+		return
+	}
+	p := g.printf
+
+	g.imports[pbinfo.ImportSpec{Path: "strings"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/codes"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc/status"}] = true
+
+	p("// missingFieldsError returns an InvalidArgument error listing the required")
+	p("// fields missing from a request, or nil if none is missing.")
+	p("func missingFieldsError(fields []string) error {")
+	p("  if len(fields) == 0 {")
+	p("    return nil")
+	p("  }")
+	p("  return status.Errorf(codes.InvalidArgument, \"missing required fields: %%s\", strings.Join(fields, \", \"))")
+	p("}")
+	p("")
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestRequiredChecks(t *testing.T) {
+	field := func(name string, typ descriptorpb.FieldDescriptorProto_Type, typeName string, required bool) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Type:     typ.Enum(),
+			Options:  &descriptorpb.FieldOptions{},
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		if required {
+			proto.SetExtension(f.Options, annotations.E_FieldBehavior, []annotations.FieldBehavior{annotations.FieldBehavior_REQUIRED})
+		}
+		return f
+	}
+	repeated := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		return f
+	}
+	optional := func(f *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+		f.OneofIndex = proto.Int32(0)
+		f.Proto3Optional = proto.Bool(true)
+		return f
+	}
+
+	part := &descriptorpb.DescriptorProto{
+		Name: proto.String("Part"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			field("id", descriptorpb.FieldDescriptorProto_TYPE_STRING, "", true),
+			// Recursive messages are walked once.
+			field("parent", descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".my.pkg.Part", true),
+		},
+	}
+	thing := &descriptorpb.DescriptorProto{
+		Name: proto.String("Thing"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			field("name", descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+			field("display_name", descriptorpb.FieldDescriptorProto_TYPE_STRING, "", true),
+			field("part", descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".my.pkg.Part", false),
+			repeated(field("parts", descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".my.pkg.Part", false)),
+		},
+	}
+	req := &descriptorpb.DescriptorProto{
+		Name: proto.String("UpdateThingRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			field("thing", descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".my.pkg.Thing", true),
+			field("etag", descriptorpb.FieldDescriptorProto_TYPE_BYTES, "", true),
+			field("state", descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".my.pkg.State", true),
+			repeated(field("tags", descriptorpb.FieldDescriptorProto_TYPE_STRING, "", true)),
+			field("count", descriptorpb.FieldDescriptorProto_TYPE_INT32, "", true),
+			optional(field("limit", descriptorpb.FieldDescriptorProto_TYPE_INT32, "", true)),
+			field("location", descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+		},
+		OneofDecl: []*descriptorpb.OneofDescriptorProto{{Name: proto.String("_limit")}},
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Package:     proto.String("my.pkg"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{part, thing, req},
+	}
+
+	opts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(opts, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{thing.name=projects/*/things/*}/{location}/{count}/{thing.display_name}"},
+	})
+	m := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("UpdateThing"),
+		InputType:  proto.String(".my.pkg.UpdateThingRequest"),
+		OutputType: proto.String(".my.pkg.Thing"),
+		Options:    opts,
+	}
+
+	g := &generator{
+		cfg: &generatorConfig{
+			featureEnablement: map[featureID]struct{}{RequiredFieldValidationFeature: {}},
+		},
+		descInfo: pbinfo.Info{
+			Type: map[string]pbinfo.ProtoType{
+				".my.pkg.Part":               part,
+				".my.pkg.Thing":              thing,
+				".my.pkg.UpdateThingRequest": req,
+			},
+			ParentFile: map[protoreflect.ProtoMessage]*descriptorpb.FileDescriptorProto{
+				part:  file,
+				thing: file,
+				req:   file,
+			},
+			ParentElement: map[pbinfo.ProtoType]pbinfo.ProtoType{},
+		},
+	}
+	for _, msg := range file.GetMessageType() {
+		for _, f := range msg.GetField() {
+			g.descInfo.ParentElement[f] = msg
+		}
+	}
+
+	got := g.requiredChecks(m)
+	want := []requiredCheck{
+		{path: "thing", unset: "req.GetThing() == nil"},
+		{path: "thing.display_name", unset: `req.GetThing() != nil && req.GetThing().GetDisplayName() == ""`},
+		{path: "thing.part.id", unset: `req.GetThing() != nil && req.GetThing().GetPart() != nil && req.GetThing().GetPart().GetId() == ""`},
+		{path: "thing.part.parent", unset: "req.GetThing() != nil && req.GetThing().GetPart() != nil && req.GetThing().GetPart().GetParent() == nil"},
+		{path: "etag", unset: "len(req.GetEtag()) == 0"},
+		{path: "state", unset: "req.GetState() == 0"},
+		{path: "tags", unset: "len(req.GetTags()) == 0"},
+		{path: "limit", unset: "req.Limit == nil"},
+		{path: "thing.name", unset: `req.GetThing().GetName() == ""`},
+		{path: "location", unset: `req.GetLocation() == ""`},
+	}
+	if diff := cmp.Diff(got, want, cmp.AllowUnexported(requiredCheck{})); diff != "" {
+		t.Errorf("requiredChecks() got(-),want(+):\n%s", diff)
+	}
+
+	// The scalars of proto2 files are pointers, so numeric fields are checked.
+	file.Syntax = proto.String("proto2")
+	var count *requiredCheck
+	for _, c := range g.requiredChecks(m) {
+		if c.path == "count" {
+			count = &c
+		}
+	}
+	if count == nil || count.unset != "req.Count == nil" {
+		t.Errorf("requiredChecks() of proto2 count = %+v, want req.Count == nil", count)
+	}
+
+	delete(g.cfg.featureEnablement, RequiredFieldValidationFeature)
+	if got := g.requiredChecks(m); len(got) != 0 {
+		t.Errorf("requiredChecks() = %v with the feature disabled, want none", got)
+	}
+}
+
+func TestRequiredFieldValidationGen(t *testing.T) {
+	for _, tst := range []struct {
+		param string
+		want  bool
+	}{
+		{param: "go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest"},
+		{param: "go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,F_required_field_validation", want: true},
+	} {
+		resp, err := gen(thingsRequest(tst.param))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string][]string{
+			"foo_client.go": {
+				`if req.GetParent() == "" {`,
+				`missing = append(missing, "parent")`,
+				"if err := missingFieldsError(missing); err != nil {",
+				`it.pageInfo, it.nextFunc = iterator.NewPageInfo(func(int, string) (string, error) { return "", err }, it.bufLen, it.takeBuf)`,
+			},
+			"auxiliary.go": {
+				"func missingFieldsError(fields []string) error {",
+				`return status.Errorf(codes.InvalidArgument, "missing required fields: %s", strings.Join(fields, ", "))`,
+			},
+		}
+		for _, f := range resp.GetFile() {
+			for _, w := range want[filepath.Base(f.GetName())] {
+				if got := strings.Contains(f.GetContent(), w); got != tst.want {
+					t.Errorf("%s: %s contains %q: %t, want %t", tst.param, f.GetName(), w, got, tst.want)
+				}
+			}
+		}
+	}
+}
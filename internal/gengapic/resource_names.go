@@ -0,0 +1,356 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// resourceNamesFile is the name of the file of the typed resource names, in
+// the output directory.
+const resourceNamesFile = "resources.go"
+
+// resourceName is a resource whose typed names are generated.
+type resourceName struct {
+	// typ is the resource type, e.g. pubsub.googleapis.com/Topic.
+	typ string
+	// element is the proto element declaring the resource, for diagnostics.
+	element string
+	// name is the Go name of the resource names, e.g. TopicName.
+	name     string
+	patterns []*resourcePattern
+}
+
+// resourcePattern is a pattern of a resource, named by its Go struct.
+type resourcePattern struct {
+	name string
+	tmpl pathTemplate
+	// fields are the Go names of the variables of tmpl.
+	fields []string
+}
+
+// genResourceNames generates the typed names of the resources annotated in
+// the given files, with google.api.resource or google.api.resource_definition,
+// and commits them to resources.go. A resource of a single pattern has a
+// struct, e.g. TopicName, and one of several patterns an interface of the
+// structs of its patterns.
+func (g *generator) genResourceNames(files []*descriptorpb.FileDescriptorProto) error {
+	var resources []*resourceName
+	seen := map[string]bool{}
+	add := func(rd *annotations.ResourceDescriptor, element string) {
+		if rd.GetType() == "" || seen[rd.GetType()] {
+			return
+		}
+		seen[rd.GetType()] = true
+		if r := g.resourceName(rd, element); r != nil {
+			resources = append(resources, r)
+		}
+	}
+	for _, f := range files {
+		defs := proto.GetExtension(f.GetOptions(), annotations.E_ResourceDefinition).([]*annotations.ResourceDescriptor)
+		for _, rd := range defs {
+			add(rd, rd.GetType())
+		}
+		var addMessages func(prefix string, msgs []*descriptorpb.DescriptorProto)
+		addMessages = func(prefix string, msgs []*descriptorpb.DescriptorProto) {
+			for _, m := range msgs {
+				if rd, ok := proto.GetExtension(m.GetOptions(), annotations.E_Resource).(*annotations.ResourceDescriptor); ok && rd != nil {
+					add(rd, prefix+m.GetName())
+				}
+				addMessages(prefix+m.GetName()+".", m.GetNestedType())
+			}
+		}
+		addMessages(f.GetPackage()+".", f.GetMessageType())
+	}
+	sort.SliceStable(resources, func(i, j int) bool { return resources[i].name < resources[j].name })
+
+	// The names declared by each resource must be unique in the package, the
+	// first resource declared taking them.
+	declared := map[string]string{}
+	var gen []*resourceName
+	for _, r := range resources {
+		names := r.declaredNames()
+		if conflict := firstDeclared(declared, names); conflict != "" {
+			g.diagnose(skippedResourceName, r.element, "%s of resource %s is already declared by resource %s", conflict, r.typ, declared[conflict])
+			continue
+		}
+		for _, n := range names {
+			declared[n] = r.typ
+		}
+		gen = append(gen, r)
+	}
+	if len(gen) == 0 {
+		return nil
+	}
+
+	g.imports[pbinfo.ImportSpec{Path: "fmt"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "strings"}] = true
+	for _, r := range gen {
+		g.resourceNameTypes(r)
+	}
+	_, err := g.commit(filepath.Join(g.cfg.outDir, resourceNamesFile), g.cfg.pkgName)
+	return err
+}
+
+// resourceName returns the resource of the given descriptor, with its
+// supported patterns, or nil if it has none.
+func (g *generator) resourceName(rd *annotations.ResourceDescriptor, element string) *resourceName {
+	singular := upperFirst(rd.GetSingular())
+	if singular == "" {
+		singular = rd.GetType()[strings.LastIndexByte(rd.GetType(), '/')+1:]
+	}
+	r := &resourceName{typ: rd.GetType(), element: element, name: singular + "Name"}
+	if !token.IsIdentifier(r.name) {
+		g.diagnose(skippedResourceName, element, "resource %s has no valid Go name", r.typ)
+		return nil
+	}
+
+	patterns := map[string]bool{}
+	names := map[string]bool{}
+	for _, pat := range rd.GetPattern() {
+		if patterns[pat] {
+			continue
+		}
+		patterns[pat] = true
+		p, err := newResourcePattern(pat)
+		if err != nil {
+			g.diagnose(skippedResourceName, element, "pattern %q of resource %s is skipped: %v", pat, r.typ, err)
+			continue
+		}
+		if names[p.name] {
+			g.diagnose(skippedResourceName, element, "pattern %q of resource %s is skipped: another pattern has the variables %s", pat, r.typ, strings.Join(p.tmpl.variables(), ", "))
+			continue
+		}
+		names[p.name] = true
+		r.patterns = append(r.patterns, p)
+	}
+
+	if len(r.patterns) > 1 {
+		// The interface of the resource takes its name.
+		r.patterns = slices.DeleteFunc(r.patterns, func(p *resourcePattern) bool {
+			if p.name != r.name {
+				return false
+			}
+			g.diagnose(skippedResourceName, element, "pattern %q of resource %s is skipped: its name %s is the name of the resource", p.tmpl, r.typ, r.name)
+			return true
+		})
+	}
+	switch len(r.patterns) {
+	case 0:
+		g.diagnose(skippedResourceName, element, "resource %s has no supported pattern", r.typ)
+		return nil
+	case 1:
+		r.patterns[0].name = r.name
+	}
+	return r
+}
+
+// newResourcePattern parses a resource pattern. Only the patterns of literal
+// segments and variables of a single segment, with at least one variable, are
+// supported. The pattern is named after its variables, e.g.
+// ProjectLocationName for projects/{project}/locations/{location}.
+func newResourcePattern(pat string) (*resourcePattern, error) {
+	tmpl, err := parsePathTemplate(pat)
+	if err != nil {
+		return nil, err
+	}
+	if !tmpl.singleSegmentVariables() {
+		return nil, fmt.Errorf("variables spanning several segments are not supported")
+	}
+	p := &resourcePattern{tmpl: tmpl}
+	for _, s := range tmpl {
+		if s.isWildcard() {
+			return nil, fmt.Errorf("wildcards outside variables are not supported")
+		}
+		if s.variable == "" {
+			continue
+		}
+		f := snakeToCamel(s.variable)
+		if !token.IsIdentifier(f) || f == "String" {
+			return nil, fmt.Errorf("variable %s has no valid Go field name", s.variable)
+		}
+		p.fields = append(p.fields, f)
+	}
+	if len(p.fields) == 0 {
+		return nil, fmt.Errorf("it has no variable")
+	}
+	p.name = strings.Join(p.fields, "") + "Name"
+	return p, nil
+}
+
+// declaredNames returns the Go names declared for the resource.
+func (r *resourceName) declaredNames() []string {
+	var names []string
+	for _, p := range r.patterns {
+		names = append(names, p.name, "New"+p.name, "Parse"+p.name)
+		if len(r.patterns) > 1 {
+			names = append(names, "Match"+p.name)
+		}
+	}
+	if len(r.patterns) > 1 {
+		names = append(names, r.name, "Parse"+r.name)
+	}
+	return names
+}
+
+func firstDeclared(declared map[string]string, names []string) string {
+	for _, n := range names {
+		if _, ok := declared[n]; ok {
+			return n
+		}
+	}
+	return ""
+}
+
+// resourceNameTypes generates the types and functions of the names of r.
+func (g *generator) resourceNameTypes(r *resourceName) {
+	p := g.printf
+
+	if len(r.patterns) > 1 {
+		p("// %s is a resource name of type %s,", r.name, r.typ)
+		p("// of one of the patterns:")
+		p("//")
+		for _, pat := range r.patterns {
+			p("//   - %s: %s", pat.tmpl, pat.name)
+		}
+		p("type %s interface {", r.name)
+		p("  fmt.Stringer")
+		p("  is%s()", r.name)
+		p("}")
+		p("")
+
+		p("// Parse%s parses a resource name of any of the patterns of %[1]s.", r.name)
+		p("func Parse%s(name string) (%[1]s, error) {", r.name)
+		for _, pat := range r.patterns {
+			p("  if n, err := Parse%s(name); err == nil {", pat.name)
+			p("    return n, nil")
+			p("  }")
+		}
+		p("  return nil, fmt.Errorf(\"resource name %%q matches no pattern of %s\", name)", r.typ)
+		p("}")
+		p("")
+	}
+
+	for _, pat := range r.patterns {
+		g.resourcePatternType(r, pat)
+	}
+}
+
+// resourcePatternType generates the struct of the names of pattern pat of r,
+// with its constructor, parser and String method, and, if r has several
+// patterns, its matcher.
+func (g *generator) resourcePatternType(r *resourceName, pat *resourcePattern) {
+	p := g.printf
+	multi := len(r.patterns) > 1
+
+	p("// %s is a resource name of type %s,", pat.name, r.typ)
+	p("// of the pattern %s.", pat.tmpl)
+	if multi {
+		p("// It implements %s.", r.name)
+	}
+	p("type %s struct {", pat.name)
+	for _, f := range pat.fields {
+		p("  %s string", f)
+	}
+	p("}")
+	p("")
+
+	var params, inits []string
+	for _, f := range pat.fields {
+		param := lowerFirst(f)
+		if token.IsKeyword(param) {
+			param += "ID"
+		}
+		params = append(params, param)
+		inits = append(inits, fmt.Sprintf("%s: %s", f, param))
+	}
+	p("// New%s returns the %[1]s of the given IDs.", pat.name)
+	p("func New%s(%s string) %[1]s {", pat.name, strings.Join(params, ", "))
+	p("  return %s{%s}", pat.name, strings.Join(inits, ", "))
+	p("}")
+	p("")
+
+	var conds, vals, str []string
+	var field int
+	conds = append(conds, fmt.Sprintf("len(s) != %d", len(pat.tmpl)))
+	for i, s := range pat.tmpl {
+		if i > 0 {
+			str = append(str, `"/"`)
+		}
+		if s.variable == "" {
+			conds = append(conds, fmt.Sprintf("s[%d] != %q", i, s.literal))
+			str = append(str, fmt.Sprintf("%q", s.literal))
+			continue
+		}
+		f := pat.fields[field]
+		field++
+		conds = append(conds, fmt.Sprintf(`s[%d] == ""`, i))
+		vals = append(vals, fmt.Sprintf("%s: s[%d]", f, i))
+		str = append(str, "n."+f)
+	}
+	p("// Parse%s parses a resource name of the pattern %s.", pat.name, pat.tmpl)
+	p("func Parse%s(name string) (%[1]s, error) {", pat.name)
+	p("  s := strings.Split(name, \"/\")")
+	p("  if %s {", strings.Join(conds, " || "))
+	p("    return %s{}, fmt.Errorf(\"resource name %%q does not match the pattern %s\", name)", pat.name, pat.tmpl)
+	p("  }")
+	p("  return %s{%s}, nil", pat.name, strings.Join(vals, ", "))
+	p("}")
+	p("")
+
+	if multi {
+		p("// Match%s reports whether name is of the pattern %s.", pat.name, pat.tmpl)
+		p("func Match%s(name string) bool {", pat.name)
+		p("  _, err := Parse%s(name)", pat.name)
+		p("  return err == nil")
+		p("}")
+		p("")
+	}
+
+	p("// String returns the resource name.")
+	p("func (n %s) String() string {", pat.name)
+	p("  return %s", joinStringLiterals(str))
+	p("}")
+	p("")
+
+	if multi {
+		p("func (%s) is%s() {}", pat.name, r.name)
+		p("")
+	}
+}
+
+// joinStringLiterals returns the Go expression concatenating the given
+// operands, merging adjacent string literals.
+func joinStringLiterals(operands []string) string {
+	var merged []string
+	for _, o := range operands {
+		if n := len(merged); n > 0 && strings.HasPrefix(o, `"`) && strings.HasPrefix(merged[n-1], `"`) {
+			merged[n-1] = merged[n-1][:len(merged[n-1])-1] + o[1:]
+			continue
+		}
+		merged = append(merged, o)
+	}
+	return strings.Join(merged, " + ")
+}
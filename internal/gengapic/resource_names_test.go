@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func resourceMessage(name string, rd *annotations.ResourceDescriptor) *descriptorpb.DescriptorProto {
+	opts := &descriptorpb.MessageOptions{}
+	proto.SetExtension(opts, annotations.E_Resource, rd)
+	return &descriptorpb.DescriptorProto{Name: proto.String(name), Options: opts}
+}
+
+func TestGenResourceNames(t *testing.T) {
+	fileOpts := &descriptorpb.FileOptions{}
+	proto.SetExtension(fileOpts, annotations.E_ResourceDefinition, []*annotations.ResourceDescriptor{
+		{
+			Type:     "example.googleapis.com/Location",
+			Pattern:  []string{"projects/{project}/locations/{location}"},
+			Singular: "location",
+		},
+		{
+			// Takes ThingName from the resource of the Thing message.
+			Type:     "other.googleapis.com/Thing",
+			Pattern:  []string{"things/{thing}"},
+			Singular: "thing",
+		},
+	})
+	thing := resourceMessage("Thing", &annotations.ResourceDescriptor{
+		Type: "example.googleapis.com/Thing",
+		Pattern: []string{
+			"projects/{project}/things/{thing}",
+			"projects/{project}/locations/{location}/things/{thing}",
+			"organizations/{organization}/things/{thing=*}",
+			"projects/{project}/things/{thing}",
+			"folders/{project}/things/{thing}",
+		},
+		Singular: "thing",
+	})
+	thing.NestedType = []*descriptorpb.DescriptorProto{
+		resourceMessage("Part", &annotations.ResourceDescriptor{
+			Type:    "example.googleapis.com/Part",
+			Pattern: []string{"projects/{project}/things/{thing}/parts/{part}/settings", "parts/{part=**}"},
+		}),
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("my/pkg/thing.proto"),
+		Package: proto.String("my.pkg"),
+		Options: fileOpts,
+		MessageType: []*descriptorpb.DescriptorProto{
+			thing,
+			resourceMessage("Deleted", &annotations.ResourceDescriptor{
+				Type:    "example.googleapis.com/Deleted",
+				Pattern: []string{"_deleted-thing_"},
+			}),
+			resourceMessage("Bucket", &annotations.ResourceDescriptor{
+				Type:    "example.googleapis.com/Bucket",
+				Pattern: []string{"projects/{project}/buckets/{bucket}", "projects/{project}/buckets/{bucket}/objects/{object}", "{bucket}"},
+			}),
+		},
+	}
+
+	g := &generator{
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg: &generatorConfig{
+			pkgName:       "mypkg",
+			outDir:        "mypkg",
+			copyrightYear: 2026,
+		},
+	}
+	if err := g.genResourceNames([]*descriptorpb.FileDescriptorProto{file}); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.resp.File) != 1 || g.resp.File[0].GetName() != filepath.Join("mypkg", resourceNamesFile) {
+		t.Fatalf("got files %v, want %s", g.resp.File, resourceNamesFile)
+	}
+	txtdiff.Diff(t, g.resp.File[0].GetContent(), filepath.Join("testdata", "resource_names.want"))
+
+	var got []string
+	for _, d := range g.diagnostics {
+		if d.Class != skippedResourceName {
+			t.Errorf("got diagnostic class %s, want %s", d.Class, skippedResourceName)
+		}
+		got = append(got, d.Element+": "+d.Reason)
+	}
+	want := []string{
+		`my.pkg.Thing: pattern "folders/{project}/things/{thing}" of resource example.googleapis.com/Thing is skipped: another pattern has the variables project, thing`,
+		`my.pkg.Thing.Part: pattern "parts/{part=**}" of resource example.googleapis.com/Part is skipped: variables spanning several segments are not supported`,
+		`my.pkg.Deleted: pattern "_deleted-thing_" of resource example.googleapis.com/Deleted is skipped: it has no variable`,
+		`my.pkg.Deleted: resource example.googleapis.com/Deleted has no supported pattern`,
+		`my.pkg.Bucket: pattern "{bucket}" of resource example.googleapis.com/Bucket is skipped: its name BucketName is the name of the resource`,
+		`my.pkg.Thing: ThingName of resource example.googleapis.com/Thing is already declared by resource other.googleapis.com/Thing`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got diagnostics:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestGenResourceNamesFeature(t *testing.T) {
+	for _, tst := range []struct {
+		param string
+		want  bool
+	}{
+		{param: "go-gapic-package=github.com/googleapis/mypkg;mypkg"},
+		{param: "go-gapic-package=github.com/googleapis/mypkg;mypkg,F_resource_names", want: true},
+	} {
+		req := thingsRequest(tst.param)
+		for _, f := range req.GetProtoFile() {
+			for _, m := range f.GetMessageType() {
+				if m.GetName() == "Thing" {
+					m.Options = resourceMessage("Thing", &annotations.ResourceDescriptor{
+						Type:    "my.googleapis.com/Thing",
+						Pattern: []string{"projects/{project}/things/{thing}"},
+					}).GetOptions()
+				}
+			}
+		}
+		resp, err := gen(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got bool
+		for _, f := range resp.GetFile() {
+			if filepath.Base(f.GetName()) == resourceNamesFile {
+				got = strings.Contains(f.GetContent(), "func ParseThingName(name string) (ThingName, error) {")
+			}
+		}
+		if got != tst.want {
+			t.Errorf("%s: got ThingName %t, want %t", tst.param, got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reststream contains the transports used by REST clients for
+// client-streaming and bidirectional streaming RPCs.
+//
+// Client streams are sent as the elements of a JSON array in a chunked HTTP
+// request body. Bidirectional streams are carried by a WebSocket connection,
+// one JSON message per text frame, with the client's Close frame ending the
+// request stream.
+//
+// The declarations following the import block are copied verbatim into the
+// generated auxiliary.go of any client package with such RPCs, so they must
+// depend only on the standard library and must not reference anything
+// declared outside of this file.
+package reststream
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// requestStream sends a client stream as the elements of a JSON array in the
+// body of a single HTTP request.
+type requestStream struct {
+	pw     *io.PipeWriter
+	sent   int
+	closed bool
+
+	done chan struct{}
+	resp *http.Response
+	err  error
+}
+
+// newRequestStream starts the request made by do, which body is the stream.
+func newRequestStream(do func(body io.Reader) (*http.Response, error)) *requestStream {
+	pr, pw := io.Pipe()
+	s := &requestStream{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		s.resp, s.err = do(pr)
+		// Fail further writes once the request is over, as nothing reads them.
+		if s.err != nil {
+			pr.CloseWithError(s.err)
+		} else {
+			pr.CloseWithError(io.EOF)
+		}
+	}()
+	return s
+}
+
+// send writes msg, a JSON encoded message, to the stream. It returns io.EOF
+// if the request is already over, the cause of which is then reported by
+// response.
+func (s *requestStream) send(msg []byte) error {
+	if s.closed {
+		return errors.New("send on a closed stream")
+	}
+	sep := byte(',')
+	if s.sent == 0 {
+		sep = '['
+	}
+	b := make([]byte, 0, len(msg)+1)
+	b = append(append(b, sep), msg...)
+	if _, err := s.pw.Write(b); err != nil {
+		return io.EOF
+	}
+	s.sent++
+	return nil
+}
+
+// closeSend ends the stream.
+func (s *requestStream) closeSend() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	end := "]"
+	if s.sent == 0 {
+		end = "[]"
+	}
+	// An error means the request is already over, which is reported by
+	// response.
+	s.pw.Write([]byte(end))
+	return s.pw.Close()
+}
+
+// response waits for the response to the request.
+func (s *requestStream) response() (*http.Response, error) {
+	<-s.done
+	return s.resp, s.err
+}
+
+// webSocketGUID is used to compute the Sec-WebSocket-Accept header, see RFC
+// 6455 section 1.3.
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket frame opcodes.
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+)
+
+// wsCloseNormal is the status code of a WebSocket connection closed after
+// its purpose was fulfilled.
+const wsCloseNormal = 1000
+
+// maxWebSocketMessage bounds the size of a message received from a stream.
+const maxWebSocketMessage = 64 << 20
+
+// webSocketCloseError is returned by recv when the server closes the stream
+// with a status other than a normal closure.
+type webSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *webSocketCloseError) Error() string {
+	return fmt.Sprintf("stream closed by the server with status %d: %s", e.Code, e.Reason)
+}
+
+// webSocketStream is the client side of a WebSocket connection carrying a
+// bidirectional stream.
+type webSocketStream struct {
+	header http.Header
+	conn   io.ReadWriteCloser
+	r      *bufio.Reader
+	stop   func() bool
+
+	// wmu serializes the frames written by send, closeSend, and the replies to
+	// control frames made by recv.
+	wmu       sync.Mutex
+	sentClose bool
+
+	// recvErr is the error that ended the response stream, if any.
+	recvErr error
+}
+
+// dialWebSocket opens a WebSocket connection with the request made by do, to
+// which it adds the given header. do reports the error carried by a response
+// refusing the upgrade, if any.
+func dialWebSocket(ctx context.Context, do func(header http.Header) (*http.Response, error)) (*webSocketStream, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	header := http.Header{}
+	header.Set("Connection", "Upgrade")
+	header.Set("Upgrade", "websocket")
+	header.Set("Sec-WebSocket-Version", "13")
+	header.Set("Sec-WebSocket-Key", key)
+
+	resp, err := do(header)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, fmt.Errorf("server responded with %q to a WebSocket upgrade", resp.Status)
+	}
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("the HTTP client does not support WebSocket connections")
+	}
+	h := sha1.Sum([]byte(key + webSocketGUID))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(h[:]) {
+		conn.Close()
+		return nil, errors.New("server responded with an invalid Sec-WebSocket-Accept header")
+	}
+
+	s := &webSocketStream{
+		header: resp.Header,
+		conn:   conn,
+		r:      bufio.NewReader(conn),
+	}
+	// The connection outlives the request, so it is closed explicitly when
+	// the context is done.
+	s.stop = context.AfterFunc(ctx, func() { conn.Close() })
+	return s, nil
+}
+
+// send writes msg, a JSON encoded message, to the stream in a text frame.
+func (s *webSocketStream) send(msg []byte) error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	if s.sentClose {
+		return errors.New("send on a closed stream")
+	}
+	if err := s.writeFrame(wsText, msg); err != nil {
+		// The cause is reported by recv.
+		return io.EOF
+	}
+	return nil
+}
+
+// closeSend ends the request stream with a Close frame. The server keeps
+// sending messages until it closes the connection in turn.
+func (s *webSocketStream) closeSend() error {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	return s.writeCloseLocked(wsCloseNormal)
+}
+
+func (s *webSocketStream) writeCloseLocked(code int) error {
+	if s.sentClose {
+		return nil
+	}
+	s.sentClose = true
+	return s.writeFrame(wsClose, binary.BigEndian.AppendUint16(nil, uint16(code)))
+}
+
+// recv reads the next message of the stream. It returns io.EOF once the
+// server has closed the stream normally.
+func (s *webSocketStream) recv() ([]byte, error) {
+	if s.recvErr != nil {
+		return nil, s.recvErr
+	}
+	msg, err := s.readMessage()
+	if err != nil {
+		s.recvErr = err
+		s.close()
+	}
+	return msg, err
+}
+
+func (s *webSocketStream) readMessage() ([]byte, error) {
+	var msg []byte
+	started := false
+	for {
+		fin, op, payload, err := s.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case wsPing:
+			s.wmu.Lock()
+			err := s.writeFrame(wsPong, payload)
+			s.wmu.Unlock()
+			if err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			code := wsCloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			// Echo the Close frame, as the closing handshake requires.
+			s.wmu.Lock()
+			s.writeCloseLocked(code)
+			s.wmu.Unlock()
+			if code != wsCloseNormal {
+				reason := ""
+				if len(payload) > 2 {
+					reason = string(payload[2:])
+				}
+				return nil, &webSocketCloseError{Code: code, Reason: reason}
+			}
+			return nil, io.EOF
+		case wsText, wsBinary:
+			if started {
+				return nil, errors.New("invalid WebSocket frame: interleaved message")
+			}
+			started = true
+		case wsContinuation:
+			if !started {
+				return nil, errors.New("invalid WebSocket frame: unexpected continuation")
+			}
+		default:
+			return nil, fmt.Errorf("invalid WebSocket frame: unknown opcode %d", op)
+		}
+		if len(msg)+len(payload) > maxWebSocketMessage {
+			return nil, errors.New("stream message too large")
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads a single frame, unmasking its payload if needed.
+func (s *webSocketStream) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(s.r, hdr[:]); err != nil {
+		return false, 0, nil, unexpectedEOF(err)
+	}
+	fin = hdr[0]&0x80 != 0
+	op = hdr[0] & 0x0F
+	masked := hdr[1]&0x80 != 0
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(s.r, ext[:]); err != nil {
+			return false, 0, nil, unexpectedEOF(err)
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(s.r, ext[:]); err != nil {
+			return false, 0, nil, unexpectedEOF(err)
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxWebSocketMessage {
+		return false, 0, nil, errors.New("stream message too large")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(s.r, mask[:]); err != nil {
+			return false, 0, nil, unexpectedEOF(err)
+		}
+	}
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(s.r, payload); err != nil {
+		return false, 0, nil, unexpectedEOF(err)
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, op, payload, nil
+}
+
+// writeFrame writes a single, final frame. Frames sent by a client must be
+// masked. It must be called with wmu held.
+func (s *webSocketStream) writeFrame(op byte, payload []byte) error {
+	b := make([]byte, 0, 14+len(payload))
+	b = append(b, 0x80|op)
+	switch n := len(payload); {
+	case n < 126:
+		b = append(b, 0x80|byte(n))
+	case n <= 0xFFFF:
+		b = append(b, 0x80|126)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, 0x80|127)
+		b = binary.BigEndian.AppendUint64(b, uint64(n))
+	}
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	b = append(b, mask[:]...)
+	for i, c := range payload {
+		b = append(b, c^mask[i%4])
+	}
+	_, err := s.conn.Write(b)
+	return err
+}
+
+// close releases the connection.
+func (s *webSocketStream) close() error {
+	s.stop()
+	return s.conn.Close()
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,353 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reststream
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	b, _ := io.ReadAll(resp.Body)
+	return fmt.Errorf("status %d: %s", resp.StatusCode, b)
+}
+
+// collectHandler responds with the concatenation of the "content" of the
+// messages of the JSON array it receives.
+func collectHandler(w http.ResponseWriter, r *http.Request) {
+	if len(r.TransferEncoding) == 0 || r.TransferEncoding[0] != "chunked" {
+		http.Error(w, "request stream must be chunked", http.StatusBadRequest)
+		return
+	}
+	var msgs []struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&msgs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var parts []string
+	for _, m := range msgs {
+		if m.Content == "fail" {
+			http.Error(w, "failed", http.StatusConflict)
+			return
+		}
+		parts = append(parts, m.Content)
+	}
+	fmt.Fprintf(w, `{"content":%q}`, strings.Join(parts, " "))
+}
+
+func requestStreamTo(srv *httptest.Server) *requestStream {
+	return newRequestStream(func(body io.Reader) (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, body)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkStatus(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	})
+}
+
+func TestRequestStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(collectHandler))
+	defer srv.Close()
+
+	for _, tst := range []struct {
+		name    string
+		msgs    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "messages", msgs: []string{"hello", "streaming", "world"}, want: `{"content":"hello streaming world"}`},
+		{name: "empty", want: `{"content":""}`},
+		{name: "error", msgs: []string{"hello", "fail"}, wantErr: true},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			s := requestStreamTo(srv)
+			for _, m := range tst.msgs {
+				if err := s.send([]byte(fmt.Sprintf(`{"content":%q}`, m))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.closeSend(); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := s.response()
+			if (err != nil) != tst.wantErr {
+				t.Fatalf("response() error = %v, wantErr %v", err, tst.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer resp.Body.Close()
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tst.want {
+				t.Errorf("got %s, want %s", got, tst.want)
+			}
+		})
+	}
+}
+
+func TestRequestStream_EarlyResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	s := requestStreamTo(srv)
+	// Once the request is over, sending fails with io.EOF. The server may
+	// read part of the request before responding.
+	msg := []byte(`{"content":"` + strings.Repeat("x", 4096) + `"}`)
+	var err error
+	for i := 0; i < 10000 && err == nil; i++ {
+		err = s.send(msg)
+	}
+	if err != io.EOF {
+		t.Errorf("send() error = %v, want io.EOF", err)
+	}
+	s.closeSend()
+	if _, err := s.response(); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("response() error = %v, want the server error", err)
+	}
+}
+
+// wsServerConn is the server side of a WebSocket connection.
+type wsServerConn struct {
+	rw *bufio.ReadWriter
+}
+
+func (c *wsServerConn) readFrame() (byte, []byte, error) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.rw, hdr[:]); err != nil {
+		return 0, nil, err
+	}
+	if hdr[1]&0x80 == 0 {
+		return 0, nil, errors.New("client frame is not masked")
+	}
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(c.rw, ext[:])
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(c.rw, ext[:])
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	var mask [4]byte
+	io.ReadFull(c.rw, mask[:])
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return hdr[0] & 0x0F, payload, nil
+}
+
+// writeFrames writes the payloads as the fragments of a single message.
+func (c *wsServerConn) writeFrames(op byte, payloads ...[]byte) error {
+	for i, p := range payloads {
+		b := byte(wsContinuation)
+		if i == 0 {
+			b = op
+		}
+		if i == len(payloads)-1 {
+			b |= 0x80
+		}
+		if len(p) < 126 {
+			c.rw.Write([]byte{b, byte(len(p))})
+		} else {
+			c.rw.Write(binary.BigEndian.AppendUint16([]byte{b, 126}, uint16(len(p))))
+		}
+		c.rw.Write(p)
+	}
+	return c.rw.Flush()
+}
+
+// webSocketHandler upgrades the connection and passes it to serve.
+func webSocketHandler(t *testing.T, serve func(c *wsServerConn)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			http.Error(w, "not a websocket request", http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		h := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + webSocketGUID))
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nX-Goog-Custom: custom\r\nSec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(h[:]))
+		rw.Flush()
+		serve(&wsServerConn{rw: rw})
+	}
+}
+
+func dialTo(ctx context.Context, srv *httptest.Server) (*webSocketStream, error) {
+	return dialWebSocket(ctx, func(header http.Header) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header = header
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			defer resp.Body.Close()
+			return resp, checkStatus(resp)
+		}
+		return resp, nil
+	})
+}
+
+func TestWebSocketStream(t *testing.T) {
+	// The server echoes every text message, fragmented and preceded by a
+	// ping, until the client's Close frame.
+	srv := httptest.NewServer(webSocketHandler(t, func(c *wsServerConn) {
+		for {
+			op, payload, err := c.readFrame()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			switch op {
+			case wsText:
+				c.writeFrames(wsPing, []byte("ping"))
+				half := len(payload) / 2
+				c.writeFrames(wsText, payload[:half], payload[half:])
+			case wsPong:
+				if string(payload) != "ping" {
+					t.Errorf("got pong %q", payload)
+				}
+			case wsClose:
+				c.writeFrames(wsClose, payload)
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	s, err := dialTo(context.Background(), srv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.header.Get("X-Goog-Custom"); got != "custom" {
+		t.Errorf("got header %q", got)
+	}
+	msgs := []string{`{"content":"hello"}`, `{"content":"` + strings.Repeat("x", 70000) + `"}`}
+	for _, m := range msgs {
+		if err := s.send([]byte(m)); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != m {
+			t.Errorf("recv() = %.40s, want %.40s", got, m)
+		}
+	}
+	if err := s.closeSend(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.send([]byte(msgs[0])); err == nil {
+		t.Error("send() after closeSend() succeeded")
+	}
+	if _, err := s.recv(); err != io.EOF {
+		t.Errorf("recv() error = %v, want io.EOF", err)
+	}
+}
+
+func TestWebSocketStream_Errors(t *testing.T) {
+	t.Run("upgrade refused", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "denied", http.StatusForbidden)
+		}))
+		defer srv.Close()
+		if _, err := dialTo(context.Background(), srv); err == nil || !strings.Contains(err.Error(), "denied") {
+			t.Errorf("dialWebSocket() error = %v, want the server error", err)
+		}
+	})
+
+	t.Run("closed with error", func(t *testing.T) {
+		srv := httptest.NewServer(webSocketHandler(t, func(c *wsServerConn) {
+			c.readFrame()
+			c.writeFrames(wsClose, append(binary.BigEndian.AppendUint16(nil, 1011), "internal error"...))
+			c.readFrame()
+		}))
+		defer srv.Close()
+		s, err := dialTo(context.Background(), srv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.send([]byte(`{}`))
+		_, err = s.recv()
+		var cerr *webSocketCloseError
+		if !errors.As(err, &cerr) || cerr.Code != 1011 || cerr.Reason != "internal error" {
+			t.Errorf("recv() error = %v, want close status 1011", err)
+		}
+		if _, err2 := s.recv(); err2 != err {
+			t.Errorf("second recv() error = %v, want %v", err2, err)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		done := make(chan struct{})
+		srv := httptest.NewServer(webSocketHandler(t, func(c *wsServerConn) {
+			<-done
+		}))
+		defer srv.Close()
+		defer close(done)
+		ctx, cancel := context.WithCancel(context.Background())
+		s, err := dialTo(ctx, srv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		if _, err := s.recv(); err == nil {
+			t.Error("recv() succeeded after the context was canceled")
+		}
+	})
+}
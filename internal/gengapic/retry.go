@@ -0,0 +1,334 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+
+	conf "github.com/googleapis/gapic-generator-go/internal/grpc_service_config"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// maxPolicyAttempts is the cap the gRPC service config puts on the
+// max_attempts of retry and hedging policies.
+const maxPolicyAttempts = 5
+
+// capAttempts returns the given max_attempts of a retry or hedging policy,
+// capped like the gRPC service config specifies.
+func capAttempts(n uint32) int {
+	if n > maxPolicyAttempts {
+		return maxPolicyAttempts
+	}
+	return int(n)
+}
+
+// diagnoseGRPCServiceConfig records the method_config entries of the gRPC
+// service config which match no RPC, and those whose max_attempts is capped.
+func (g *generator) diagnoseGRPCServiceConfig() {
+	c := g.cfg.gRPCServiceConfig
+	for _, name := range c.Names() {
+		s, m := name.GetService(), name.GetMethod()
+		// A name without a service is the default of all of the RPCs.
+		if s == "" {
+			continue
+		}
+		element := s
+		if m != "" {
+			element += "." + m
+		}
+
+		serv, ok := g.descInfo.Serv["."+s]
+		if ok && m != "" {
+			ok = false
+			for _, sm := range serv.GetMethod() {
+				ok = ok || sm.GetName() == m
+			}
+		}
+		if !ok {
+			g.diagnose(unmatchedMethodConfig, element, "method_config name matches no RPC, it is ignored")
+			continue
+		}
+
+		if rp, ok := c.RetryPolicy(s, m); ok && rp.GetMaxAttempts() > maxPolicyAttempts {
+			g.diagnose(cappedMaxAttempts, element, "retry policy max_attempts %d is capped to %d", rp.GetMaxAttempts(), maxPolicyAttempts)
+		}
+		if hp, ok := c.HedgingPolicy(s, m); ok && hp.GetMaxAttempts() > maxPolicyAttempts {
+			g.diagnose(cappedMaxAttempts, element, "hedging policy max_attempts %d is capped to %d", hp.GetMaxAttempts(), maxPolicyAttempts)
+		}
+	}
+}
+
+// retryMaxAttempts returns the maximum number of attempts, including the
+// original one, allowed by the given retry policy. Zero means that the policy
+// does not limit the number of attempts.
+func retryMaxAttempts(rp *conf.MethodConfig_RetryPolicy) int {
+	return capAttempts(rp.GetMaxAttempts())
+}
+
+// waitForReady reports whether the given RPC waits for the service to be
+// ready, rather than failing fast when it cannot connect to it, as declared by
+// the wait_for_ready of the gRPC service config.
+func (g *generator) waitForReady(m *descriptorpb.MethodDescriptorProto) bool {
+	sFQN := g.fqn(g.descInfo.ParentElement[m])
+	wfr, ok := g.cfg.gRPCServiceConfig.WaitForReady(sFQN, m.GetName())
+	return ok && wfr
+}
+
+// retryerWrappers returns the opening and the closing of the calls to the
+// helpers wrapping the gax.Retryer built from the given retry policy of the
+// given RPC, for the given transport. The helpers enforce what gax does not
+// support: the retry throttling policy, the max_attempts of the retry policy
+// and, for REST, wait_for_ready.
+func (g *generator) retryerWrappers(m *descriptorpb.MethodDescriptorProto, rp *conf.MethodConfig_RetryPolicy, t transport) (string, string) {
+	var open, close string
+	if g.retryThrottled() {
+		open, close = "throttled(throttle, "+open, close+")"
+	}
+	if n := retryMaxAttempts(rp); n > 0 {
+		open, close = "withMaxAttempts("+open, close+fmt.Sprintf(", %d)", n)
+		g.aux.maxAttempts = true
+	}
+	if t == rest && g.waitForReady(m) {
+		open, close = "withWaitForReady("+open, close+")"
+		g.aux.waitForReady = true
+	}
+	return open, close
+}
+
+// genRetryHelpers generates the helpers enforcing the retry policies of the
+// gRPC service config that gax does not support, if any was used.
+func (g *generator) genRetryHelpers() {
+	p := g.printf
+
+	if g.aux.maxAttempts {
+		g.imports[pbinfo.ImportSpec{Path: "time"}] = true
+		g.imports[pbinfo.ImportSpec{Path: "github.com/googleapis/gax-go/v2"}] = true
+
+		p("// maxAttemptsRetryer stops the retries of a gax.Retryer once a call was")
+		p("// attempted maxAttempts times, as allowed by the retry policy of the call.")
+		p("type maxAttemptsRetryer struct {")
+		p("  retryer     gax.Retryer")
+		p("  attempts    int")
+		p("  maxAttempts int")
+		p("}")
+		p("")
+		p("func withMaxAttempts(r gax.Retryer, maxAttempts int) gax.Retryer {")
+		p("  return &maxAttemptsRetryer{retryer: r, maxAttempts: maxAttempts}")
+		p("}")
+		p("")
+		p("func (r *maxAttemptsRetryer) Retry(err error) (time.Duration, bool) {")
+		p("  // The wrapped Retryer sees every failure, even the one of the last")
+		p("  // attempt, since it may account for them.")
+		p("  pause, ok := r.retryer.Retry(err)")
+		p("  r.attempts++")
+		p("  if !ok || r.attempts >= r.maxAttempts {")
+		p("    return 0, false")
+		p("  }")
+		p("  return pause, true")
+		p("}")
+		p("")
+	}
+
+	if g.aux.waitForReady {
+		g.imports[pbinfo.ImportSpec{Path: "errors"}] = true
+		g.imports[pbinfo.ImportSpec{Path: "net"}] = true
+		g.imports[pbinfo.ImportSpec{Path: "time"}] = true
+		g.imports[pbinfo.ImportSpec{Path: "github.com/googleapis/gax-go/v2"}] = true
+
+		p("// waitForReadyRetryer retries the calls failing to connect to the service")
+		p("// until their deadline, like the WaitForReady option of gRPC does, and")
+		p("// leaves the other failures to retryer, if any.")
+		p("type waitForReadyRetryer struct {")
+		p("  retryer gax.Retryer")
+		p("  backoff gax.Backoff")
+		p("}")
+		p("")
+		p("func withWaitForReady(r gax.Retryer) gax.Retryer {")
+		p("  return &waitForReadyRetryer{retryer: r}")
+		p("}")
+		p("")
+		p("func (r *waitForReadyRetryer) Retry(err error) (time.Duration, bool) {")
+		p("  var opErr *net.OpError")
+		p(`  if errors.As(err, &opErr) && opErr.Op == "dial" {`)
+		p("    return r.backoff.Pause(), true")
+		p("  }")
+		p("  if r.retryer == nil {")
+		p("    return 0, false")
+		p("  }")
+		p("  return r.retryer.Retry(err)")
+		p("}")
+		p("")
+	}
+}
+
+// retryThrottled reports whether the retries and hedged requests of the
+// clients are throttled, which is the case if the gRPC service config has a
+// retry throttling policy.
+func (g *generator) retryThrottled() bool {
+	_, ok := g.cfg.gRPCServiceConfig.RetryThrottling()
+	return ok
+}
+
+// retryThrottleVar generates the budget of retries of a client, shared by the
+// default call options of its methods, if retries are throttled. It must be
+// called at the start of the function returning those call options.
+func (g *generator) retryThrottleVar(methods []*descriptorpb.MethodDescriptorProto) {
+	if !g.retryThrottled() || len(methods) == 0 {
+		return
+	}
+	g.printf("  throttle := newRetryThrottle()")
+}
+
+// retryThrottleOption generates the call option carrying the budget of
+// retries of the client, if retries are throttled.
+func (g *generator) retryThrottleOption() {
+	if !g.retryThrottled() {
+		return
+	}
+	g.printf("withRetryThrottle(throttle),")
+}
+
+// throttleSuccess generates the accounting of a successful call in the budget
+// of retries carried by the gRPC call options opts, if retries are throttled.
+func (g *generator) throttleSuccess(opts string) {
+	if !g.retryThrottled() {
+		return
+	}
+	g.printf("  creditRetryThrottle(%s)", opts)
+}
+
+// returnAttempt generates the return of err, the error of an attempt of a
+// call in the function invoked by gax.Invoke, accounting for its success in
+// the budget of retries, if retries are throttled.
+func (g *generator) returnAttempt(err string) {
+	p := g.printf
+	if !g.retryThrottled() {
+		p("  return %s", err)
+		return
+	}
+	if err == "err" {
+		p("  if err != nil {")
+	} else {
+		p("  if err := %s; err != nil {", err)
+	}
+	p("    return err")
+	p("  }")
+	g.throttleSuccess("settings.GRPC")
+	p("  return nil")
+}
+
+// genRetryThrottling generates the budget of retries of the clients of the
+// package, if retries are throttled.
+func (g *generator) genRetryThrottling() {
+	rt, ok := g.cfg.gRPCServiceConfig.RetryThrottling()
+	if !ok {
+		return
+	}
+	p := g.printf
+
+	g.imports[pbinfo.ImportSpec{Path: "sync"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "time"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "github.com/googleapis/gax-go/v2"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/grpc"}] = true
+
+	p("// retryThrottle is the budget of retries and hedged requests of a client, as")
+	p("// declared by the retry throttling policy of the service. It is carried by")
+	p("// the gRPC call options of the calls of the client, whatever its transport.")
+	p("// Every successful call adds tokenRatio tokens to it, and every failure that")
+	p("// would be retried takes a token from it. No retries nor hedged requests are")
+	p("// sent while at most half of its tokens are left.")
+	p("type retryThrottle struct {")
+	p("  grpc.EmptyCallOption")
+	p("  mu         sync.Mutex")
+	p("  maxTokens  float64")
+	p("  tokenRatio float64")
+	p("  tokens     float64")
+	p("}")
+	p("")
+	p("func newRetryThrottle() *retryThrottle {")
+	p("  return &retryThrottle{maxTokens: %[1]d, tokenRatio: %[2]v, tokens: %[1]d}", rt.GetMaxTokens(), rt.GetTokenRatio())
+	p("}")
+	p("")
+	p("// withRetryThrottle makes a call share the budget of retries t.")
+	p("func withRetryThrottle(t *retryThrottle) gax.CallOption {")
+	p("  return gax.WithGRPCOptions(t)")
+	p("}")
+	p("")
+	p("// callRetryThrottle returns the budget of retries in the gRPC call options of")
+	p("// a call, if any.")
+	p("func callRetryThrottle(opts []grpc.CallOption) *retryThrottle {")
+	p("  for i := len(opts) - 1; i >= 0; i-- {")
+	p("    if t, ok := opts[i].(*retryThrottle); ok {")
+	p("      return t")
+	p("    }")
+	p("  }")
+	p("  return nil")
+	p("}")
+	p("")
+	p("// creditRetryThrottle accounts for a successful call in the budget of")
+	p("// retries in its gRPC call options, if any.")
+	p("func creditRetryThrottle(opts []grpc.CallOption) {")
+	p("  if t := callRetryThrottle(opts); t != nil {")
+	p("    t.succeed()")
+	p("  }")
+	p("}")
+	p("")
+	p("func (t *retryThrottle) succeed() {")
+	p("  t.mu.Lock()")
+	p("  defer t.mu.Unlock()")
+	p("  t.tokens += t.tokenRatio")
+	p("  if t.tokens > t.maxTokens {")
+	p("    t.tokens = t.maxTokens")
+	p("  }")
+	p("}")
+	p("")
+	p("// fail takes a token for a failure, and reports whether retries are throttled.")
+	p("func (t *retryThrottle) fail() bool {")
+	p("  t.mu.Lock()")
+	p("  defer t.mu.Unlock()")
+	p("  t.tokens--")
+	p("  if t.tokens < 0 {")
+	p("    t.tokens = 0")
+	p("  }")
+	p("  return t.tokens <= t.maxTokens/2")
+	p("}")
+	p("")
+	p("func (t *retryThrottle) throttled() bool {")
+	p("  t.mu.Lock()")
+	p("  defer t.mu.Unlock()")
+	p("  return t.tokens <= t.maxTokens/2")
+	p("}")
+	p("")
+	p("// throttledRetryer stops the retries of a gax.Retryer while its budget of")
+	p("// retries is throttling them.")
+	p("type throttledRetryer struct {")
+	p("  retryer  gax.Retryer")
+	p("  throttle *retryThrottle")
+	p("}")
+	p("")
+	p("func throttled(t *retryThrottle, r gax.Retryer) gax.Retryer {")
+	p("  return &throttledRetryer{retryer: r, throttle: t}")
+	p("}")
+	p("")
+	p("func (r *throttledRetryer) Retry(err error) (time.Duration, bool) {")
+	p("  pause, ok := r.retryer.Retry(err)")
+	p("  if !ok || r.throttle.fail() {")
+	p("    return 0, false")
+	p("  }")
+	p("  return pause, true")
+	p("}")
+	p("")
+}
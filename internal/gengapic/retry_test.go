@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	conf "github.com/googleapis/gapic-generator-go/internal/grpc_service_config"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/txtdiff"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestRetryMaxAttempts(t *testing.T) {
+	for _, tst := range []struct {
+		in   uint32
+		want int
+	}{
+		{0, 0},
+		{1, 1},
+		{3, 3},
+		{5, 5},
+		{8, 5},
+	} {
+		rp := &conf.MethodConfig_RetryPolicy{MaxAttempts: tst.in}
+		if got := retryMaxAttempts(rp); got != tst.want {
+			t.Errorf("retryMaxAttempts(%d) = %d, want %d", tst.in, got, tst.want)
+		}
+	}
+}
+
+func TestGenRetryHelpers(t *testing.T) {
+	g := &generator{
+		aux:     &auxTypes{maxAttempts: true, waitForReady: true},
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg:     &generatorConfig{transports: []transport{grpc, rest}},
+	}
+	g.genRetryHelpers()
+
+	got := g.pt.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "auxiliary.go", "package foo\n\n"+got, parser.AllErrors); err != nil {
+		t.Errorf("generated helpers do not parse: %v", err)
+	}
+	txtdiff.Diff(t, got, filepath.Join("testdata", "retry_helpers.want"))
+}
+
+func TestGenRetryThrottling(t *testing.T) {
+	cpb := &conf.ServiceConfig{
+		RetryThrottling: &conf.ServiceConfig_RetryThrottlingPolicy{
+			MaxTokens:  10,
+			TokenRatio: 0.1,
+		},
+	}
+	data, err := protojson.Marshal(cpb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcConf, err := conf.New(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &generator{
+		aux:     &auxTypes{},
+		imports: map[pbinfo.ImportSpec]bool{},
+		cfg:     &generatorConfig{gRPCServiceConfig: grpcConf},
+	}
+	g.genRetryThrottling()
+
+	got := g.pt.String()
+	if _, err := parser.ParseFile(token.NewFileSet(), "helpers.go", "package foo\n\n"+got, parser.AllErrors); err != nil {
+		t.Errorf("generated helpers do not parse: %v", err)
+	}
+	txtdiff.Diff(t, got, filepath.Join("testdata", "retry_throttling.want"))
+
+	g.reset()
+	g.throttleSuccess("opts")
+	if got, want := strings.TrimSpace(g.pt.String()), "creditRetryThrottle(opts)"; got != want {
+		t.Errorf("throttleSuccess() = %s, want %s", got, want)
+	}
+
+	g.reset()
+	g.returnAttempt("f(ctx)")
+	if got, want := g.pt.String(), "if err := f(ctx); err != nil {\n\treturn err\n}\ncreditRetryThrottle(settings.GRPC)\nreturn nil\n"; got != want {
+		t.Errorf("returnAttempt() = %q, want %q", got, want)
+	}
+
+	g.reset()
+	g.cfg.gRPCServiceConfig = conf.Config{}
+	g.genRetryThrottling()
+	g.throttleSuccess("opts")
+	g.retryThrottleOption()
+	if got := g.pt.String(); got != "" {
+		t.Errorf("without a retry throttling policy, got %s, want nothing", got)
+	}
+	g.returnAttempt("err")
+	if got, want := g.pt.String(), "return err\n"; got != want {
+		t.Errorf("returnAttempt() = %q, want %q", got, want)
+	}
+}
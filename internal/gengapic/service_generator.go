@@ -0,0 +1,272 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/gapic/metadata"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// genServices generates the files of the given services, each in its own
+// generator context, on a pool of the given number of workers. The files,
+// auxiliary types and metadata collected for each service are then merged
+// into g in the order of the services, so the output does not depend on the
+// number of workers. If generating any service fails, the error of the first
+// failing one is returned. Once ctx is done, the remaining services are not
+// generated and its error is returned.
+func (g *generator) genServices(ctx context.Context, servs []*descriptorpb.ServiceDescriptorProto, protoPkg string, workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(servs) {
+		workers = len(servs)
+	}
+
+	sgs := make([]*generator, len(servs))
+	errs := make([]error, len(servs))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				if errs[i] = ctx.Err(); errs[i] != nil {
+					continue
+				}
+				sgs[i] = g.serviceGenerator(protoPkg)
+				errs[i] = sgs[i].genService(servs[i])
+			}
+		}()
+	}
+	for i := range servs {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	for i, sg := range sgs {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		if err := g.mergeService(sg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serviceGenerator returns a generator context for generating a single
+// service concurrently with the others. It shares the descriptors and the
+// configuration of g, which are not modified while generating services, and
+// has its own printer, imports, files, auxiliary types and metadata.
+func (g *generator) serviceGenerator(protoPkg string) *generator {
+	// The transports are overridden for services without REST-able RPCs.
+	cfg := *g.cfg
+	sg := &generator{
+		descInfo: g.descInfo,
+		comments: g.comments,
+		imports:  map[pbinfo.ImportSpec]bool{},
+		apiName:  g.apiName,
+		aux: &auxTypes{
+			iters:           map[string]*iterType{},
+			methodToWrapper: map[*descriptorpb.MethodDescriptorProto]operationWrapper{},
+			opWrappers:      map[string]operationWrapper{},
+			uploads:         map[string]*uploadType{},
+			customOp:        g.aux.customOp,
+		},
+		cfg: &cfg,
+		metadata: &metadata.GapicMetadata{
+			Schema:         g.metadata.GetSchema(),
+			Language:       g.metadata.GetLanguage(),
+			Comment:        g.metadata.GetComment(),
+			ProtoPackage:   g.metadata.GetProtoPackage(),
+			LibraryPackage: g.metadata.GetLibraryPackage(),
+			Services:       make(map[string]*metadata.GapicMetadata_ServiceForTransport),
+		},
+		mixins:                g.mixins,
+		hasIAMPolicyOverrides: g.hasIAMPolicyOverrides,
+		customOpServices:      g.customOpServices,
+		vocabulary:            g.vocabulary,
+		sggConfigs:            make(map[string]*sggConfig),
+	}
+	sg.snippetMetadata = sg.newSnippetsMetadata(protoPkg)
+	return sg
+}
+
+// genService generates the client, examples, snippets and tests of the given
+// service.
+func (g *generator) genService(s *descriptorpb.ServiceDescriptorProto) error {
+	if len(g.getMethods(s)) == 0 {
+		return nil
+	}
+	g.clientProtoPkg = g.descInfo.ParentFile[s].GetPackage()
+	// TODO(pongad): gapic-generator does not remove the package name here,
+	// so even though the client for LoggingServiceV2 is just "Client"
+	// the file name is "logging_client.go".
+	// Keep the current behavior for now, but we could revisit this later.
+	override := g.getServiceNameOverride(s)
+	servName := pbinfo.ReduceServNameWithOverride(s.GetName(), "", override)
+	outFile := camelToSnake(servName)
+	outFile = filepath.Join(g.cfg.outDir, outFile)
+
+	if err := g.genAndCommitSnippets(s); err != nil {
+		return fmt.Errorf("error generating snippets for %s: %v ", s.GetName(), err)
+	}
+
+	g.reset()
+	// If the service has no REST-able RPCs, then a REGAPIC should not be
+	// generated for it, even if REST is an enabled transport.
+	if !g.hasRESTMethod(s) {
+		transports := g.cfg.transports
+		g.cfg.transports = nil
+		for _, t := range transports {
+			if t != rest {
+				g.cfg.transports = append(g.cfg.transports, t)
+			}
+		}
+		// The in-process client cannot be the only one.
+		if len(g.cfg.transports) == 0 || g.cfg.transports[0] == inprocess {
+			g.cfg.transports = append([]transport{grpc}, g.cfg.transports...)
+		}
+	}
+	if err := g.gen(s); err != nil {
+		return err
+	}
+	if _, err := g.commit(outFile+"_client.go", g.cfg.pkgName); err != nil {
+		return err
+	}
+
+	if g.isInternalService(s) {
+		return nil
+	}
+
+	g.reset()
+	if err := g.genExampleFile(s); err != nil {
+		return fmt.Errorf("error generating example for %q; %v", s.GetName(), err)
+	}
+	g.imports[pbinfo.ImportSpec{Name: g.cfg.pkgName, Path: g.cfg.pkgPath}] = true
+	if _, err := g.commit(outFile+"_client_example_test.go", g.cfg.pkgName+"_test"); err != nil {
+		return err
+	}
+
+	g.reset()
+	if err := g.genExampleIteratorFile(s); err != nil {
+		return fmt.Errorf("error generating iter example for %q; %v", s.GetName(), err)
+	}
+	g.imports[pbinfo.ImportSpec{Name: g.cfg.pkgName, Path: g.cfg.pkgPath}] = true
+	if _, err := g.commitWithBuildTag(outFile+"_client_example_go123_test.go", g.cfg.pkgName+"_test", "go1.23"); err != nil {
+		return err
+	}
+
+	if g.cfg.generateTests && containsTransport(g.cfg.transports, grpc) {
+		g.reset()
+		if err := g.genMockTestFile(s); err != nil {
+			return fmt.Errorf("error generating mock tests for %q; %v", s.GetName(), err)
+		}
+		if _, err := g.commit(outFile+"_client_mock_test.go", g.cfg.pkgName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeService adds the files, auxiliary types and metadata collected by the
+// generator context of a service to g, as if the service had been generated
+// by g after the services already merged into it.
+func (g *generator) mergeService(sg *generator) error {
+	g.resp.File = append(g.resp.File, sg.resp.File...)
+
+	for _, ow := range sortOperationWrapperMap(sg.aux.opWrappers) {
+		if exists, err := g.aux.wrapperExists(ow); err != nil {
+			return err
+		} else if !exists {
+			g.aux.opWrappers[ow.name] = ow
+		}
+	}
+	for m, ow := range sg.aux.methodToWrapper {
+		g.aux.methodToWrapper[m] = g.aux.opWrappers[ow.name]
+	}
+	for name, iter := range sg.aux.iters {
+		if _, ok := g.aux.iters[name]; !ok {
+			g.aux.iters[name] = iter
+		}
+	}
+	names := make([]string, 0, len(sg.aux.uploads))
+	for n := range sg.aux.uploads {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		ut := sg.aux.uploads[n]
+		if existing, ok := g.aux.uploads[ut.name]; !ok {
+			g.aux.uploads[ut.name] = ut
+		} else if existing.respTyp != ut.respTyp {
+			return fmt.Errorf("duplicate upload helper types %q have mismatched response types: %s v. %s", ut.name, existing.respTyp, ut.respTyp)
+		}
+	}
+	g.aux.httpBodyReader = g.aux.httpBodyReader || sg.aux.httpBodyReader
+	g.aux.restStreams = g.aux.restStreams || sg.aux.restStreams
+	g.aux.connectConn = g.aux.connectConn || sg.aux.connectConn
+	g.aux.inProcessConn = g.aux.inProcessConn || sg.aux.inProcessConn
+	g.aux.maxAttempts = g.aux.maxAttempts || sg.aux.maxAttempts
+	g.aux.waitForReady = g.aux.waitForReady || sg.aux.waitForReady
+	g.aux.hedging = g.aux.hedging || sg.aux.hedging
+	g.aux.requiredFields = g.aux.requiredFields || sg.aux.requiredFields
+	g.aux.updateMask = g.aux.updateMask || sg.aux.updateMask
+
+	for _, d := range sg.diagnostics {
+		g.addDiagnostic(d)
+	}
+	g.mergeSurface(sg.surface)
+
+	g.mergeMetadata(sg.metadata)
+	if g.snippetMetadata != nil && sg.snippetMetadata != nil {
+		g.snippetMetadata.Merge(sg.snippetMetadata)
+	}
+	return nil
+}
+
+// mergeMetadata adds the services of the given GapicMetadata to the one of g.
+// As with addMetadataServiceEntry and addMetadataServiceForTransport, the
+// entries already present are kept, while the RPCs are replaced.
+func (g *generator) mergeMetadata(md *metadata.GapicMetadata) {
+	for name, s := range md.GetServices() {
+		g.addMetadataServiceEntry(name, s.GetApiVersion())
+		for trans, c := range s.GetClients() {
+			g.metadata.Services[name].Clients[trans] = mergeMetadataClient(g.metadata.Services[name].Clients[trans], c)
+		}
+	}
+}
+
+func mergeMetadataClient(dst, src *metadata.GapicMetadata_ServiceAsClient) *metadata.GapicMetadata_ServiceAsClient {
+	if dst == nil {
+		return src
+	}
+	if dst.Rpcs == nil {
+		dst.Rpcs = make(map[string]*metadata.GapicMetadata_MethodList)
+	}
+	for rpc, ml := range src.GetRpcs() {
+		dst.Rpcs[rpc] = ml
+	}
+	return dst
+}
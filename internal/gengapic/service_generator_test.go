@@ -0,0 +1,137 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// thingsRequest returns a request generating an API of several services
+// paging over the same resource, with the given plugin parameter.
+func thingsRequest(param string) *pluginpb.CodeGeneratorRequest {
+	str := func(name string, num int32) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(num),
+			Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			JsonName: proto.String(name),
+		}
+	}
+	listReq := &descriptorpb.DescriptorProto{
+		Name: proto.String("ListThingsRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			str("parent", 1),
+			{
+				Name:     proto.String("page_size"),
+				Number:   proto.Int32(2),
+				Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				JsonName: proto.String("pageSize"),
+			},
+			str("page_token", 3),
+		},
+	}
+	listResp := &descriptorpb.DescriptorProto{
+		Name: proto.String("ListThingsResponse"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("things"),
+				Number:   proto.Int32(1),
+				Label:    labelp(descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+				Type:     typep(descriptorpb.FieldDescriptorProto_TYPE_MESSAGE),
+				TypeName: proto.String(".my.pkg.Thing"),
+				JsonName: proto.String("things"),
+			},
+			str("next_page_token", 2),
+		},
+	}
+	thing := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Thing"),
+		Field: []*descriptorpb.FieldDescriptorProto{str("name", 1)},
+	}
+
+	var servs []*descriptorpb.ServiceDescriptorProto
+	for _, name := range []string{"Foo", "Bar", "Baz", "Qux"} {
+		sOpts := &descriptorpb.ServiceOptions{}
+		proto.SetExtension(sOpts, annotations.E_DefaultHost, "my.googleapis.com")
+		get := &descriptorpb.MethodOptions{}
+		proto.SetExtension(get, annotations.E_Http, &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Get{Get: fmt.Sprintf("/v1/{parent=%s/*}/things", name)},
+		})
+		proto.SetExtension(get, annotations.E_Routing, &annotations.RoutingRule{
+			RoutingParameters: []*annotations.RoutingParameter{
+				{Field: "parent", PathTemplate: "{project=projects/*}/**"},
+				{Field: "parent", PathTemplate: "projects/*/{location=locations/*}/**"},
+				{Field: "parent", PathTemplate: "{routing_id=**}"},
+			},
+		})
+		servs = append(servs, &descriptorpb.ServiceDescriptorProto{
+			Name:    proto.String(name),
+			Options: sOpts,
+			Method: []*descriptorpb.MethodDescriptorProto{
+				{
+					Name:       proto.String("ListThings"),
+					InputType:  proto.String(".my.pkg.ListThingsRequest"),
+					OutputType: proto.String(".my.pkg.ListThingsResponse"),
+					Options:    get,
+				},
+			},
+		})
+	}
+	return &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{"my/pkg/things.proto"},
+		Parameter:      proto.String(param),
+		ProtoFile: []*descriptorpb.FileDescriptorProto{
+			{
+				Name:    proto.String("my/pkg/things.proto"),
+				Package: proto.String("my.pkg"),
+				Options: &descriptorpb.FileOptions{
+					GoPackage: proto.String("github.com/googleapis/mypkg/pb;pkgpb"),
+				},
+				MessageType: []*descriptorpb.DescriptorProto{listReq, listResp, thing},
+				Service:     servs,
+			},
+		},
+	}
+}
+
+func TestGenServicesDeterministic(t *testing.T) {
+	req := thingsRequest("go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,metadata,api-surface")
+	generate := func(procs int) *pluginpb.CodeGeneratorResponse {
+		defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+		resp, err := gen(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	serial := generate(1)
+	if len(serial.GetFile()) == 0 {
+		t.Fatal("gen() generated no files")
+	}
+	for i := 0; i < 5; i++ {
+		if diff := cmp.Diff(serial, generate(4), cmp.Comparer(proto.Equal)); diff != "" {
+			t.Fatalf("parallel generation differs from serial generation: got(-),want(+):\n%s", diff)
+		}
+	}
+}
@@ -113,6 +113,7 @@ func (g *generator) genAndCommitSnippets(s *descriptorpb.ServiceDescriptorProto)
 		}
 		if m.GetClientStreaming() != m.GetServerStreaming() {
 			// TODO(chrisdsmith): implement streaming examples correctly, see example.go TODOs.
+			g.diagnose(skippedSnippet, g.fqn(m), "snippets are not generated for client or server streaming RPCs")
 			continue
 		}
 		// For each method, reset the generator in order to write a
@@ -131,8 +132,13 @@ func (g *generator) genAndCommitSnippets(s *descriptorpb.ServiceDescriptorProto)
 		f := g.descInfo.ParentFile[m]
 		// Get the original proto service for the method (different from `s` only for mixins).
 		methodServ := (g.descInfo.ParentElement[m]).(*descriptorpb.ServiceDescriptorProto)
-		lineCount := g.commitWithBuildTag(filepath.Join(g.snippetsOutDir(), clientName, g.methodName(m), "main.go"), "main", "examples")
-		g.snippetMetadata.AddMethod(servName, g.methodName(m), f.GetPackage(), methodServ.GetName(), lineCount-1)
+		lineCount, err := g.commitWithBuildTag(filepath.Join(g.snippetsOutDir(), clientName, g.methodName(m), "main.go"), "main", "examples")
+		if err != nil {
+			return err
+		}
+		// The START region tag is the first line after the license header.
+		regionTagStart := strings.Count(g.licenseHeader(g.copyrightYear()), "\n") + 1
+		g.snippetMetadata.AddMethod(servName, g.methodName(m), f.GetPackage(), methodServ.GetName(), regionTagStart, lineCount+1)
 	}
 	return nil
 }
@@ -150,6 +156,7 @@ func (g *generator) genSnippetFile(s *descriptorpb.ServiceDescriptorProto, m *de
 	reducedServName := pbinfo.ReduceServName(servName, pkgName)
 
 	p := g.printf
+	g.startRPC(m)
 	p("func main() {")
 	if err := g.exampleMethodBody(pkgName, reducedServName, m); err != nil {
 		return err
@@ -31,7 +31,7 @@ func (g *generator) noRequestStreamCall(servName string, s *descriptorpb.Service
 	g.imports[servSpec] = true
 
 	// We DON'T want to export the transport layers.
-	lowcaseServName := lowerFirst(servName + "GRPCClient")
+	lowcaseServName := g.grpcClientTypeName(servName)
 
 	retTyp := fmt.Sprintf("%s.%s_%sClient", servSpec.Name, s.GetName(), m.GetName())
 	p("func (c *%s) %s(ctx context.Context, opts ...gax.CallOption) (%s, error) {",
@@ -47,7 +47,7 @@ func (g *generator) noRequestStreamCall(servName string, s *descriptorpb.Service
 	p(`    c.logger.DebugContext(ctx, "api streaming client request", "serviceName", serviceName, "rpcName", %q)`, m.GetName())
 	p("    resp, err = c.%s.%s(ctx, settings.GRPC...)", grpcClientField(servName), m.GetName())
 	p(`    c.logger.DebugContext(ctx, "api streaming client response", "serviceName", serviceName, "rpcName", %q)`, m.GetName())
-	p("    return err")
+	g.returnAttempt("err")
 	p("  }, opts...)")
 	p("  if err != nil {")
 	p("    return nil, err")
@@ -74,7 +74,7 @@ func (g *generator) serverStreamCall(servName string, s *descriptorpb.ServiceDes
 	g.imports[servSpec] = true
 
 	p := g.printf
-	lowcaseServName := lowerFirst(servName + "GRPCClient")
+	lowcaseServName := g.grpcClientTypeName(servName)
 
 	retTyp := fmt.Sprintf("%s.%s_%sClient", servSpec.Name, s.GetName(), m.GetName())
 	p("func (c *%s) %s(ctx context.Context, req *%s.%s, opts ...gax.CallOption) (%s, error) {",
@@ -90,7 +90,7 @@ func (g *generator) serverStreamCall(servName string, s *descriptorpb.ServiceDes
 	p(`  c.logger.DebugContext(ctx, "api streaming client request", "serviceName", serviceName, "rpcName", %q)`, m.GetName())
 	p("  resp, err = c.%s.%s(ctx, req, settings.GRPC...)", grpcClientField(servName), m.GetName())
 	p(`  c.logger.DebugContext(ctx, "api streaming client response", "serviceName", serviceName, "rpcName", %q)`, m.GetName())
-	p("  return err")
+	g.returnAttempt("err")
 	p("}, opts...)")
 	p("if err != nil {")
 	p("  return nil, err")
@@ -0,0 +1,140 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"fmt"
+
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const fieldMaskType = ".google.protobuf.FieldMask"
+
+// updateMaskResource returns the resource field of the request of m, if m is
+// an AIP-134 Update method whose update_mask is computed when unset, or nil.
+// The request of such a method has a google.protobuf.FieldMask update_mask,
+// and the resource is the message field named as the HTTP body, with fields
+// that may be updated.
+func (g *generator) updateMaskResource(m *descriptorpb.MethodDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	if !g.featureEnabled(AutoUpdateMaskFeature) || m.GetClientStreaming() || m.GetServerStreaming() {
+		return nil
+	}
+	info := getHTTPInfo(m)
+	if info == nil || info.body == "" || info.body == "*" {
+		return nil
+	}
+	req, ok := g.descInfo.Type[m.GetInputType()].(*descriptorpb.DescriptorProto)
+	if !ok {
+		return nil
+	}
+
+	// The fields of a oneof are not set directly.
+	mask := getField(req, "update_mask")
+	if mask == nil || mask.GetTypeName() != fieldMaskType || mask.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED ||
+		(mask.OneofIndex != nil && !mask.GetProto3Optional()) {
+		return nil
+	}
+	res := getField(req, info.body)
+	if res == nil || res.GetType() != descriptorpb.FieldDescriptorProto_TYPE_MESSAGE || res.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return nil
+	}
+	if len(g.updatableFields(res)) == 0 {
+		return nil
+	}
+	return res
+}
+
+// updatableFields returns the names of the fields of the message of the
+// resource field res that an update may set, which are all of them but the
+// OUTPUT_ONLY and IMMUTABLE ones.
+func (g *generator) updatableFields(res *descriptorpb.FieldDescriptorProto) []string {
+	msg, ok := g.descInfo.Type[res.GetTypeName()].(*descriptorpb.DescriptorProto)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, f := range msg.GetField() {
+		if hasFieldBehavior(f, annotations.FieldBehavior_OUTPUT_ONLY) || hasFieldBehavior(f, annotations.FieldBehavior_IMMUTABLE) {
+			continue
+		}
+		names = append(names, f.GetName())
+	}
+	return names
+}
+
+// updateMaskDoc returns the documentation of the computation of the
+// update_mask of m, if any, to follow the name of m in its comment.
+func (g *generator) updateMaskDoc(m *descriptorpb.MethodDescriptorProto) string {
+	res := g.updateMaskResource(m)
+	if res == nil {
+		return ""
+	}
+	return fmt.Sprintf("sets the update_mask of a request that does not set one to the paths of the populated fields of %s, other than its output only and immutable fields, so that only those fields are updated.", res.GetName())
+}
+
+// setUpdateMask generates the computation of the update_mask of req, of type
+// reqTyp, if m is an Update method whose update_mask is computed when unset.
+// The mask is set on a copy of req, so that the request of the caller is left
+// untouched.
+func (g *generator) setUpdateMask(m *descriptorpb.MethodDescriptorProto, reqTyp string) {
+	res := g.updateMaskResource(m)
+	if res == nil {
+		return
+	}
+	p := g.printf
+	g.aux.updateMask = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/proto"}] = true
+
+	getter := fieldGetter(res.GetName())
+	p("if req.GetUpdateMask() == nil && req%s != nil {", getter)
+	p("  req = proto.Clone(req).(*%s)", reqTyp)
+	p("  req.UpdateMask = populatedFieldsMask(req%s,", getter)
+	for _, f := range g.updatableFields(res) {
+		p("    %q,", f)
+	}
+	p("  )")
+	p("}")
+}
+
+// genUpdateMaskHelper generates the helper computing the update_mask of a
+// request, if any Update method computes it.
+func (g *generator) genUpdateMaskHelper() {
+	if !g.aux.updateMask {
+		return
+	}
+	p := g.printf
+
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/proto"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/reflect/protoreflect"}] = true
+	g.imports[pbinfo.ImportSpec{Path: "google.golang.org/protobuf/types/known/fieldmaskpb"}] = true
+
+	p("// populatedFieldsMask returns the field mask of the populated fields of the")
+	p("// resource m among the given fields, the fields an update may set, setting")
+	p("// the update_mask of an Update request not setting one.")
+	p("func populatedFieldsMask(m proto.Message, fields ...protoreflect.Name) *fieldmaskpb.FieldMask {")
+	p("  mask := &fieldmaskpb.FieldMask{}")
+	p("  r := m.ProtoReflect()")
+	p("  fds := r.Descriptor().Fields()")
+	p("  for _, f := range fields {")
+	p("    if fd := fds.ByName(f); fd != nil && r.Has(fd) {")
+	p("      mask.Paths = append(mask.Paths, string(f))")
+	p("    }")
+	p("  }")
+	p("  return mask")
+	p("}")
+	p("")
+}
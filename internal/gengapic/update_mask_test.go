@@ -0,0 +1,306 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gengapic
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
+	"github.com/googleapis/gapic-generator-go/internal/snippets"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func updateThingRequest(maskType string, maskLabel descriptorpb.FieldDescriptorProto_Label) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{
+		Name: proto.String("UpdateThingRequest"),
+		Field: []*descriptorpb.FieldDescriptorProto{
+			{
+				Name:     proto.String("thing"),
+				Number:   proto.Int32(1),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(".my.pkg.Thing"),
+				JsonName: proto.String("thing"),
+			},
+			{
+				Name:     proto.String("update_mask"),
+				Number:   proto.Int32(2),
+				Label:    maskLabel.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+				TypeName: proto.String(maskType),
+				JsonName: proto.String("updateMask"),
+			},
+			{
+				Name:     proto.String("etag"),
+				Number:   proto.Int32(3),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: proto.String("etag"),
+			},
+		},
+	}
+}
+
+// thingMessage returns the resource of updateThingRequest, with a string field
+// of each of the given behaviors, named after it.
+func thingMessage(behaviors ...annotations.FieldBehavior) *descriptorpb.DescriptorProto {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String("Thing")}
+	for _, b := range behaviors {
+		addBehaviorField(msg, b)
+	}
+	return msg
+}
+
+// addBehaviorField adds a string field of the given behavior to msg, named
+// after it.
+func addBehaviorField(msg *descriptorpb.DescriptorProto, b annotations.FieldBehavior) {
+	opts := &descriptorpb.FieldOptions{}
+	proto.SetExtension(opts, annotations.E_FieldBehavior, []annotations.FieldBehavior{b})
+	name := strings.ToLower(b.String())
+	msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		Number:   proto.Int32(int32(len(msg.GetField()) + 1)),
+		Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		JsonName: proto.String(name),
+		Options:  opts,
+	})
+}
+
+func updateThingMethod(body string) *descriptorpb.MethodDescriptorProto {
+	opts := &descriptorpb.MethodOptions{}
+	proto.SetExtension(opts, annotations.E_Http, &annotations.HttpRule{
+		Pattern: &annotations.HttpRule_Patch{Patch: "/v1/{thing.name=things/*}"},
+		Body:    body,
+	})
+	return &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String("UpdateThing"),
+		InputType:  proto.String(".my.pkg.UpdateThingRequest"),
+		OutputType: proto.String(".my.pkg.Thing"),
+		Options:    opts,
+	}
+}
+
+func TestUpdateMaskResource(t *testing.T) {
+	optional := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	streaming := updateThingMethod("thing")
+	streaming.ServerStreaming = proto.Bool(true)
+
+	updatable := thingMessage(annotations.FieldBehavior_OPTIONAL, annotations.FieldBehavior_OUTPUT_ONLY)
+	for _, tst := range []struct {
+		name    string
+		req     *descriptorpb.DescriptorProto
+		thing   *descriptorpb.DescriptorProto
+		m       *descriptorpb.MethodDescriptorProto
+		feature bool
+		want    string
+	}{
+		{
+			name:    "update",
+			req:     updateThingRequest(fieldMaskType, optional),
+			m:       updateThingMethod("thing"),
+			feature: true,
+			want:    "thing",
+		},
+		{
+			name: "disabled",
+			req:  updateThingRequest(fieldMaskType, optional),
+			m:    updateThingMethod("thing"),
+		},
+		{
+			name:    "whole_request_body",
+			req:     updateThingRequest(fieldMaskType, optional),
+			m:       updateThingMethod("*"),
+			feature: true,
+		},
+		{
+			name:    "scalar_body",
+			req:     updateThingRequest(fieldMaskType, optional),
+			m:       updateThingMethod("etag"),
+			feature: true,
+		},
+		{
+			name:    "no_body",
+			req:     updateThingRequest(fieldMaskType, optional),
+			m:       updateThingMethod(""),
+			feature: true,
+		},
+		{
+			name:    "mask_of_other_type",
+			req:     updateThingRequest(".my.pkg.Thing", optional),
+			m:       updateThingMethod("thing"),
+			feature: true,
+		},
+		{
+			name:    "repeated_mask",
+			req:     updateThingRequest(fieldMaskType, descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+			m:       updateThingMethod("thing"),
+			feature: true,
+		},
+		{
+			name:    "streaming",
+			req:     updateThingRequest(fieldMaskType, optional),
+			m:       streaming,
+			feature: true,
+		},
+		{
+			name:    "no_updatable_field",
+			req:     updateThingRequest(fieldMaskType, optional),
+			thing:   thingMessage(annotations.FieldBehavior_OUTPUT_ONLY, annotations.FieldBehavior_IMMUTABLE),
+			m:       updateThingMethod("thing"),
+			feature: true,
+		},
+	} {
+		t.Run(tst.name, func(t *testing.T) {
+			thing := tst.thing
+			if thing == nil {
+				thing = updatable
+			}
+			g := &generator{
+				cfg: &generatorConfig{featureEnablement: map[featureID]struct{}{}},
+				descInfo: pbinfo.Info{
+					Type: map[string]pbinfo.ProtoType{
+						".my.pkg.UpdateThingRequest": tst.req,
+						".my.pkg.Thing":              thing,
+					},
+				},
+			}
+			if tst.feature {
+				g.cfg.featureEnablement[AutoUpdateMaskFeature] = struct{}{}
+			}
+			var got string
+			if res := g.updateMaskResource(tst.m); res != nil {
+				got = res.GetName()
+			}
+			if got != tst.want {
+				t.Errorf("updateMaskResource() = %q, want %q", got, tst.want)
+			}
+		})
+	}
+}
+
+func TestUpdatableFields(t *testing.T) {
+	g := &generator{
+		descInfo: pbinfo.Info{
+			Type: map[string]pbinfo.ProtoType{
+				".my.pkg.Thing": thingMessage(
+					annotations.FieldBehavior_REQUIRED,
+					annotations.FieldBehavior_OUTPUT_ONLY,
+					annotations.FieldBehavior_IMMUTABLE,
+					annotations.FieldBehavior_OPTIONAL,
+					annotations.FieldBehavior_IDENTIFIER,
+				),
+			},
+		},
+	}
+	got := g.updatableFields(getField(updateThingRequest(fieldMaskType, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL), "thing"))
+	want := []string{"required", "optional", "identifier"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("updatableFields() got(-),want(+):\n%s", diff)
+	}
+}
+
+func TestUpdateMaskMethodDoc(t *testing.T) {
+	m := updateThingMethod("thing")
+	serv := &descriptorpb.ServiceDescriptorProto{Name: proto.String("Foo")}
+	g := generator{
+		comments: map[protoiface.MessageV1]string{},
+		cfg: &generatorConfig{
+			featureEnablement: map[featureID]struct{}{AutoUpdateMaskFeature: {}},
+		},
+		descInfo: pbinfo.Info{
+			Type: map[string]pbinfo.ProtoType{
+				".my.pkg.UpdateThingRequest": updateThingRequest(fieldMaskType, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+				".my.pkg.Thing":              thingMessage(annotations.FieldBehavior_OPTIONAL),
+			},
+			ParentElement: map[pbinfo.ProtoType]pbinfo.ProtoType{m: serv},
+		},
+	}
+
+	for _, tst := range []struct {
+		in, want string
+	}{
+		{
+			in: "Updates a thing.",
+			want: "// UpdateThing updates a thing.\n" +
+				"//\n" +
+				"// UpdateThing sets the update_mask of a request that does not set one to the paths of the populated fields of thing, other than its output only and immutable fields, so that only those fields are updated.\n",
+		},
+		{
+			in:   "",
+			want: "// UpdateThing sets the update_mask of a request that does not set one to the paths of the populated fields of thing, other than its output only and immutable fields, so that only those fields are updated.\n",
+		},
+	} {
+		sm := snippets.NewMetadata("mypackage", "github.com/googleapis/mypackage", "mypackagego")
+		sm.AddService("Foo", "mypackage.googleapis.com")
+		sm.AddMethod("Foo", "UpdateThing", "mypackage", "Foo", 17, 50)
+		g.snippetMetadata = sm
+		g.comments[m] = tst.in
+		g.pt.Reset()
+		g.methodDoc(m, serv)
+		if diff := cmp.Diff(g.pt.String(), tst.want); diff != "" {
+			t.Errorf("methodDoc(%q) got(-),want(+):\n%s", tst.in, diff)
+		}
+	}
+}
+
+func TestUpdateMaskGen(t *testing.T) {
+	for _, tst := range []struct {
+		param string
+		want  bool
+	}{
+		{param: "go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest"},
+		{param: "go-gapic-package=github.com/googleapis/mypkg;mypkg,transport=grpc+rest,F_auto_update_mask", want: true},
+	} {
+		req := thingsRequest(tst.param)
+		req.ProtoFile = append([]*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(fieldmaskpb.File_google_protobuf_field_mask_proto)}, req.ProtoFile...)
+		f := req.ProtoFile[1]
+		f.Dependency = append(f.Dependency, "google/protobuf/field_mask.proto")
+		f.MessageType = append(f.MessageType, updateThingRequest(fieldMaskType, descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL))
+		thing := f.MessageType[2]
+		addBehaviorField(thing, annotations.FieldBehavior_OUTPUT_ONLY)
+		addBehaviorField(thing, annotations.FieldBehavior_OPTIONAL)
+		f.Service[0].Method = append(f.Service[0].Method, updateThingMethod("thing"))
+
+		resp, err := gen(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string][]string{
+			"foo_client.go": {
+				"// UpdateThing sets the update_mask of a request that does not set one to the paths of the populated fields of thing, other than its output only and immutable fields, so that only those fields are updated.",
+				"if req.GetUpdateMask() == nil && req.GetThing() != nil {",
+				"req = proto.Clone(req).(*pkgpb.UpdateThingRequest)",
+				"req.UpdateMask = populatedFieldsMask(req.GetThing(),\n\t\t\t\"name\",\n\t\t\t\"optional\",\n\t\t)",
+			},
+			"auxiliary.go": {
+				"func populatedFieldsMask(m proto.Message, fields ...protoreflect.Name) *fieldmaskpb.FieldMask {",
+			},
+		}
+		for _, f := range resp.GetFile() {
+			for _, w := range want[filepath.Base(f.GetName())] {
+				if got := strings.Contains(f.GetContent(), w); got != tst.want {
+					t.Errorf("%s: %s contains %q: %t, want %t", tst.param, f.GetName(), w, got, tst.want)
+				}
+			}
+		}
+	}
+}
@@ -25,10 +25,14 @@ import (
 // Config represents parsed mapping of the gRPC ServiceConfig contents
 // with methods for increased accessibility.
 type Config struct {
-	policies  map[string]*MethodConfig_RetryPolicy
-	timeouts  map[string]*duration.Duration
-	reqLimits map[string]int
-	resLimits map[string]int
+	policies     map[string]*MethodConfig_RetryPolicy
+	hedging      map[string]*MethodConfig_HedgingPolicy
+	timeouts     map[string]*duration.Duration
+	reqLimits    map[string]int
+	resLimits    map[string]int
+	waitForReady map[string]bool
+	throttling   *ServiceConfig_RetryThrottlingPolicy
+	names        []*MethodConfig_Name
 }
 
 // New traverses the given gRPC ServiceConfig into more accessible constructs
@@ -47,13 +51,17 @@ func New(in io.Reader) (Config, error) {
 	}
 
 	policies := map[string]*MethodConfig_RetryPolicy{}
+	hedging := map[string]*MethodConfig_HedgingPolicy{}
 	timeouts := map[string]*duration.Duration{}
 	reqLimits := map[string]int{}
 	resLimits := map[string]int{}
+	waitForReady := map[string]bool{}
+	var names []*MethodConfig_Name
 
 	// gather retry policies from MethodConfigs
 	for _, mc := range c.GetMethodConfig() {
 		for _, name := range mc.GetName() {
+			names = append(names, name)
 			n := name.GetService()
 
 			// individual method config
@@ -62,6 +70,7 @@ func New(in io.Reader) (Config, error) {
 			}
 
 			policies[n] = mc.GetRetryPolicy()
+			hedging[n] = mc.GetHedgingPolicy()
 
 			if maxReq := mc.GetMaxRequestMessageBytes(); maxReq != nil {
 				reqLimits[n] = int(maxReq.GetValue())
@@ -74,14 +83,22 @@ func New(in io.Reader) (Config, error) {
 			if timeout := mc.GetTimeout(); timeout != nil {
 				timeouts[n] = timeout
 			}
+
+			if wfr := mc.GetWaitForReady(); wfr != nil {
+				waitForReady[n] = wfr.GetValue()
+			}
 		}
 	}
 
 	return Config{
-		policies:  policies,
-		timeouts:  timeouts,
-		reqLimits: reqLimits,
-		resLimits: resLimits,
+		policies:     policies,
+		hedging:      hedging,
+		timeouts:     timeouts,
+		reqLimits:    reqLimits,
+		resLimits:    resLimits,
+		waitForReady: waitForReady,
+		throttling:   c.GetRetryThrottling(),
+		names:        names,
 	}, nil
 }
 
@@ -100,6 +117,21 @@ func (c Config) RetryPolicy(s, m string) (*MethodConfig_RetryPolicy, bool) {
 	return policy, ok
 }
 
+// HedgingPolicy returns the hedgingPolicy and a presence flag for the
+// given fully-qualified Service and simple Method names. A config assignment
+// for a specific Method takes precedence over a Service-level assignment.
+func (c Config) HedgingPolicy(s, m string) (*MethodConfig_HedgingPolicy, bool) {
+	// Favor the policy defined for a fully-qualified Method name.
+	policy, ok := c.hedging[s+"."+m]
+	if ok {
+		return policy, ok
+	}
+
+	// Fallback on the policy defined for an entire Service
+	policy, ok = c.hedging[s]
+	return policy, ok
+}
+
 // Timeout returns the timeout in milliseconds and a presence flag for the given
 // fully-qualified Service and simple Method names. A config assignment for the
 // specific Method takes precedence over a Service-level assignment.
@@ -155,3 +187,29 @@ func (c Config) ResponseLimit(s, m string) (int, bool) {
 	lim, ok = c.resLimits[s]
 	return lim, ok
 }
+
+// WaitForReady returns the wait_for_ready value and a presence flag for the
+// given fully-qualified Service and simple Method names. A config assignment
+// for a specific Method takes precedence over a Service-level assignment.
+func (c Config) WaitForReady(s, m string) (bool, bool) {
+	// Favor the value defined for a fully-qualified Method name.
+	wfr, ok := c.waitForReady[s+"."+m]
+	if ok {
+		return wfr, ok
+	}
+
+	// Fallback on the value defined for an entire Service
+	wfr, ok = c.waitForReady[s]
+	return wfr, ok
+}
+
+// RetryThrottling returns the retry throttling policy and a presence flag.
+// The policy applies to all the Services of the config.
+func (c Config) RetryThrottling() (*ServiceConfig_RetryThrottlingPolicy, bool) {
+	return c.throttling, c.throttling != nil
+}
+
+// Names returns the names of the MethodConfigs, in the order they are declared.
+func (c Config) Names() []*MethodConfig_Name {
+	return c.names
+}
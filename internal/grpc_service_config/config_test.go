@@ -47,7 +47,8 @@ func TestParse(t *testing.T) {
 						},
 					},
 				},
-				Timeout: &duration.Duration{Seconds: 30},
+				Timeout:      &duration.Duration{Seconds: 30},
+				WaitForReady: &wrappers.BoolValue{Value: true},
 			},
 			{
 				Name: []*MethodConfig_Name{
@@ -69,6 +70,27 @@ func TestParse(t *testing.T) {
 				},
 				Timeout: &duration.Duration{Seconds: 60},
 			},
+			{
+				Name: []*MethodConfig_Name{
+					{
+						Service: "bar.FooService",
+						Method:  "Zap",
+					},
+				},
+				RetryOrHedgingPolicy: &MethodConfig_HedgingPolicy_{
+					HedgingPolicy: &MethodConfig_HedgingPolicy{
+						MaxAttempts:  3,
+						HedgingDelay: &duration.Duration{Nanos: 50000000},
+						NonFatalStatusCodes: []code.Code{
+							code.Code_UNAVAILABLE,
+						},
+					},
+				},
+			},
+		},
+		RetryThrottling: &ServiceConfig_RetryThrottlingPolicy{
+			MaxTokens:  10,
+			TokenRatio: 0.1,
 		},
 	}
 	data, err := protojson.Marshal(c)
@@ -95,6 +117,18 @@ func TestParse(t *testing.T) {
 					code.Code_UNKNOWN,
 				},
 			},
+			"bar.FooService.Zap": nil,
+		},
+		hedging: map[string]*MethodConfig_HedgingPolicy{
+			"bar.FooService":     nil,
+			"bar.FooService.Zip": nil,
+			"bar.FooService.Zap": {
+				MaxAttempts:  3,
+				HedgingDelay: &duration.Duration{Nanos: 50000000},
+				NonFatalStatusCodes: []code.Code{
+					code.Code_UNAVAILABLE,
+				},
+			},
 		},
 		timeouts: map[string]*duration.Duration{
 			"bar.FooService":     {Seconds: 60},
@@ -108,6 +142,18 @@ func TestParse(t *testing.T) {
 			"bar.FooService":     654321,
 			"bar.FooService.Zip": 123456,
 		},
+		waitForReady: map[string]bool{
+			"bar.FooService.Zip": true,
+		},
+		throttling: &ServiceConfig_RetryThrottlingPolicy{
+			MaxTokens:  10,
+			TokenRatio: 0.1,
+		},
+		names: []*MethodConfig_Name{
+			{Service: "bar.FooService", Method: "Zip"},
+			{Service: "bar.FooService"},
+			{Service: "bar.FooService", Method: "Zap"},
+		},
 	}
 
 	got, err := New(in)
@@ -185,6 +231,40 @@ func TestRetryPolicy(t *testing.T) {
 	}
 }
 
+func TestHedgingPolicy(t *testing.T) {
+	s := "bar.FooService"
+	m := "Zip"
+	mFQN := s + "." + m
+	c := Config{
+		hedging: map[string]*MethodConfig_HedgingPolicy{
+			s: {
+				MaxAttempts:  3,
+				HedgingDelay: &duration.Duration{Nanos: 50000000},
+				NonFatalStatusCodes: []code.Code{
+					code.Code_UNAVAILABLE,
+				},
+			},
+			mFQN: {
+				MaxAttempts: 2,
+			},
+		},
+	}
+
+	want := c.hedging[s]
+	if got, ok := c.HedgingPolicy(s, "dne"); !ok || !cmp.Equal(got, want, cmp.Comparer(proto.Equal)) {
+		t.Errorf("%s: expected %v got %v", t.Name(), want, got)
+	}
+
+	want = c.hedging[mFQN]
+	if got, ok := c.HedgingPolicy(s, m); !ok || !cmp.Equal(got, want, cmp.Comparer(proto.Equal)) {
+		t.Errorf("%s: expected %v got %v", t.Name(), want, got)
+	}
+
+	if got, ok := c.HedgingPolicy("dne", "dne"); ok {
+		t.Errorf("%s: expected !ok got %v", t.Name(), got)
+	}
+}
+
 func TestRequestLimit(t *testing.T) {
 	s := "bar.FooService"
 	m := "Zip"
@@ -236,3 +316,39 @@ func TestResponseLimit(t *testing.T) {
 		t.Errorf("%s: expected !ok got %d", t.Name(), got)
 	}
 }
+
+func TestWaitForReady(t *testing.T) {
+	s := "bar.FooService"
+	m := "Zip"
+	mFQN := s + "." + m
+	c := Config{
+		waitForReady: map[string]bool{
+			s:    true,
+			mFQN: false,
+		},
+	}
+
+	if got, ok := c.WaitForReady(s, "dne"); !ok || !got {
+		t.Errorf("%s: expected true got %v", t.Name(), got)
+	}
+
+	if got, ok := c.WaitForReady(s, m); !ok || got {
+		t.Errorf("%s: expected false got %v", t.Name(), got)
+	}
+
+	if got, ok := c.WaitForReady("dne", "dne"); ok {
+		t.Errorf("%s: expected !ok got %v", t.Name(), got)
+	}
+}
+
+func TestRetryThrottling(t *testing.T) {
+	if got, ok := (Config{}).RetryThrottling(); ok {
+		t.Errorf("%s: expected !ok got %v", t.Name(), got)
+	}
+
+	want := &ServiceConfig_RetryThrottlingPolicy{MaxTokens: 10, TokenRatio: 0.1}
+	c := Config{throttling: want}
+	if got, ok := c.RetryThrottling(); !ok || !proto.Equal(got, want) {
+		t.Errorf("%s: expected %v got %v", t.Name(), want, got)
+	}
+}
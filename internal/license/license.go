@@ -14,7 +14,14 @@
 
 package license
 
-const Apache = `// Copyright %d Google LLC
+import (
+	"fmt"
+	"strings"
+)
+
+// Apache is the notice of the Apache License 2.0, formatted with the year and
+// the copyright holder.
+const Apache = `// Copyright %d %s
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -27,7 +34,68 @@ const Apache = `// Copyright %d Google LLC
 // WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
 // See the License for the specific language governing permissions and
 // limitations under the License.
+`
 
-// Code generated by protoc-gen-go_gapic. DO NOT EDIT.
-
+// SPDX is the short notice of a license, formatted with the year, the
+// copyright holder and the SPDX identifier of the license.
+const SPDX = `// Copyright %d %s
+// SPDX-License-Identifier: %s
 `
+
+// Generated marks the generated files, at the end of their header.
+const Generated = "// Code generated by protoc-gen-go_gapic. DO NOT EDIT.\n"
+
+// DefaultHolder is the copyright holder of the generated files, unless
+// configured otherwise.
+const DefaultHolder = "Google LLC"
+
+// DefaultID is the SPDX identifier of the license of the generated files,
+// unless configured otherwise.
+const DefaultID = "Apache-2.0"
+
+// None is the license of generated files without a copyright notice.
+const None = "none"
+
+// Presets are the SPDX identifiers of the licenses with a notice. The notice
+// of Apache-2.0 is Apache, the notice of the others is SPDX.
+var Presets = []string{"Apache-2.0", "BSD-2-Clause", "BSD-3-Clause", "MIT", "MPL-2.0"}
+
+// Notice returns the notice of the license with the given SPDX identifier, or
+// nothing for None.
+func Notice(id, holder string, year int) string {
+	switch id {
+	case None:
+		return ""
+	case DefaultID:
+		return fmt.Sprintf(Apache, year, holder)
+	}
+	return fmt.Sprintf(SPDX, year, holder, id)
+}
+
+// Comment returns the given license text as a notice, commenting those of
+// its lines which are not comments already.
+func Comment(text string) string {
+	var b strings.Builder
+	for _, l := range strings.Split(strings.TrimRight(text, "\n\t "), "\n") {
+		l = strings.TrimRight(l, "\r\t ")
+		switch {
+		case strings.HasPrefix(l, "//"):
+			b.WriteString(l)
+		case l == "":
+			b.WriteString("//")
+		default:
+			b.WriteString("// " + l)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Header returns the header of the generated files with the given notice,
+// which may be empty.
+func Header(notice string) string {
+	if notice == "" {
+		return Generated + "\n"
+	}
+	return notice + "\n" + Generated + "\n"
+}
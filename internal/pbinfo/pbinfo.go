@@ -197,6 +197,62 @@ func (in *Info) ImportSpec(e ProtoType) (ImportSpec, error) {
 	return imp, err
 }
 
+// HasExplicitPresence reports whether the singular field f tracks whether it
+// is set independently of its value, such that a scalar field is generated as
+// a pointer in Go. This is the case of proto3 optional fields, of the fields of
+// proto2 files, and of the fields of Protobuf Editions files whose
+// field_presence feature resolves to EXPLICIT, the default, or LEGACY_REQUIRED.
+//
+// The fields of a oneof are set through the wrapper types of the oneof, so they
+// are not reported.
+func (in *Info) HasExplicitPresence(f *descriptorpb.FieldDescriptorProto) bool {
+	if f.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REPEATED {
+		return false
+	}
+	if f.GetProto3Optional() {
+		return true
+	}
+	if f.OneofIndex != nil {
+		return false
+	}
+
+	// Features are inherited from the enclosing messages, then from the file.
+	features := []*descriptorpb.FeatureSet{f.GetOptions().GetFeatures()}
+	var e ProtoType = f
+	for in.ParentElement[e] != nil {
+		e = in.ParentElement[e]
+		if m, ok := e.(*descriptorpb.DescriptorProto); ok {
+			features = append(features, m.GetOptions().GetFeatures())
+		}
+	}
+	file, ok := in.ParentFile[e]
+	if !ok {
+		return false
+	}
+	switch file.GetSyntax() {
+	case "", "proto2":
+		// The singular fields of proto2 files, optional or required, all do.
+		return true
+	case "proto3":
+		return false
+	}
+	switch f.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		return true
+	}
+	features = append(features, file.GetOptions().GetFeatures())
+	for _, fs := range features {
+		switch fs.GetFieldPresence() {
+		case descriptorpb.FeatureSet_IMPLICIT:
+			return false
+		case descriptorpb.FeatureSet_EXPLICIT, descriptorpb.FeatureSet_LEGACY_REQUIRED:
+			return true
+		}
+	}
+	// EXPLICIT is the default of all of the editions supported by the generator.
+	return true
+}
+
 // ReduceServNameWithOverride returns the override string if present,
 // otherwise calls ReduceServName.
 func ReduceServNameWithOverride(svc, pkg, override string) string {
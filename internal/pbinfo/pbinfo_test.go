@@ -75,3 +75,141 @@ func TestNameSpec(t *testing.T) {
 		}
 	}
 }
+
+func TestHasExplicitPresence(t *testing.T) {
+	t.Parallel()
+
+	presence := func(p descriptorpb.FeatureSet_FieldPresence) *descriptorpb.FeatureSet {
+		return &descriptorpb.FeatureSet{FieldPresence: p.Enum()}
+	}
+	field := func(name string, typ descriptorpb.FieldDescriptorProto_Type, features *descriptorpb.FeatureSet) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name: proto.String(name),
+			Type: typ.Enum(),
+		}
+		if features != nil {
+			f.Options = &descriptorpb.FieldOptions{Features: features}
+		}
+		return f
+	}
+	str := descriptorpb.FieldDescriptorProto_TYPE_STRING
+	msgType := descriptorpb.FieldDescriptorProto_TYPE_MESSAGE
+
+	defaulted := field("defaulted", str, nil)
+	implicit := field("implicit", str, presence(descriptorpb.FeatureSet_IMPLICIT))
+	required := field("required", str, presence(descriptorpb.FeatureSet_LEGACY_REQUIRED))
+	message := field("message", msgType, nil)
+	repeated := field("repeated", str, nil)
+	repeated.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	oneof := field("oneof", str, nil)
+	oneof.OneofIndex = proto.Int32(0)
+	msg := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Message"),
+		Field: []*descriptorpb.FieldDescriptorProto{defaulted, implicit, required, message, repeated, oneof},
+	}
+
+	// Nested in a message with implicit presence.
+	inherited := field("inherited", str, nil)
+	explicit := field("explicit", str, presence(descriptorpb.FeatureSet_EXPLICIT))
+	nestedMessage := field("nested_message", msgType, nil)
+	nested := &descriptorpb.DescriptorProto{
+		Name:    proto.String("Nested"),
+		Field:   []*descriptorpb.FieldDescriptorProto{inherited, explicit, nestedMessage},
+		Options: &descriptorpb.MessageOptions{Features: presence(descriptorpb.FeatureSet_IMPLICIT)},
+	}
+	outer := &descriptorpb.DescriptorProto{
+		Name:       proto.String("Outer"),
+		NestedType: []*descriptorpb.DescriptorProto{nested},
+	}
+
+	// In a file with implicit presence.
+	fileImplicit := field("file_implicit", str, nil)
+	implicitMsg := &descriptorpb.DescriptorProto{
+		Name:  proto.String("ImplicitMessage"),
+		Field: []*descriptorpb.FieldDescriptorProto{fileImplicit},
+	}
+
+	proto3Optional := field("proto3_optional", str, nil)
+	proto3Optional.Proto3Optional = proto.Bool(true)
+	proto3Optional.OneofIndex = proto.Int32(0)
+	proto3 := field("proto3", str, nil)
+	proto3Msg := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Proto3Message"),
+		Field: []*descriptorpb.FieldDescriptorProto{proto3Optional, proto3},
+	}
+
+	proto2 := field("proto2", str, nil)
+	proto2.Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	proto2Msg := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Proto2Message"),
+		Field: []*descriptorpb.FieldDescriptorProto{proto2},
+	}
+
+	proto2Required := field("proto2_required", descriptorpb.FieldDescriptorProto_TYPE_INT32, nil)
+	proto2Required.Label = descriptorpb.FieldDescriptorProto_LABEL_REQUIRED.Enum()
+	proto2Repeated := field("proto2_repeated", str, nil)
+	proto2Repeated.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	proto2Oneof := field("proto2_oneof", str, nil)
+	proto2Oneof.Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	proto2Oneof.OneofIndex = proto.Int32(0)
+	proto2SyntaxMsg := &descriptorpb.DescriptorProto{
+		Name:  proto.String("Proto2SyntaxMessage"),
+		Field: []*descriptorpb.FieldDescriptorProto{proto2Required, proto2Repeated, proto2Oneof},
+	}
+
+	info := Of([]*descriptorpb.FileDescriptorProto{
+		{
+			Name:        proto.String("editions.proto"),
+			Syntax:      proto.String("editions"),
+			Edition:     descriptorpb.Edition_EDITION_2023.Enum(),
+			MessageType: []*descriptorpb.DescriptorProto{msg, outer},
+		},
+		{
+			Name:        proto.String("implicit.proto"),
+			Syntax:      proto.String("editions"),
+			Edition:     descriptorpb.Edition_EDITION_2023.Enum(),
+			Options:     &descriptorpb.FileOptions{Features: presence(descriptorpb.FeatureSet_IMPLICIT)},
+			MessageType: []*descriptorpb.DescriptorProto{implicitMsg},
+		},
+		{
+			Name:        proto.String("proto3.proto"),
+			Syntax:      proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{proto3Msg},
+		},
+		{
+			Name:        proto.String("proto2.proto"),
+			MessageType: []*descriptorpb.DescriptorProto{proto2Msg},
+		},
+		{
+			Name:        proto.String("proto2_syntax.proto"),
+			Syntax:      proto.String("proto2"),
+			MessageType: []*descriptorpb.DescriptorProto{proto2SyntaxMsg},
+		},
+	})
+
+	for _, tst := range []struct {
+		f    *descriptorpb.FieldDescriptorProto
+		want bool
+	}{
+		{defaulted, true},
+		{implicit, false},
+		{required, true},
+		{message, true},
+		{repeated, false},
+		{oneof, false},
+		{inherited, false},
+		{explicit, true},
+		{nestedMessage, true},
+		{fileImplicit, false},
+		{proto3Optional, true},
+		{proto3, false},
+		{proto2, true},
+		{proto2Required, true},
+		{proto2Repeated, false},
+		{proto2Oneof, false},
+	} {
+		if got := info.HasExplicitPresence(tst.f); got != tst.want {
+			t.Errorf("HasExplicitPresence(%s) = %v, want %v", tst.f.GetName(), got, tst.want)
+		}
+	}
+}
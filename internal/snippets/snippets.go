@@ -22,7 +22,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/googleapis/gapic-generator-go/internal/license"
 	"github.com/googleapis/gapic-generator-go/internal/pbinfo"
 	"github.com/googleapis/gapic-generator-go/internal/snippets/metadata"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -32,9 +31,6 @@ import (
 // the actual module version by a generator post-processing script.
 var VersionPlaceholder = "$VERSION"
 
-// headerLen is the length of the Apache license header including trailing newlines.
-var headerLen = len(strings.Split(license.Apache, "\n"))
-
 var spaceSanitizerRegex = regexp.MustCompile(`:\s*`)
 
 var ctxParam = &param{
@@ -95,10 +91,12 @@ func (sm *SnippetMetadata) AddService(servName, defaultHost string) {
 // to add an incomplete method entry that will be updated via UpdateMethodDoc and UpdateMethodResult.
 // parentProtoPkg and parentName are the original proto namespace and service for the method.
 // (In mixin methods, these are different from the protoPkg and service into which it has been mixed.)
-func (sm *SnippetMetadata) AddMethod(servName, methodName, parentProtoPkg, parentName string, regionTagEnd int) {
+// regionTagStart and regionTagEnd are the numbers of the lines of the START and END region tags
+// in the snippet file, which depend on the length of its license header.
+func (sm *SnippetMetadata) AddMethod(servName, methodName, parentProtoPkg, parentName string, regionTagStart, regionTagEnd int) {
 	m := &method{
 		regionTag:      sm.RegionTag(servName, methodName),
-		regionTagStart: headerLen,
+		regionTagStart: regionTagStart,
 		regionTagEnd:   regionTagEnd,
 		parentProtoPkg: parentProtoPkg,
 		parentName:     parentName,
@@ -141,6 +139,15 @@ func (sm *SnippetMetadata) AddParams(servName, methodName, requestType string) {
 	m.params = append(m.params, optsParam)
 }
 
+// Merge adds the services collected in other, a model of the same API package,
+// to sm. A service present in both is replaced by the one in other, as if it
+// had been added to sm after the services already in it.
+func (sm *SnippetMetadata) Merge(other *SnippetMetadata) {
+	for servName, s := range other.protoServices {
+		sm.protoServices[servName] = s
+	}
+}
+
 // RegionTag generates a snippet region tag from service.shortName(defaultHost),
 // apiVersion, and the given full servName and method name.
 func (sm *SnippetMetadata) RegionTag(servName, methodName string) string {
@@ -49,7 +49,7 @@ func TestToMetadataJSON(t *testing.T) {
 		serviceName := fmt.Sprintf("Foo%dService", i)
 		methodName := fmt.Sprintf("Bar%dMethod", i)
 		sm.AddService(serviceName, sample.ServiceURL)
-		sm.AddMethod(serviceName, methodName, sample.ProtoPackagePath, serviceName, regionTagEnd)
+		sm.AddMethod(serviceName, methodName, sample.ProtoPackagePath, serviceName, regionTagStart-1, regionTagEnd)
 		sm.UpdateMethodDoc(serviceName, methodName, methodName+" doc\n New line.")
 		sm.UpdateMethodResult(serviceName, methodName, "mypackage."+methodName+"Result")
 		sm.AddParams(serviceName, methodName, "mypackage."+methodName+"Request")
@@ -144,3 +144,23 @@ func TestRegionTag(t *testing.T) {
 		t.Errorf("%s: got %s want %s", t.Name(), got, want)
 	}
 }
+
+func TestMerge(t *testing.T) {
+	sm := NewMetadata(sample.ProtoPackagePath, sample.GoPackagePath, sample.GoPackageName)
+	sm.AddService("FooService", sample.ServiceURL)
+	sm.AddMethod("FooService", "Foo", sample.ProtoPackagePath, "FooService", 17, 50)
+
+	other := NewMetadata(sample.ProtoPackagePath, sample.GoPackagePath, sample.GoPackageName)
+	other.AddService("BarService", sample.ServiceURL)
+	other.AddMethod("BarService", "Bar", sample.ProtoPackagePath, "BarService", 17, 50)
+
+	sm.Merge(other)
+	for _, servName := range []string{"FooService", "BarService"} {
+		if _, ok := sm.protoServices[servName]; !ok {
+			t.Errorf("%s: missing service %s", t.Name(), servName)
+		}
+	}
+	if got, want := len(sm.protoServices["BarService"].methods), 1; got != want {
+		t.Errorf("%s: got %d methods want %d", t.Name(), got, want)
+	}
+}